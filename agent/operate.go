@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -25,6 +26,10 @@ func StartAgent(do *definitions.Do) error {
 	mux.HandleFunc("/install", InstallAgent)
 	fmt.Println("Starting agent on localhost:17552")
 
+	// Requests are served concurrently for as long as the agent
+	// runs; follow container changes instead of re-listing them.
+	defer util.WatchContainers()()
+
 	// cors.Default() sets up the middleware with default options being
 	// all origins accepted with simple methods (GET, POST).
 	// See https://github.com/rs/cors
@@ -42,29 +47,20 @@ func StartAgent(do *definitions.Do) error {
 	return nil
 }*/
 
+// ListChains replies with the statuses of the running chains as a JSON
+// array (see util.GetChainStatus).
 func ListChains(w http.ResponseWriter, r *http.Request) {
 	if r.Method == "GET" {
-		var deets []*util.Details
-
-		deets = util.ErisContainersByType(definitions.TypeChain, true)
-		names := make([]string, len(deets))
-		for i, chainName := range deets {
-			names[i] = chainName.ShortName
+		var names []string
+		for _, chain := range util.ErisContainersByType(definitions.TypeChain, true) {
+			names = append(names, chain.ShortName)
 		}
 
-		chainz := []string{}
-		lenChainz := len(names) - 1
-		for i, fmtName := range names {
-			if i == 0 {
-				chainz = append(chainz, "[")
-			}
-			if i == lenChainz {
-				chainz = append(chainz, fmt.Sprintf("{ name: '%s' }]", fmtName))
-				break
-			}
-			chainz = append(chainz, fmt.Sprintf("{ name: '%s' }, ", fmtName))
+		statuses := util.GetChainStatuses(names)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(statuses); err != nil {
+			log.Errorf("Error writing chains: %v", err)
 		}
-		w.Write([]byte(strings.Join(chainz, "")))
 	}
 }
 
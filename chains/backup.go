@@ -0,0 +1,473 @@
+package chains
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/eris-ltd/eris-cli/data"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/genesis"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/perform"
+	"github.com/eris-ltd/eris-cli/util"
+	"github.com/eris-ltd/eris-cli/version"
+
+	. "github.com/eris-ltd/common/go/common"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Entries of a chain backup archive, in the order they are written.
+const (
+	backupManifest   = "manifest.json"
+	backupDefinition = "chain.toml"
+	backupData       = "data.tar"
+	backupKeys       = "keys/"
+)
+
+// Backup is the manifest of a chain backup archive.
+type Backup struct {
+	Name    string    `json:"name"`
+	ChainID string    `json:"chain_id"`
+	Created time.Time `json:"created"`
+	Version string    `json:"eris_version"`
+	Keys    []string  `json:"keys"`
+}
+
+// BackupChain writes everything needed to run the chain on another machine
+// into one gzipped tar archive: a manifest, the chain definition file, the
+// contents of the chain data container (blockchain database, config.toml,
+// genesis.json, and priv_validator.json), and the keys of the validator
+// and the genesis accounts found in the keys data container. It sets
+// do.Result to the archive path. A running chain is stopped for the time
+// of the data copy and started again.
+//
+//  do.Name        - name of the chain (required)
+//  do.Destination - archive path (defaults to NAME.tar.gz)
+//  do.Timeout     - seconds to wait for the chain to stop
+//
+func BackupChain(do *definitions.Do) error {
+	defFile := util.GetFileByNameAndType("chains", do.Name)
+	if defFile == "" {
+		return fmt.Errorf("I cannot find that chain. Please check the chain name you sent me.")
+	}
+	if !util.IsData(do.Name) && !util.IsDataVolume(do.Name) {
+		return fmt.Errorf("The %s chain has no data to back up. Start it first with [eris chains start %s]", do.Name, do.Name)
+	}
+	if do.Destination == "" {
+		do.Destination = do.Name + ".tar.gz"
+	}
+
+	// The data archive goes to a temporary file first, as its size has
+	// to be known for the tar header.
+	dataFile, err := ioutil.TempFile("", "eris-backup-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(dataFile.Name())
+	defer dataFile.Close()
+
+	log.WithField("=>", do.Name).Info("Backing up chain data")
+	if err := snapshotChainData(do.Name, dataFile, do.Timeout); err != nil {
+		return err
+	}
+
+	manifest := &Backup{
+		Name:    do.Name,
+		Created: time.Now().UTC(),
+		Version: version.VERSION,
+	}
+	addresses, err := scanChainData(dataFile, manifest)
+	if err != nil {
+		return err
+	}
+
+	keys := new(bytes.Buffer)
+	if manifest.Keys, err = backupKeyFiles(addresses, keys); err != nil {
+		return err
+	}
+
+	partial := do.Destination + ".partial"
+	out, err := os.Create(partial)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(partial)
+	defer out.Close()
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeTarFile(tw, backupManifest, int64(len(b)), bytes.NewReader(b)); err != nil {
+		return err
+	}
+
+	definition, err := os.Open(defFile)
+	if err != nil {
+		return err
+	}
+	defer definition.Close()
+	info, err := definition.Stat()
+	if err != nil {
+		return err
+	}
+	if err := writeTarFile(tw, backupDefinition, info.Size(), definition); err != nil {
+		return err
+	}
+
+	info, err = dataFile.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := dataFile.Seek(0, 0); err != nil {
+		return err
+	}
+	if err := writeTarFile(tw, backupData, info.Size(), dataFile); err != nil {
+		return err
+	}
+
+	if err := copyTar(tw, tar.NewReader(keys), func(name string) string { return backupKeys + name }); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(partial, do.Destination); err != nil {
+		return err
+	}
+
+	do.Result = do.Destination
+	return nil
+}
+
+// snapshotChainData writes the archive of the chain data to w. The
+// database of a running chain would change under the copy, so the chain
+// is stopped for the time of it, waiting for timeout seconds before
+// killing it, and then started again.
+func snapshotChainData(name string, w io.Writer, timeout uint) (err error) {
+	ops := loaders.LoadDataDefinition(name)
+	if owner := perform.DataOwner(ops); owner != nil && owner.Info.State.Running {
+		log.WithField("=>", owner.FullName).Warn("Stopping chain to back up its data")
+		if err := util.DockerClient.StopContainer(owner.FullName, timeout); err != nil {
+			return util.DockerError(err)
+		}
+
+		defer func() {
+			log.WithField("=>", owner.FullName).Warn("Starting chain again")
+			if err2 := util.DockerClient.StartContainer(owner.FullName, nil); err2 != nil && err == nil {
+				err = util.DockerError(err2)
+			}
+		}()
+	}
+
+	return perform.DockerSnapshotData(ops, w)
+}
+
+// scanChainData sets the chain ID of the manifest from the genesis file in
+// the chain data archive and returns the addresses of the validator and
+// the genesis accounts.
+func scanChainData(file *os.File, manifest *Backup) ([]string, error) {
+	if _, err := file.Seek(0, 0); err != nil {
+		return nil, err
+	}
+
+	var addresses []string
+	tr := tar.NewReader(file)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch path.Base(header.Name) {
+		case "genesis.json":
+			b, err := ioutil.ReadAll(tr)
+			if err != nil {
+				return nil, err
+			}
+			g, err := genesis.Parse(b)
+			if err != nil {
+				log.WithField("file", header.Name).Warnf("Cannot read genesis file: %v", err)
+				continue
+			}
+			if manifest.ChainID == "" {
+				manifest.ChainID = g.ChainID
+			}
+			for _, account := range g.Accounts {
+				addresses = append(addresses, account.Address)
+			}
+		case "priv_validator.json":
+			var validator struct {
+				Address string `json:"address"`
+			}
+			if err := json.NewDecoder(tr).Decode(&validator); err != nil {
+				log.WithField("file", header.Name).Warnf("Cannot read validator file: %v", err)
+				continue
+			}
+			addresses = append(addresses, validator.Address)
+		}
+	}
+	return addresses, nil
+}
+
+// backupKeyFiles writes a tar archive of the files of those of the
+// addresses the keys data container has keys for to w, and returns these
+// addresses.
+func backupKeyFiles(addresses []string, w io.Writer) ([]string, error) {
+	tw := tar.NewWriter(w)
+	found := []string{}
+	if !util.IsData("keys") && !util.IsDataVolume("keys") {
+		log.Warn("There is no keys data to back up keys from")
+		return found, tw.Close()
+	}
+
+	ops := loaders.LoadDataDefinition("keys")
+	seen := make(map[string]bool)
+	for _, address := range addresses {
+		address = strings.ToUpper(address)
+		if address == "" || seen[address] {
+			continue
+		}
+		seen[address] = true
+
+		buf := new(bytes.Buffer)
+		if err := perform.DockerCopyFromData(ops, path.Join(ErisContainerRoot, "keys", "data", address), buf); err != nil {
+			log.WithField("=>", address).Debugf("No key to back up: %v", err)
+			continue
+		}
+		if err := copyTar(tw, tar.NewReader(buf), nil); err != nil {
+			return nil, err
+		}
+		log.WithField("=>", address).Info("Backing up key")
+		found = append(found, address)
+	}
+	return found, tw.Close()
+}
+
+// RestoreChain recreates a chain from an archive written by BackupChain:
+// the chain definition file, the chain data container, and the keys, which
+// are added to the keys data container. The chain is not started. It sets
+// do.Result to the chain name. If the restore fails, what it has restored
+// so far is removed, so that it can be tried again.
+//
+//  do.Source  - archive path (required)
+//  do.NewName - name to restore the chain under (defaults to the backed
+//               up chain name)
+//
+func RestoreChain(do *definitions.Do) (err error) {
+	file, err := os.Open(do.Source)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("%s is not a chain backup: %v", do.Source, err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	header, err := tr.Next()
+	if err != nil || header.Name != backupManifest {
+		return fmt.Errorf("%s is not a chain backup: the manifest is missing", do.Source)
+	}
+	manifest := new(Backup)
+	if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+		return fmt.Errorf("Cannot read the backup manifest: %v", err)
+	}
+
+	name := manifest.Name
+	if do.NewName != "" {
+		name = do.NewName
+	}
+	if util.GetFileByNameAndType("chains", name) != "" || util.IsData(name) || util.IsDataVolume(name) {
+		return fmt.Errorf("The %s chain exists. Remove it with [eris chains rm --data --file %s] or restore the backup under another name with --as", name, name)
+	}
+
+	log.WithFields(log.Fields{
+		"=>":       name,
+		"chain id": manifest.ChainID,
+		"created":  manifest.Created,
+	}).Info("Restoring chain backup")
+
+	var undo []func() error
+	defer func() {
+		if err == nil {
+			return
+		}
+		log.WithField("=>", name).Warn("Removing the partly restored chain")
+		for i := len(undo) - 1; i >= 0; i-- {
+			if err2 := undo[i](); err2 != nil {
+				log.WithField("=>", name).Errorf("Cannot clean up after the failed restore: %v", err2)
+			}
+		}
+	}()
+
+	keys := new(bytes.Buffer)
+	tw := tar.NewWriter(keys)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case header.Name == backupDefinition:
+			undo = append(undo, func() error {
+				return os.Remove(filepath.Join(ChainsPath, name+".toml"))
+			})
+			if err := restoreDefinition(name, manifest.Name, tr); err != nil {
+				return err
+			}
+		case header.Name == backupData:
+			ops := loaders.LoadDataDefinition(name)
+			if err := perform.DockerCreateData(ops); err != nil {
+				return fmt.Errorf("Error creating data container =>\t%v", err)
+			}
+			undo = append(undo, func() error {
+				doRm := definitions.NowDo()
+				doRm.Name = name
+				doRm.Volumes = true
+				return data.RmData(doRm)
+			})
+			// The data is new, so there's nothing to stop.
+			if err := perform.DockerRestoreData(ops, tr, 0); err != nil {
+				return err
+			}
+		case strings.HasPrefix(header.Name, backupKeys):
+			header.Name = strings.TrimPrefix(header.Name, backupKeys)
+			if err := tw.WriteHeader(header); err != nil {
+				return err
+			}
+			if _, err := io.Copy(tw, tr); err != nil {
+				return err
+			}
+		default:
+			log.WithField("file", header.Name).Warn("Skipping unknown file in backup")
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	if len(manifest.Keys) != 0 {
+		if err := checkKeysRunningOrStart(); err != nil {
+			return err
+		}
+		log.WithField("keys", manifest.Keys).Info("Restoring keys")
+		keysOps := loaders.LoadDataDefinition("keys")
+		var added []string
+		for _, address := range manifest.Keys {
+			key := path.Join(ErisContainerRoot, "keys", "data", address)
+			if err := perform.DockerCopyFromData(keysOps, key, ioutil.Discard); err != nil {
+				added = append(added, key)
+			}
+		}
+		undo = append(undo, func() error {
+			if len(added) == 0 {
+				return nil
+			}
+			doRm := definitions.NowDo()
+			doRm.Name = "keys"
+			doRm.Operations.Args = append([]string{"rm", "-rf"}, added...)
+			_, err := data.ExecData(doRm)
+			return err
+		})
+		if err := perform.DockerCopyToData(keysOps, path.Join(ErisContainerRoot, "keys", "data"), keys); err != nil {
+			return err
+		}
+	}
+
+	do.Result = name
+	return nil
+}
+
+// restoreDefinition writes the backed up definition of the oldName chain
+// as the definition of the name chain.
+func restoreDefinition(name, oldName string, r io.Reader) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	fileName := filepath.Join(ChainsPath, name+".toml")
+	if err := ioutil.WriteFile(fileName, b, 0644); err != nil {
+		return err
+	}
+	if name == oldName {
+		return nil
+	}
+
+	chain, err := loaders.LoadChainDefinition(name, false)
+	if err != nil {
+		return err
+	}
+	chain.Name = name
+	// See RenameChain.
+	chain.Service.Name = ""
+	chain.Service.Image = ""
+	return WriteChainDefinitionFile(chain, fileName)
+}
+
+func writeTarFile(tw *tar.Writer, name string, size int64, r io.Reader) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    size,
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+	_, err := io.CopyN(tw, r, size)
+	return err
+}
+
+// copyTar copies the entries of tr to tw, renaming them with rename if
+// it's not nil.
+func copyTar(tw *tar.Writer, tr *tar.Reader, rename func(string) string) error {
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if rename != nil {
+			header.Name = rename(header.Name)
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package chains
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/eris-ltd/eris-cli/data"
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/perform"
+	"github.com/eris-ltd/eris-cli/tests"
+	"github.com/eris-ltd/eris-cli/util"
+
+	"github.com/eris-ltd/common/go/common"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestBackupRestoreChain(t *testing.T) {
+	server, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	root, done := tests.WithErisRoot(t)
+	defer done()
+
+	const (
+		chain   = "test-backup"
+		address = "6D1A4E9FA2AD3F8B3E0A37E14D3F08D2AFE7A34B"
+	)
+	files := map[string]string{
+		"chain/genesis.json":              `{"chain_id": "` + chain + `", "accounts": [{"address": "` + address + `", "amount": 1}]}`,
+		"chain/priv_validator.json":       `{"address": "` + address + `"}`,
+		"keys/" + address + "/" + address: "secret",
+		"chains/" + chain + ".toml":       "name = \"" + chain + "\"\nchain_id = \"" + chain + "\"\n\n[service]\nimage = \"quay.io/eris/db\"\n",
+		"chains/default.toml":             "[service]\nimage = \"quay.io/eris/db\"\n",
+		"services/keys.toml":              "name = \"keys\"\n\n[service]\nimage = \"quay.io/eris/keys\"\ndata_container = true\n",
+	}
+	tests.WriteFiles(t, root, files)
+
+	srv := startKeys(t)
+
+	for _, imp := range []struct{ name, source, destination string }{
+		{"keys", "keys", "keys/data"},
+		{chain, "chain", "chains/" + chain},
+	} {
+		do := def.NowDo()
+		do.Name = imp.name
+		do.Source = filepath.Join(root, imp.source)
+		do.Destination = common.ErisContainerRoot + "/" + imp.destination
+		if err := data.ImportData(do); err != nil {
+			t.Fatalf("expected import to succeed, got %v", err)
+		}
+	}
+
+	do := def.NowDo()
+	do.Name = chain
+	do.Destination = filepath.Join(root, "backup.tar.gz")
+	if err := BackupChain(do); err != nil {
+		t.Fatalf("expected backup to succeed, got %v", err)
+	}
+
+	// The key goes in once, even if it's both an account and the validator.
+	archive, err := os.Open(do.Destination)
+	if err != nil {
+		t.Fatalf("expected archive, got %v", err)
+	}
+	defer archive.Close()
+	gz, err := gzip.NewReader(archive)
+	if err != nil {
+		t.Fatalf("expected gzipped archive, got %v", err)
+	}
+	var entries []string
+	for tr := tar.NewReader(gz); ; {
+		header, err := tr.Next()
+		if err != nil {
+			break
+		}
+		entries = append(entries, header.Name)
+	}
+	expected := []string{"manifest.json", "chain.toml", "data.tar", "keys/" + address + "/", "keys/" + address + "/" + address}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Fatalf("expected archive entries %v, got %v", expected, entries)
+	}
+
+	// A running chain is stopped for the data copy and started again.
+	server.AddImage("quay.io/eris/db")
+	if _, err := util.DockerClient.CreateContainer(docker.CreateContainerOptions{
+		Name: util.ChainContainerName(chain),
+		Config: &docker.Config{
+			Image:  "quay.io/eris/db",
+			Labels: util.Labels(chain, &def.Operation{ContainerType: def.TypeChain}),
+		},
+	}); err != nil {
+		t.Fatalf("expected chain container to be created, got %v", err)
+	}
+	if err := util.DockerClient.StartContainer(util.ChainContainerName(chain), nil); err != nil {
+		t.Fatalf("expected chain container to start, got %v", err)
+	}
+	started, err := util.DockerClient.InspectContainer(util.ChainContainerName(chain))
+	if err != nil {
+		t.Fatalf("expected chain container, got %v", err)
+	}
+	if err := BackupChain(do); err != nil {
+		t.Fatalf("expected backup of the running chain to succeed, got %v", err)
+	}
+	restarted, err := util.DockerClient.InspectContainer(util.ChainContainerName(chain))
+	if err != nil || !restarted.State.Running || !restarted.State.StartedAt.After(started.State.StartedAt) {
+		t.Fatalf("expected the chain stopped and started again, got %+v (%v)", restarted.State, err)
+	}
+
+	do.Source = do.Destination
+	if err := RestoreChain(do); err == nil {
+		t.Fatalf("expected restore over an existing chain to fail")
+	}
+
+	// A restore which fails leaves nothing behind, so it can be tried again.
+	do.NewName = "test-restored"
+	do.Source = filepath.Join(root, "broken.tar.gz")
+	tests.ReplaceArchiveEntry(t, do.Destination, do.Source, "data.tar", "garbage")
+	if err := RestoreChain(do); err == nil {
+		t.Fatalf("expected restore of a broken backup to fail")
+	}
+	if util.GetFileByNameAndType("chains", do.NewName) != "" || util.IsData(do.NewName) || util.IsDataVolume(do.NewName) {
+		t.Fatalf("expected the failed restore to be cleaned up")
+	}
+
+	do.Source = do.Destination
+	if err := RestoreChain(do); err != nil {
+		t.Fatalf("expected restore to succeed, got %v", err)
+	}
+	restored, err := loaders.LoadChainDefinition(do.NewName, false)
+	if err != nil || restored.Name != do.NewName || restored.ChainID != chain {
+		t.Fatalf("expected renamed chain definition, got %+v (%v)", restored, err)
+	}
+	content, err := server.ReadFile(util.DataContainerName(do.NewName), common.ErisContainerRoot+"/chains/"+chain+"/priv_validator.json")
+	if err != nil || string(content) != files["chain/priv_validator.json"] {
+		t.Fatalf("expected restored chain data, got %q (%v)", content, err)
+	}
+	content, err = server.ReadFile(srv.Operations.SrvContainerName, common.ErisContainerRoot+"/keys/data/"+address+"/"+address)
+	if err != nil || string(content) != "secret" {
+		t.Fatalf("expected restored key, got %q (%v)", content, err)
+	}
+}
+
+// startKeys runs the keys service along with its data container.
+func startKeys(t *testing.T) *def.ServiceDefinition {
+	srv := def.BlankServiceDefinition()
+	srv.Name = "keys"
+	srv.Service.Name = "keys"
+	srv.Service.Image = "quay.io/eris/keys"
+	srv.Service.AutoData = true
+	srv.Operations.ContainerType = def.TypeService
+	srv.Operations.SrvContainerName = util.ServiceContainerName("keys")
+	srv.Operations.DataContainerName = util.DataContainerName("keys")
+	srv.Operations.Labels = util.Labels("keys", srv.Operations)
+
+	if err := perform.DockerRunService(srv.Service, srv.Operations); err != nil {
+		t.Fatalf("expected service to start, got %v", err)
+	}
+	return srv
+}
@@ -2,12 +2,15 @@ package chains
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/eris-ltd/eris-cli/config"
 	def "github.com/eris-ltd/eris-cli/definitions"
@@ -95,6 +98,72 @@ func TestLoadChainDefinition(t *testing.T) {
 	}
 }
 
+func TestLoadChainDefinitionProfile(t *testing.T) {
+	const name = "test-profile"
+	definition := `name = "` + name + `"
+chain_id = "${TEST_PROFILE_CHAIN_ID:-default-id}"
+
+[service]
+ports = ["46656"]
+environment = ["NODE=${TEST_PROFILE_NODE}", "LITERAL=$${TEST_PROFILE_NODE}"]
+
+[profiles.dev.service]
+environment = ["LOG_LEVEL=debug"]
+
+[profiles.prod.service]
+ports = ["46657:46657"]
+restart = "always"
+`
+	file := filepath.Join(common.ChainsPath, name+".toml")
+	if err := ioutil.WriteFile(file, []byte(definition), 0644); err != nil {
+		t.Fatalf("expected definition file to be written, got %v", err)
+	}
+	defer os.Remove(file)
+
+	os.Setenv("TEST_PROFILE_NODE", "marmot")
+	defer os.Unsetenv("TEST_PROFILE_NODE")
+	defer func() { loaders.Profile, loaders.Settings, loaders.Names = "", nil, nil }()
+
+	loaders.Names = []string{name}
+	loaders.Profile = "dev"
+	chain, err := loaders.LoadChainDefinition(name, false)
+	if err != nil {
+		t.Fatalf("expected chain definition to be loaded, got %v", err)
+	}
+	if chain.ChainID != "default-id" {
+		t.Fatalf("expected chain ID from the variable default, got %q", chain.ChainID)
+	}
+	if env := strings.Join(chain.Service.Environment, " "); env != "NODE=marmot LITERAL=${TEST_PROFILE_NODE} LOG_LEVEL=debug" {
+		t.Fatalf("expected interpolated environment with the dev profile, got %q", env)
+	}
+
+	loaders.Profile = "prod"
+	loaders.Settings = []string{"service.ports=1337:1337", "8080:8080", "chain_id=other"}
+	if chain, err = loaders.LoadChainDefinition(name, false); err != nil {
+		t.Fatalf("expected chain definition to be loaded, got %v", err)
+	}
+	if chain.ChainID != "other" || chain.Service.Restart != "always" {
+		t.Fatalf("expected prod profile and settings, got chain ID %q, restart %q", chain.ChainID, chain.Service.Restart)
+	}
+	if ports := strings.Join(chain.Service.Ports, " "); ports != "46656 46657:46657 1337:1337 8080:8080" {
+		t.Fatalf("expected merged ports, got %q", ports)
+	}
+
+	loaders.Profile = "staging"
+	if _, err := loaders.LoadChainDefinition(name, false); err == nil {
+		t.Fatalf("expected unknown profile to fail")
+	}
+
+	// Definitions not named on the command line ignore --profile and --set.
+	loaders.Names = []string{"other"}
+	if chain, err = loaders.LoadChainDefinition(name, false); err != nil {
+		t.Fatalf("expected profile and settings to be ignored, got %v", err)
+	}
+	if ports := strings.Join(chain.Service.Ports, " "); chain.ChainID != "default-id" || ports != "46656" {
+		t.Fatalf("expected definition as written, got chain ID %q, ports %q", chain.ChainID, ports)
+	}
+}
+
 func TestStartChain(t *testing.T) {
 	defer tests.RemoveAllContainers()
 
@@ -310,6 +379,104 @@ func TestChainsNewKeysImported(t *testing.T) {
 	}
 }
 
+func TestChainsNewNodes(t *testing.T) {
+	defer tests.RemoveAllContainers()
+
+	const chain = "test-nodes"
+
+	// Validators taken from the default chain rather than made.
+	for i := 0; i < 2; i++ {
+		dir := filepath.Join(common.ChainsPath, chain, fmt.Sprintf("%s_validator_%03d", chain, i))
+		if err := common.Copy(filepath.Join(common.ChainsPath, "default"), dir); err != nil {
+			t.Fatalf("expected validator files to be copied, got %v", err)
+		}
+	}
+	defer os.RemoveAll(filepath.Join(common.ChainsPath, chain))
+	defer os.Remove(filepath.Join(common.ChainsPath, chain+".toml"))
+
+	do := def.NowDo()
+	do.Name = chain
+	do.Nodes = 2
+	if err := NewChain(do); err != nil {
+		t.Fatalf("expected a new chain to be created, got %v", err)
+	}
+
+	for _, node := range []string{chain + "-0", chain + "-1"} {
+		if !util.Running(def.TypeChain, node) {
+			t.Fatalf("expected node %s running", node)
+		}
+	}
+	args := []string{"cat", fmt.Sprintf("/home/eris/.eris/chains/%s/config.toml", chain)}
+	if out := exec(t, chain+"-0", args); !strings.Contains(out, chain+"-1:46656") {
+		t.Fatalf("expected the other node in seeds, got %v", out)
+	}
+
+	do = def.NowDo()
+	do.Name = chain + "-1"
+	if err := KillChain(do); err != nil {
+		t.Fatalf("expected node to stop, got %v", err)
+	}
+	if util.Running(def.TypeChain, chain+"-1") || !util.Running(def.TypeChain, chain+"-0") {
+		t.Fatalf("expected only the named node to stop")
+	}
+
+	do = def.NowDo()
+	do.Name = chain
+	if err := KillChain(do); err != nil {
+		t.Fatalf("expected chain to stop, got %v", err)
+	}
+	if util.Running(def.TypeChain, chain+"-0") {
+		t.Fatalf("expected all nodes to stop")
+	}
+
+	do = def.NowDo()
+	do.Name = chain
+	do.RmD = true
+	if err := RemoveChain(do); err != nil {
+		t.Fatalf("expected chain to be removed, got %v", err)
+	}
+	if util.Exists(def.TypeChain, chain+"-0") || util.Exists(def.TypeChain, chain+"-1") {
+		t.Fatalf("expected node containers to be removed")
+	}
+}
+
+func TestStatusChain(t *testing.T) {
+	defer tests.RemoveAllContainers()
+
+	start(t, chainName)
+
+	buf := new(bytes.Buffer)
+	config.GlobalConfig.Writer = buf
+
+	do := def.NowDo()
+	do.Operations.Args = []string{chainName}
+	do.JSON = true
+	if err := StatusChain(do); err != nil {
+		t.Fatalf("expected status to succeed, got %v", err)
+	}
+
+	var statuses []*util.ChainStatus
+	if err := json.Unmarshal(buf.Bytes(), &statuses); err != nil {
+		t.Fatalf("expected JSON output, got %v (%s)", err, buf)
+	}
+	if len(statuses) != 1 || statuses[0].Name != chainName || !statuses[0].Running {
+		t.Fatalf("expected the running chain status, got %+v", statuses)
+	}
+	if statuses[0].Error != "" || statuses[0].Validators == 0 || statuses[0].Version == "" {
+		t.Fatalf("expected status from the chain RPC, got %+v", statuses[0])
+	}
+
+	kill(t, chainName)
+
+	buf.Reset()
+	if err := StatusChain(do); err != nil {
+		t.Fatalf("expected status to succeed, got %v", err)
+	}
+	if err := json.Unmarshal(buf.Bytes(), &statuses); err != nil || len(statuses) != 1 || statuses[0].Running {
+		t.Fatalf("expected the chain stopped, got %+v (%v)", statuses, err)
+	}
+}
+
 func TestLogsChain(t *testing.T) {
 	defer tests.RemoveAllContainers()
 
@@ -342,6 +509,35 @@ func TestUpdateChain(t *testing.T) {
 	}
 }
 
+func TestUpdateChainImageRollback(t *testing.T) {
+	defer tests.RemoveAllContainers()
+	defer os.RemoveAll(filepath.Join(common.ErisRoot, "snapshots"))
+	defer func(wait time.Duration) { upgradeWait = wait }(upgradeWait)
+	upgradeWait = 10 * time.Second
+
+	start(t, chainName)
+	previous, err := util.DockerClient.InspectContainer(util.ChainContainerName(chainName))
+	if err != nil {
+		t.Fatalf("expected chain container, got %v", err)
+	}
+
+	// The base image has no chain to run, so it never produces blocks.
+	do := def.NowDo()
+	do.Name = chainName
+	do.Image = path.Join(ver.ERIS_REG_DEF, ver.ERIS_IMG_BASE)
+	if err := UpdateChain(do); err == nil {
+		t.Fatalf("expected upgrade to a broken image to fail")
+	}
+
+	current, err := util.DockerClient.InspectContainer(util.ChainContainerName(chainName))
+	if err != nil {
+		t.Fatalf("expected chain container after rollback, got %v", err)
+	}
+	if current.Config.Image != previous.Config.Image || !current.State.Running {
+		t.Fatalf("expected chain running %s after rollback, got %s (running %v)", previous.Config.Image, current.Config.Image, current.State.Running)
+	}
+}
+
 func TestInspectChain(t *testing.T) {
 	defer tests.RemoveAllContainers()
 
@@ -0,0 +1,247 @@
+package chains
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/eris-ltd/eris-cli/chains/edit"
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/perform"
+	"github.com/eris-ltd/eris-cli/util"
+
+	. "github.com/eris-ltd/common/go/common"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Name of the chain configuration file in the chain data container.
+const chainConfigFile = "config.toml"
+
+// ConfigChain reads and changes the tendermint and eris:db configuration
+// of a chain, the config.toml file in the chain data container, without
+// recreating the chain. Keys are dotted paths of the TOML tables, e.g.
+// moniker, seeds, or TABLE.KEY for the keys of a table. The changes are
+// written in place, keeping the comments, those at the end of the changed
+// lines included, and the order of the file. A chain made of several
+// validator nodes is changed node by node.
+//
+//  do.Name            - name of the chain (required)
+//  do.Type            - get, set, unset, or edit (required)
+//  do.Operations.Args - KEY for get and unset, KEY VALUE for set
+//  do.Force           - set keys which are not in the file yet, or values
+//                       of another type than the current one
+//  do.Restart         - restart the chain if it is running to apply the
+//                       change
+//  do.Timeout         - seconds to wait for the chain to stop
+//
+func ConfigChain(do *definitions.Do) error {
+	nodes := chainNames(do.Name)
+
+	switch do.Type {
+	case "get":
+		if len(do.Operations.Args) != 1 {
+			return fmt.Errorf("Please give me the key to get")
+		}
+		for _, name := range nodes {
+			value, err := getChainConfig(name, do.Operations.Args[0])
+			if err != nil {
+				return err
+			}
+			if len(nodes) > 1 {
+				value = name + "\t" + value
+			}
+			fmt.Fprintln(config.GlobalConfig.Writer, value)
+		}
+		return nil
+	case "set":
+		if len(do.Operations.Args) != 2 {
+			return fmt.Errorf("Please give me the key to set and its value")
+		}
+	case "unset":
+		if len(do.Operations.Args) != 1 {
+			return fmt.Errorf("Please give me the key to unset")
+		}
+	case "edit":
+		if len(nodes) > 1 {
+			return fmt.Errorf("The %s chain is made of several nodes. Please edit the configuration of one node at a time (e.g. %s)", do.Name, nodes[0])
+		}
+	default:
+		return fmt.Errorf("unknown config subcommand %q", do.Type)
+	}
+
+	for _, name := range nodes {
+		var err error
+		switch do.Type {
+		case "set":
+			err = updateChainConfig(name, func(b []byte) ([]byte, error) {
+				return edit.Set(b, do.Operations.Args[0], do.Operations.Args[1], do.Force)
+			})
+		case "unset":
+			err = updateChainConfig(name, func(b []byte) ([]byte, error) {
+				return edit.Unset(b, do.Operations.Args[0])
+			})
+		case "edit":
+			err = updateChainConfig(name, editConfig)
+		}
+		if err != nil {
+			return err
+		}
+
+		if do.Restart {
+			if err := restartChain(name, do.Timeout); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !do.Restart {
+		log.WithField("=>", do.Name).Warn("The change applies when the chain is restarted (use --restart)")
+	}
+	do.Result = "success"
+	return nil
+}
+
+// chainConfigDir returns the chain data container directory the chain
+// configuration is in.
+func chainConfigDir(name string) (string, error) {
+	if !util.IsData(name) && !util.IsDataVolume(name) {
+		return "", fmt.Errorf("The %s chain has no data container. Create the chain first with [eris chains new %s]", name, name)
+	}
+
+	chainID := name
+	if chain, err := loaders.LoadChainDefinition(name, false); err == nil && chain.ChainID != "" {
+		chainID = chain.ChainID
+	}
+	return path.Join(ErisContainerRoot, "chains", chainID), nil
+}
+
+// readChainConfig returns the contents of the chain configuration file.
+func readChainConfig(name string) ([]byte, error) {
+	dir, err := chainConfigDir(name)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := perform.DockerCopyFromData(loaders.LoadDataDefinition(name), path.Join(dir, chainConfigFile), buf); err != nil {
+		return nil, fmt.Errorf("Cannot read the %s chain configuration: %v", name, err)
+	}
+
+	tr := tar.NewReader(buf)
+	if _, err := tr.Next(); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(tr)
+}
+
+// writeChainConfig replaces the chain configuration file with b.
+func writeChainConfig(name string, b []byte) error {
+	dir, err := chainConfigDir(name)
+	if err != nil {
+		return err
+	}
+
+	archive := new(bytes.Buffer)
+	tw := tar.NewWriter(archive)
+	if err := writeTarFile(tw, chainConfigFile, int64(len(b)), bytes.NewReader(b)); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return perform.DockerCopyToData(loaders.LoadDataDefinition(name), dir, archive)
+}
+
+// updateChainConfig passes the chain configuration file through change
+// and writes the result back if it's different.
+func updateChainConfig(name string, change func([]byte) ([]byte, error)) error {
+	b, err := readChainConfig(name)
+	if err != nil {
+		return err
+	}
+	updated, err := change(b)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(b, updated) {
+		log.WithField("=>", name).Info("Chain configuration unchanged")
+		return nil
+	}
+
+	log.WithField("=>", name).Info("Writing chain configuration")
+	return writeChainConfig(name, updated)
+}
+
+func getChainConfig(name, key string) (string, error) {
+	if !edit.ValidKey(key) {
+		return "", fmt.Errorf("%q is not a valid configuration key", key)
+	}
+	b, err := readChainConfig(name)
+	if err != nil {
+		return "", err
+	}
+	tree, err := edit.Decode(b)
+	if err != nil {
+		return "", err
+	}
+	value, ok := edit.Lookup(tree, key)
+	if !ok {
+		return "", fmt.Errorf("There is no %s key in the %s chain configuration", key, name)
+	}
+	return edit.Format(value), nil
+}
+
+// restartChain stops the chain, if it is running, and starts it again.
+func restartChain(name string, timeout uint) error {
+	if !util.IsChain(name, true) {
+		log.WithField("=>", name).Info("Chain is not running, not restarting")
+		return nil
+	}
+	chain, err := loaders.LoadChainDefinition(name, false)
+	if err != nil {
+		return err
+	}
+
+	log.WithField("=>", name).Warn("Restarting chain")
+	if err := perform.DockerStop(chain.Service, chain.Operations, timeout); err != nil {
+		return err
+	}
+	return util.DockerError(util.DockerClient.StartContainer(chain.Operations.SrvContainerName, nil))
+}
+
+// editConfig opens the configuration in the editor and returns the edited
+// one, if it's still a valid TOML document.
+func editConfig(b []byte) ([]byte, error) {
+	file, err := ioutil.TempFile("", "eris-config-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(b); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := Editor(file.Name()); err != nil {
+		return nil, err
+	}
+
+	edited, err := ioutil.ReadFile(file.Name())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := edit.Decode(edited); err != nil {
+		return nil, fmt.Errorf("The edited configuration is not saved: %v", err)
+	}
+	return edited, nil
+}
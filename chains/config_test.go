@@ -0,0 +1,87 @@
+package chains
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/data"
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/tests"
+	"github.com/eris-ltd/eris-cli/util"
+
+	"github.com/eris-ltd/common/go/common"
+)
+
+func TestConfigChain(t *testing.T) {
+	server, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	root, done := tests.WithErisRoot(t)
+	defer done()
+
+	const chain = "test-config"
+	files := map[string]string{
+		"chain/config.toml":         "# node\nmoniker = \"node\"\nfast_sync = true\nseeds = \"\"\n\n[rpc]\nladdr = \"0.0.0.0:46657\"\n",
+		"chains/" + chain + ".toml": "name = \"" + chain + "\"\nchain_id = \"" + chain + "\"\n\n[service]\nimage = \"quay.io/eris/db\"\n",
+		"chains/default.toml":       "[service]\nimage = \"quay.io/eris/db\"\n",
+	}
+	tests.WriteFiles(t, root, files)
+
+	do := def.NowDo()
+	do.Name = chain
+	do.Source = filepath.Join(root, "chain")
+	do.Destination = common.ErisContainerRoot + "/chains/" + chain
+	if err := data.ImportData(do); err != nil {
+		t.Fatalf("expected import to succeed, got %v", err)
+	}
+
+	for _, change := range []struct {
+		args  []string
+		force bool
+		fails bool
+	}{
+		{[]string{"moniker", "marmot"}, false, false},
+		{[]string{"seeds", "10.0.0.1:46656"}, false, false},
+		{[]string{"fast_sync", "10"}, false, true},
+		{[]string{"rpc.pex", "true"}, false, true},
+		{[]string{"rpc.pex", "true"}, true, false},
+		{[]string{"bad key", "1"}, true, true},
+	} {
+		do := def.NowDo()
+		do.Name = chain
+		do.Type = "set"
+		do.Operations.Args = change.args
+		do.Force = change.force
+		if err := ConfigChain(do); (err != nil) != change.fails {
+			t.Fatalf("expected set %v (force %v) to fail %v, got %v", change.args, change.force, change.fails, err)
+		}
+	}
+
+	do = def.NowDo()
+	do.Name = chain
+	do.Type = "unset"
+	do.Operations.Args = []string{"fast_sync"}
+	if err := ConfigChain(do); err != nil {
+		t.Fatalf("expected unset to succeed, got %v", err)
+	}
+
+	content, err := server.ReadFile(util.DataContainerName(chain), common.ErisContainerRoot+"/chains/"+chain+"/config.toml")
+	expected := "# node\nmoniker = \"marmot\"\nseeds = \"10.0.0.1:46656\"\n\n[rpc]\nladdr = \"0.0.0.0:46657\"\npex = true\n"
+	if err != nil || string(content) != expected {
+		t.Fatalf("expected config %q, got %q (%v)", expected, content, err)
+	}
+
+	buf := new(bytes.Buffer)
+	defer func(w io.Writer) { config.GlobalConfig.Writer = w }(config.GlobalConfig.Writer)
+	config.GlobalConfig.Writer = buf
+	do = def.NowDo()
+	do.Name = chain
+	do.Type = "get"
+	do.Operations.Args = []string{"seeds"}
+	if err := ConfigChain(do); err != nil || buf.String() != "10.0.0.1:46656\n" {
+		t.Fatalf("expected the seeds, got %q (%v)", buf.String(), err)
+	}
+}
@@ -0,0 +1,314 @@
+package edit
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+var (
+	keyRe    = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+	tableRe  = regexp.MustCompile(`^\s*\[\s*([^\[\]]+?)\s*\]\s*(#.*)?$`)
+	arrayRe  = regexp.MustCompile(`^\s*\[\[`)
+	quotedRe = regexp.MustCompile(`"([^"]*)"`)
+)
+
+// ValidKey returns true if key is a dotted path of bare TOML keys, e.g.
+// moniker or TABLE.KEY.
+func ValidKey(key string) bool {
+	return keyRe.MatchString(key)
+}
+
+// Set sets key to value in the configuration b. The value is taken
+// as a TOML value (e.g. 10, true, or ["a", "b"]) if it is one, and as
+// a string otherwise. Unless force is true, the key has to be in the
+// configuration already and the value has to be of the same type.
+func Set(b []byte, key, value string, force bool) ([]byte, error) {
+	if !ValidKey(key) {
+		return nil, fmt.Errorf("%q is not a valid configuration key", key)
+	}
+	tree, err := Decode(b)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, literal := parseValue(value)
+	current, ok := Lookup(tree, key)
+	switch {
+	case !ok && !force:
+		return nil, fmt.Errorf("There is no %s key in the chain configuration. Use --force to add it", key)
+	case ok && valueType(current) == "table":
+		return nil, fmt.Errorf("%s is a table, not a key", key)
+	case ok && valueType(current) == "string" && valueType(parsed) != "string":
+		// Strings needn't be quoted on the command line.
+		parsed, literal = value, Quote(value)
+	case ok && valueType(current) != valueType(parsed) && !force:
+		return nil, fmt.Errorf("%s is a %s, not a %s. Use --force to change its type", key, valueType(current), valueType(parsed))
+	}
+
+	updated := SetLine(b, key, literal)
+	tree, err = Decode(updated)
+	if err == nil {
+		if v, ok := Lookup(tree, key); !ok || !reflect.DeepEqual(v, parsed) {
+			err = fmt.Errorf("the value is not where it's expected")
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Cannot set %s in place (%v). Use [eris chains config NAME edit]", key, err)
+	}
+	return updated, nil
+}
+
+// Unset removes key from the configuration b.
+func Unset(b []byte, key string) ([]byte, error) {
+	if !ValidKey(key) {
+		return nil, fmt.Errorf("%q is not a valid configuration key", key)
+	}
+	tree, err := Decode(b)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := Lookup(tree, key); !ok {
+		return nil, fmt.Errorf("There is no %s key in the chain configuration", key)
+	} else if valueType(v) == "table" {
+		return nil, fmt.Errorf("%s is a table, not a key", key)
+	}
+
+	updated, ok := DeleteLine(b, key)
+	if !ok {
+		return nil, fmt.Errorf("Cannot unset %s in place. Use [eris chains config NAME edit]", key)
+	}
+
+	tree, err = Decode(updated)
+	if err == nil {
+		if _, ok := Lookup(tree, key); ok {
+			err = fmt.Errorf("the key is still there")
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Cannot unset %s in place (%v). Use [eris chains config NAME edit]", key, err)
+	}
+	return updated, nil
+}
+
+// SetLine replaces the line of key in the configuration b with a
+// key = literal line, keeping its indentation and its comment, or adds
+// one to the end of the key table, which is added if it's missing.
+func SetLine(b []byte, key, literal string) []byte {
+	table, leaf := splitKey(key)
+	line := leaf + " = " + literal
+
+	lines := strings.SplitAfter(string(b), "\n")
+	if len(lines) != 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if n := len(lines); n != 0 && !strings.HasSuffix(lines[n-1], "\n") {
+		lines[n-1] += "\n"
+	}
+
+	if i := findLine(lines, key); i >= 0 {
+		indent := lines[i][:len(lines[i])-len(strings.TrimLeft(lines[i], " \t"))]
+		lines[i] = indent + line + lineComment(lines[i]) + "\n"
+		return []byte(strings.Join(lines, ""))
+	}
+
+	// The line goes after the last non-blank line of the table.
+	current, last, found := "", -1, table == ""
+	for i, l := range lines {
+		if t, ok := tableName(l); ok {
+			if current == table && found {
+				break
+			}
+			current = t
+			if current == table {
+				found, last = true, i
+			}
+			continue
+		}
+		if current == table && strings.TrimSpace(l) != "" {
+			last = i
+		}
+	}
+	if !found {
+		if len(lines) != 0 {
+			lines = append(lines, "\n")
+		}
+		lines = append(lines, "["+table+"]\n", line+"\n")
+		return []byte(strings.Join(lines, ""))
+	}
+
+	lines = append(lines[:last+1], append([]string{line + "\n"}, lines[last+1:]...)...)
+	return []byte(strings.Join(lines, ""))
+}
+
+// DeleteLine removes the line of key from the configuration b. It returns
+// false if there is no such line.
+func DeleteLine(b []byte, key string) ([]byte, bool) {
+	lines := strings.SplitAfter(string(b), "\n")
+	i := findLine(lines, key)
+	if i < 0 {
+		return b, false
+	}
+	return []byte(strings.Join(append(lines[:i:i], lines[i+1:]...), "")), true
+}
+
+// Decode returns the configuration b as a tree of TOML tables.
+func Decode(b []byte) (map[string]interface{}, error) {
+	tree := make(map[string]interface{})
+	if _, err := toml.Decode(string(b), &tree); err != nil {
+		return nil, fmt.Errorf("The chain configuration is not a valid TOML document: %v", err)
+	}
+	return tree, nil
+}
+
+// Lookup returns the value of the dotted key in the tree.
+func Lookup(tree map[string]interface{}, key string) (interface{}, bool) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		sub, ok := tree[part].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		tree = sub
+	}
+	value, ok := tree[parts[len(parts)-1]]
+	return value, ok
+}
+
+// Format returns strings as they are and other values in the JSON
+// notation, which is also TOML for arrays of plain values.
+func Format(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// Quote returns the TOML string literal of s.
+func Quote(s string) string {
+	return `"` + strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+	).Replace(s) + `"`
+}
+
+// StringList returns the TOML array literal of list.
+func StringList(list []string) string {
+	quoted := make([]string, len(list))
+	for i, s := range list {
+		quoted[i] = Quote(s)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// findLine returns the index of the line which sets key, or -1.
+func findLine(lines []string, key string) int {
+	table, leaf := splitKey(key)
+	keyRe := regexp.MustCompile(`^\s*"?` + regexp.QuoteMeta(leaf) + `"?\s*=`)
+
+	current := ""
+	for i, l := range lines {
+		if t, ok := tableName(l); ok {
+			current = t
+			continue
+		}
+		if current == table && keyRe.MatchString(l) {
+			return i
+		}
+	}
+	return -1
+}
+
+// lineComment returns the comment which ends the line, along with the
+// blanks before it, or "" if there is none. A # in a string doesn't
+// start a comment.
+func lineComment(line string) string {
+	line = strings.TrimRight(line, "\r\n")
+
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[len(strings.TrimRight(line[:i], " \t")):]
+		}
+	}
+	return ""
+}
+
+// tableName returns the name of the table the line is the header of.
+// Array of tables headers have a name no key can have.
+func tableName(line string) (string, bool) {
+	if arrayRe.MatchString(line) {
+		return "[[", true
+	}
+	m := tableRe.FindStringSubmatch(line)
+	if m == nil {
+		return "", false
+	}
+	parts := strings.Split(m[1], ".")
+	for i := range parts {
+		parts[i] = quotedRe.ReplaceAllString(strings.TrimSpace(parts[i]), "$1")
+	}
+	return strings.Join(parts, "."), true
+}
+
+func splitKey(key string) (table, leaf string) {
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		return key[:i], key[i+1:]
+	}
+	return "", key
+}
+
+// parseValue returns the value of the TOML literal s and s, or, if s
+// isn't a TOML literal, s and the TOML string literal of it.
+func parseValue(s string) (interface{}, string) {
+	var v struct{ V interface{} }
+	if _, err := toml.Decode("V = "+s, &v); err == nil && v.V != nil {
+		return v.V, s
+	}
+	return s, Quote(s)
+}
+
+func valueType(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "string"
+	case int64:
+		return "integer"
+	case float64:
+		return "float"
+	case bool:
+		return "boolean"
+	case time.Time:
+		return "datetime"
+	case map[string]interface{}:
+		return "table"
+	case []map[string]interface{}:
+		return "array of tables"
+	default:
+		return "array"
+	}
+}
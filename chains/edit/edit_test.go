@@ -0,0 +1,296 @@
+package edit
+
+import "testing"
+
+func TestSetLine(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		config   string
+		key      string
+		literal  string
+		expected string
+	}{
+		{
+			"replace",
+			"moniker = \"node\"\n",
+			"moniker", `"marmot"`,
+			"moniker = \"marmot\"\n",
+		},
+		{
+			"keep comments",
+			"# node\nmoniker = \"node\" # name\n# seeds = \"a:1\"\n",
+			"moniker", `"marmot"`,
+			"# node\nmoniker = \"marmot\" # name\n# seeds = \"a:1\"\n",
+		},
+		{
+			"hash in strings",
+			"moniker = \"node#1\"\nladdr = 'tcp://#' # local\n",
+			"laddr", `"tcp://0.0.0.0:46656"`,
+			"moniker = \"node#1\"\nladdr = \"tcp://0.0.0.0:46656\" # local\n",
+		},
+		{
+			"escaped quote",
+			"moniker = \"say \\\"#\\\"\"\n",
+			"moniker", `"marmot"`,
+			"moniker = \"marmot\"\n",
+		},
+		{
+			"comment without blanks",
+			"fast_sync = true#default\n",
+			"fast_sync", "false",
+			"fast_sync = false#default\n",
+		},
+		{
+			"commented out key",
+			"# seeds = \"a:1\"\nmoniker = \"node\"\n",
+			"seeds", `"b:2"`,
+			"# seeds = \"a:1\"\nmoniker = \"node\"\nseeds = \"b:2\"\n",
+		},
+		{
+			"quoted key",
+			"\"seeds\" = \"a:1\"\n",
+			"seeds", `"b:2"`,
+			"seeds = \"b:2\"\n",
+		},
+		{
+			"quoted table",
+			"[ \"rpc\" ]\n  laddr = \"0.0.0.0:46657\"\n",
+			"rpc.laddr", `"127.0.0.1:46657"`,
+			"[ \"rpc\" ]\n  laddr = \"127.0.0.1:46657\"\n",
+		},
+		{
+			"top-level key before tables",
+			"moniker = \"node\"\n\n[rpc]\nladdr = \"0.0.0.0:46657\"\n",
+			"seeds", `"a:1"`,
+			"moniker = \"node\"\nseeds = \"a:1\"\n\n[rpc]\nladdr = \"0.0.0.0:46657\"\n",
+		},
+		{
+			"key of another table",
+			"[p2p]\nseeds = \"a:1\"\n",
+			"seeds", `"b:2"`,
+			"seeds = \"b:2\"\n[p2p]\nseeds = \"a:1\"\n",
+		},
+		{
+			"new key of a table",
+			"[rpc]\nladdr = \"0.0.0.0:46657\"\n\n[p2p]\npex = true\n",
+			"rpc.timeout", "10",
+			"[rpc]\nladdr = \"0.0.0.0:46657\"\ntimeout = 10\n\n[p2p]\npex = true\n",
+		},
+		{
+			"new table",
+			"moniker = \"node\"\n",
+			"rpc.laddr", `"0.0.0.0:46657"`,
+			"moniker = \"node\"\n\n[rpc]\nladdr = \"0.0.0.0:46657\"\n",
+		},
+		{
+			"array of tables",
+			"seeds = \"\"\n\n[[validators]]\nseeds = \"a:1\"\n",
+			"seeds", `"b:2"`,
+			"seeds = \"b:2\"\n\n[[validators]]\nseeds = \"a:1\"\n",
+		},
+		{
+			"new key before array of tables",
+			"moniker = \"node\"\n\n[[validators]]\nname = \"v\"\n",
+			"seeds", `"a:1"`,
+			"moniker = \"node\"\nseeds = \"a:1\"\n\n[[validators]]\nname = \"v\"\n",
+		},
+		{
+			"no trailing newline",
+			"moniker = \"node\"",
+			"moniker", `"marmot"`,
+			"moniker = \"marmot\"\n",
+		},
+		{
+			"new key without trailing newline",
+			"moniker = \"node\"",
+			"seeds", `"a:1"`,
+			"moniker = \"node\"\nseeds = \"a:1\"\n",
+		},
+		{
+			"empty",
+			"",
+			"seeds", `"a:1"`,
+			"seeds = \"a:1\"\n",
+		},
+	} {
+		if out := string(SetLine([]byte(test.config), test.key, test.literal)); out != test.expected {
+			t.Fatalf("%s: expected %q, got %q", test.name, test.expected, out)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	const config = "# node\nmoniker = \"node\"\nfast_sync = true\nseeds = \"\"\n\n[rpc]\nladdr = \"0.0.0.0:46657\"\n\n[[validators]]\nname = \"v\"\n"
+
+	for _, test := range []struct {
+		name     string
+		config   string
+		key      string
+		value    string
+		force    bool
+		expected string // empty if it fails
+	}{
+		{
+			"string",
+			config, "moniker", "marmot", false,
+			"# node\nmoniker = \"marmot\"\nfast_sync = true\nseeds = \"\"\n\n[rpc]\nladdr = \"0.0.0.0:46657\"\n\n[[validators]]\nname = \"v\"\n",
+		},
+		{
+			"string which looks like a number",
+			config, "seeds", "10", false,
+			"# node\nmoniker = \"node\"\nfast_sync = true\nseeds = \"10\"\n\n[rpc]\nladdr = \"0.0.0.0:46657\"\n\n[[validators]]\nname = \"v\"\n",
+		},
+		{
+			"string with quotes",
+			config, "moniker", `say "hi"`, false,
+			"# node\nmoniker = \"say \\\"hi\\\"\"\nfast_sync = true\nseeds = \"\"\n\n[rpc]\nladdr = \"0.0.0.0:46657\"\n\n[[validators]]\nname = \"v\"\n",
+		},
+		{
+			"key of a table",
+			config, "rpc.laddr", "127.0.0.1:46657", false,
+			"# node\nmoniker = \"node\"\nfast_sync = true\nseeds = \"\"\n\n[rpc]\nladdr = \"127.0.0.1:46657\"\n\n[[validators]]\nname = \"v\"\n",
+		},
+		{
+			"other type",
+			config, "fast_sync", "10", false,
+			"",
+		},
+		{
+			"other type forced",
+			config, "fast_sync", "10", true,
+			"# node\nmoniker = \"node\"\nfast_sync = 10\nseeds = \"\"\n\n[rpc]\nladdr = \"0.0.0.0:46657\"\n\n[[validators]]\nname = \"v\"\n",
+		},
+		{
+			"new key",
+			config, "pex", "true", false,
+			"",
+		},
+		{
+			"new key forced",
+			config, "rpc.pex", "true", true,
+			"# node\nmoniker = \"node\"\nfast_sync = true\nseeds = \"\"\n\n[rpc]\nladdr = \"0.0.0.0:46657\"\npex = true\n\n[[validators]]\nname = \"v\"\n",
+		},
+		{
+			"list forced",
+			config, "peers", `["a:1", "b:2"]`, true,
+			"# node\nmoniker = \"node\"\nfast_sync = true\nseeds = \"\"\npeers = [\"a:1\", \"b:2\"]\n\n[rpc]\nladdr = \"0.0.0.0:46657\"\n\n[[validators]]\nname = \"v\"\n",
+		},
+		{
+			"table",
+			config, "rpc", "x", true,
+			"",
+		},
+		{
+			"key of an array of tables",
+			config, "validators.name", "w", true,
+			"",
+		},
+		{
+			"invalid key",
+			config, "bad key", "1", true,
+			"",
+		},
+		{
+			"invalid configuration",
+			"moniker = \n", "moniker", "marmot", true,
+			"",
+		},
+		{
+			"no trailing newline",
+			"moniker = \"node\"", "moniker", "marmot", false,
+			"moniker = \"marmot\"\n",
+		},
+	} {
+		out, err := Set([]byte(test.config), test.key, test.value, test.force)
+		if test.expected == "" {
+			if err == nil {
+				t.Fatalf("%s: expected set %s to fail, got %q", test.name, test.key, out)
+			}
+			continue
+		}
+		if err != nil || string(out) != test.expected {
+			t.Fatalf("%s: expected %q, got %q (%v)", test.name, test.expected, out, err)
+		}
+	}
+}
+
+func TestUnset(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		config   string
+		key      string
+		expected string // empty if it fails
+	}{
+		{
+			"keep comments",
+			"# node\nmoniker = \"node\"\n# seeds\nseeds = \"a:1\" # staging\n",
+			"seeds",
+			"# node\nmoniker = \"node\"\n# seeds\n",
+		},
+		{
+			"quoted key",
+			"moniker = \"node\"\n\"seeds\" = \"a:1\"\n",
+			"seeds",
+			"moniker = \"node\"\n",
+		},
+		{
+			"key of a table",
+			"seeds = \"\"\n\n[rpc]\nladdr = \"0.0.0.0:46657\"\npex = true\n",
+			"rpc.pex",
+			"seeds = \"\"\n\n[rpc]\nladdr = \"0.0.0.0:46657\"\n",
+		},
+		{
+			"key of a quoted table",
+			"[\"rpc\"]\nladdr = \"0.0.0.0:46657\"\npex = true\n",
+			"rpc.pex",
+			"[\"rpc\"]\nladdr = \"0.0.0.0:46657\"\n",
+		},
+		{
+			"array of tables",
+			"seeds = \"\"\n\n[[validators]]\nseeds = \"a:1\"\n",
+			"seeds",
+			"\n[[validators]]\nseeds = \"a:1\"\n",
+		},
+		{
+			"key of an array of tables",
+			"[[validators]]\nseeds = \"a:1\"\n",
+			"seeds",
+			"",
+		},
+		{
+			"no trailing newline",
+			"moniker = \"node\"\nseeds = \"a:1\"",
+			"seeds",
+			"moniker = \"node\"\n",
+		},
+		{
+			"missing key",
+			"moniker = \"node\"\n",
+			"seeds",
+			"",
+		},
+		{
+			"table",
+			"[rpc]\nladdr = \"0.0.0.0:46657\"\n",
+			"rpc",
+			"",
+		},
+		{
+			"invalid key",
+			"moniker = \"node\"\n",
+			"bad key",
+			"",
+		},
+	} {
+		out, err := Unset([]byte(test.config), test.key)
+		if test.expected == "" {
+			if err == nil {
+				t.Fatalf("%s: expected unset %s to fail, got %q", test.name, test.key, out)
+			}
+			continue
+		}
+		if err != nil || string(out) != test.expected {
+			t.Fatalf("%s: expected %q, got %q (%v)", test.name, test.expected, out, err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package edit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// WriteField sets the key field of the chain definition file to value
+// (a string or a list of strings), or removes it if value is nil. Keys
+// are dotted paths, e.g. seeds or service.image. TOML files are changed
+// in place, keeping their comments and the order of the fields.
+func WriteField(file, key string, value interface{}) error {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	path := strings.Split(key, ".")
+	switch filepath.Ext(file) {
+	case ".toml":
+		if value == nil {
+			b, _ = DeleteLine(b, key)
+		} else {
+			b = SetLine(b, key, literal(value))
+		}
+
+		tree, err := Decode(b)
+		if err != nil {
+			return fmt.Errorf("Cannot write %s to %s in place. Please edit it with [eris chains edit]", key, file)
+		}
+		if written, ok := Lookup(tree, key); ok != (value != nil) || ok && fmt.Sprint(written) != fmt.Sprint(value) {
+			return fmt.Errorf("Cannot write %s to %s in place. Please edit it with [eris chains edit]", key, file)
+		}
+	case ".json":
+		var def map[string]interface{}
+		if err := json.Unmarshal(b, &def); err != nil {
+			return err
+		}
+		setJSONField(def, path, value)
+		if b, err = json.MarshalIndent(def, "", "  "); err != nil {
+			return err
+		}
+		b = append(b, '\n')
+	case ".yaml", ".yml":
+		var def yaml.MapSlice
+		if err := yaml.Unmarshal(b, &def); err != nil {
+			return err
+		}
+		if b, err = yaml.Marshal(setYAMLField(def, path, value)); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("Cannot write %s to %s. Please edit it with [eris chains edit]", key, file)
+	}
+
+	return ioutil.WriteFile(file, b, 0644)
+}
+
+// literal returns the TOML literal of a definition field value.
+func literal(value interface{}) string {
+	if list, ok := value.([]string); ok {
+		return StringList(list)
+	}
+	return Quote(fmt.Sprint(value))
+}
+
+// setJSONField sets the field at path of the def object to value, or
+// removes it if value is nil. Missing objects on the way are added.
+func setJSONField(def map[string]interface{}, path []string, value interface{}) {
+	if len(path) == 1 {
+		if value == nil {
+			delete(def, path[0])
+		} else {
+			def[path[0]] = value
+		}
+		return
+	}
+
+	sub, ok := def[path[0]].(map[string]interface{})
+	if !ok {
+		if value == nil {
+			return
+		}
+		sub = make(map[string]interface{})
+		def[path[0]] = sub
+	}
+	setJSONField(sub, path[1:], value)
+}
+
+// setYAMLField returns def with the field at path set to value, or
+// removed if value is nil. The order of the fields is kept.
+func setYAMLField(def yaml.MapSlice, path []string, value interface{}) yaml.MapSlice {
+	found := -1
+	for i, item := range def {
+		if item.Key == path[0] {
+			found = i
+		}
+	}
+
+	if len(path) > 1 {
+		var sub yaml.MapSlice
+		if found >= 0 {
+			sub, _ = def[found].Value.(yaml.MapSlice)
+		}
+		if found < 0 && value == nil {
+			return def
+		}
+		value = setYAMLField(sub, path[1:], value)
+	}
+
+	switch {
+	case value == nil && found >= 0:
+		return append(def[:found], def[found+1:]...)
+	case found >= 0:
+		def[found].Value = value
+	case value != nil:
+		def = append(def, yaml.MapItem{Key: path[0], Value: value})
+	}
+	return def
+}
@@ -0,0 +1,178 @@
+package edit
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteField(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eris-definition")
+	if err != nil {
+		t.Fatalf("expected temp dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, test := range []struct {
+		name       string
+		file       string
+		definition string
+		key        string
+		value      interface{}
+		expected   string // empty if it fails
+	}{
+		{
+			"add",
+			"chain.toml",
+			"# staging\nname = \"chain\"\n\n[service]\nimage = \"quay.io/eris/db\"\n",
+			"seeds",
+			[]string{"a:1", "b:2"},
+			"# staging\nname = \"chain\"\nseeds = [\"a:1\", \"b:2\"]\n\n[service]\nimage = \"quay.io/eris/db\"\n",
+		},
+		{
+			"replace",
+			"chain.toml",
+			"name = \"chain\"\nseeds = [\"a:1\"] # staging\n",
+			"seeds",
+			[]string{"b:2"},
+			"name = \"chain\"\nseeds = [\"b:2\"] # staging\n",
+		},
+		{
+			"remove",
+			"chain.toml",
+			"name = \"chain\"\n# staging\nseeds = [\"a:1\"]\n\n[service]\nimage = \"quay.io/eris/db\"\n",
+			"seeds",
+			nil,
+			"name = \"chain\"\n# staging\n\n[service]\nimage = \"quay.io/eris/db\"\n",
+		},
+		{
+			"quoted key",
+			"chain.toml",
+			"name = \"chain\"\n\"seeds\" = [\"a:1\"]\n",
+			"seeds",
+			[]string{"b:2"},
+			"name = \"chain\"\nseeds = [\"b:2\"]\n",
+		},
+		{
+			"array of tables",
+			"chain.toml",
+			"name = \"chain\"\n\n[[nodes]]\nseeds = [\"a:1\"]\n",
+			"seeds",
+			[]string{"b:2"},
+			"name = \"chain\"\nseeds = [\"b:2\"]\n\n[[nodes]]\nseeds = [\"a:1\"]\n",
+		},
+		{
+			"no trailing newline",
+			"chain.toml",
+			"name = \"chain\"",
+			"seeds",
+			[]string{"a:1"},
+			"name = \"chain\"\nseeds = [\"a:1\"]\n",
+		},
+		{
+			"seeds of a table",
+			"chain.toml",
+			"[service]\nseeds = 1\n",
+			"seeds",
+			[]string{"a:1"},
+			"seeds = [\"a:1\"]\n[service]\nseeds = 1\n",
+		},
+		{
+			"multiline seeds",
+			"chain.toml",
+			"seeds = [\n  \"a:1\",\n]\n",
+			"seeds",
+			[]string{"b:2"},
+			"",
+		},
+		{
+			"json",
+			"chain.json",
+			`{"name": "chain", "seeds": ["a:1"]}`,
+			"seeds",
+			[]string{"b:2"},
+			"{\n  \"name\": \"chain\",\n  \"seeds\": [\n    \"b:2\"\n  ]\n}\n",
+		},
+		{
+			"json remove",
+			"chain.json",
+			`{"name": "chain", "seeds": ["a:1"]}`,
+			"seeds",
+			nil,
+			"{\n  \"name\": \"chain\"\n}\n",
+		},
+		{
+			"yaml",
+			"chain.yaml",
+			"name: chain\nchain_id: chain\n",
+			"seeds",
+			[]string{"a:1"},
+			"name: chain\nchain_id: chain\nseeds:\n- a:1\n",
+		},
+		{
+			"yaml remove",
+			"chain.yml",
+			"name: chain\nseeds:\n- a:1\nchain_id: chain\n",
+			"seeds",
+			nil,
+			"name: chain\nchain_id: chain\n",
+		},
+		{
+			"image",
+			"chain.toml",
+			"name = \"chain\"\n\n[service]\n# pinned\nimage = \"quay.io/eris/db\"\ndata_container = true\n",
+			"service.image",
+			"quay.io/eris/db:next",
+			"name = \"chain\"\n\n[service]\n# pinned\nimage = \"quay.io/eris/db:next\"\ndata_container = true\n",
+		},
+		{
+			"image without service",
+			"chain.toml",
+			"name = \"chain\"\n",
+			"service.image",
+			"quay.io/eris/db:next",
+			"name = \"chain\"\n\n[service]\nimage = \"quay.io/eris/db:next\"\n",
+		},
+		{
+			"json image",
+			"chain.json",
+			`{"name": "chain", "service": {"image": "quay.io/eris/db"}}`,
+			"service.image",
+			"quay.io/eris/db:next",
+			"{\n  \"name\": \"chain\",\n  \"service\": {\n    \"image\": \"quay.io/eris/db:next\"\n  }\n}\n",
+		},
+		{
+			"yaml image",
+			"chain.yaml",
+			"name: chain\nservice:\n  image: quay.io/eris/db\n  data_container: true\n",
+			"service.image",
+			"quay.io/eris/db:next",
+			"name: chain\nservice:\n  image: quay.io/eris/db:next\n  data_container: true\n",
+		},
+		{
+			"unknown format",
+			"chain.ini",
+			"name = chain\n",
+			"seeds",
+			[]string{"a:1"},
+			"",
+		},
+	} {
+		file := filepath.Join(dir, test.file)
+		if err := ioutil.WriteFile(file, []byte(test.definition), 0644); err != nil {
+			t.Fatalf("%s: expected definition file, got %v", test.name, err)
+		}
+		err := WriteField(file, test.key, test.value)
+		out, _ := ioutil.ReadFile(file)
+		if test.expected == "" {
+			if err == nil || string(out) != test.definition {
+				t.Fatalf("%s: expected writing %s to fail and keep the file, got %q (%v)", test.name, test.key, out, err)
+			}
+			continue
+		}
+		if err != nil || string(out) != test.expected {
+			t.Fatalf("%s: expected %q, got %q (%v)", test.name, test.expected, out, err)
+		}
+	}
+}
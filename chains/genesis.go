@@ -0,0 +1,140 @@
+package chains
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/genesis"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/util"
+
+	. "github.com/eris-ltd/common/go/common"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// GenesisFile returns the path to a genesis file given either as a path
+// or as a chain name. For a chain, the genesis file is looked up on the
+// host, in the ~/.eris/chains/NAME directory or else in the first of its
+// subdirectories [eris chains make] writes which has one. The genesis
+// file of a chain node is the one of its validator.
+func GenesisFile(name string) (string, error) {
+	if info, err := os.Stat(name); err == nil && !info.IsDir() {
+		return name, nil
+	}
+
+	dir := filepath.Join(ChainsPath, name)
+	if base, ok := loaders.ChainNodeOf(name); ok {
+		_, i, _ := util.SplitChainNodeName(name)
+		dir = filepath.Join(ChainsPath, base, strings.ToLower(fmt.Sprintf("%s_validator_%03d", base, i)))
+	}
+
+	file := filepath.Join(dir, "genesis.json")
+	if _, err := os.Stat(file); err == nil {
+		return file, nil
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*", "genesis.json")); len(files) != 0 {
+		return files[0], nil
+	}
+	return "", fmt.Errorf("There is no %s genesis file, nor a genesis file for a chain by that name in %s. To get it out of a running chain use [eris chains cat %s genesis]", name, dir, name)
+}
+
+// ValidateGenesis checks genesis files against the genesis schema and
+// writes the problems found in each to the configured writer. It returns
+// an error if any of the files is not valid. It doesn't need Docker.
+//
+//  do.Operations.Args - genesis file paths or chain names (see GenesisFile)
+//
+func ValidateGenesis(do *definitions.Do) error {
+	invalid := 0
+	for _, name := range do.Operations.Args {
+		file, err := GenesisFile(name)
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		log.WithField("file", file).Debug("Validating genesis file")
+		errs := genesis.Validate(b)
+		if len(errs) == 0 {
+			fmt.Fprintf(config.GlobalConfig.Writer, "%s: OK\n", file)
+			continue
+		}
+
+		invalid++
+		for _, err := range errs {
+			fmt.Fprintf(config.GlobalConfig.Writer, "%s: %v\n", file, err)
+		}
+	}
+
+	if invalid != 0 {
+		return fmt.Errorf("%d of %d genesis files are not valid", invalid, len(do.Operations.Args))
+	}
+	return nil
+}
+
+// ShowGenesis writes the chain ID, accounts, and validators of a genesis
+// file to the configured writer, as tables or, if do.JSON is set, as
+// a JSON document.
+//
+//  do.Name - genesis file path or chain name (see GenesisFile)
+//  do.JSON - machine readable output
+//
+func ShowGenesis(do *definitions.Do) error {
+	g, err := loadGenesis(do.Name)
+	if err != nil {
+		return err
+	}
+
+	format := ""
+	if do.JSON {
+		format = "json"
+	}
+	return genesis.Show(config.GlobalConfig.Writer, g, format)
+}
+
+// DiffGenesis writes the differences between two genesis files to the
+// configured writer (see genesis.Diff) and returns an error if there are
+// any, so the exit status tells whether the files match.
+//
+//  do.Operations.Args - two genesis file paths or chain names
+//
+func DiffGenesis(do *definitions.Do) error {
+	if len(do.Operations.Args) != 2 {
+		return fmt.Errorf("Please give me two genesis files or chains to compare")
+	}
+
+	a, err := loadGenesis(do.Operations.Args[0])
+	if err != nil {
+		return err
+	}
+	b, err := loadGenesis(do.Operations.Args[1])
+	if err != nil {
+		return err
+	}
+
+	diff := genesis.Diff(a, b)
+	for _, line := range diff {
+		fmt.Fprintln(config.GlobalConfig.Writer, line)
+	}
+	if len(diff) != 0 {
+		return fmt.Errorf("The genesis files differ")
+	}
+	return nil
+}
+
+func loadGenesis(name string) (*genesis.Genesis, error) {
+	file, err := GenesisFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return genesis.Load(file)
+}
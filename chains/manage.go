@@ -9,6 +9,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/eris-ltd/eris-cli/config"
 	"github.com/eris-ltd/eris-cli/data"
@@ -198,18 +199,96 @@ func InspectChain(do *definitions.Do) error {
 //  do.Follow  - follow the logs until the user sends SIGTERM (optional)
 //  do.Tail    - number of lines to display (can be "all") (optional)
 //
+// For a chain made of several validator nodes, the logs of every node
+// are displayed, one after another or, when following, as they come.
 func LogsChain(do *definitions.Do) error {
-	chain, err := loaders.LoadChainDefinition(do.Name, false)
-	if err != nil {
-		return err
+	names := chainNames(do.Name)
+	if len(names) == 1 {
+		return logsChain(names[0], do.Follow, do.Tail, config.GlobalConfig.Writer, config.GlobalConfig.ErrorWriter)
+	}
+
+	if !do.Follow {
+		for _, name := range names {
+			fmt.Fprintf(config.GlobalConfig.Writer, "==> %s <==\n", name)
+			if err := logsChain(name, false, do.Tail, config.GlobalConfig.Writer, config.GlobalConfig.ErrorWriter); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// The lines of the nodes come interleaved, each prefixed with the
+	// name of the node it comes from.
+	mu := new(sync.Mutex)
+	errs := make(chan error, len(names))
+	for _, name := range names {
+		go func(name string) {
+			stdout := &prefixWriter{mu: mu, w: config.GlobalConfig.Writer, prefix: name + " | "}
+			stderr := &prefixWriter{mu: mu, w: config.GlobalConfig.ErrorWriter, prefix: name + " | "}
+			err := logsChain(name, true, do.Tail, stdout, stderr)
+			stdout.Flush()
+			stderr.Flush()
+			errs <- err
+		}(name)
+	}
+	var err error
+	for range names {
+		if err2 := <-errs; err2 != nil && err == nil {
+			err = err2
+		}
 	}
+	return err
+}
 
-	err = perform.DockerLogs(chain.Service, chain.Operations, do.Follow, do.Tail)
+func logsChain(name string, follow bool, tail string, stdout, stderr io.Writer) error {
+	chain, err := loaders.LoadChainDefinition(name, false)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return perform.DockerLogsTo(chain.Service, chain.Operations, follow, tail, stdout, stderr)
+}
+
+// prefixWriter writes whole lines to w, each prefixed with prefix. The
+// writers which share mu don't interleave their lines.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	p.buf = append(p.buf, b...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			return len(b), nil
+		}
+		if err := p.writeLine(p.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+}
+
+// Flush writes what is left of the last line, if it doesn't end with a
+// newline.
+func (p *prefixWriter) Flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+	line := append(p.buf, '\n')
+	p.buf = nil
+	return p.writeLine(line)
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err := fmt.Fprintf(p.w, "%s%s", p.prefix, line)
+	return err
 }
 
 // ExportChain exports a chain definition file to IPFS for easy
@@ -430,7 +509,14 @@ func RenameChain(do *definitions.Do) error {
 	return nil
 }
 
+// UpdateChain recreates the chain container, pulling the chain image first
+// if do.Pull is set. If do.Image is set, the chain is upgraded to that image
+// instead, with a rollback if it fails (see UpgradeChain).
 func UpdateChain(do *definitions.Do) error {
+	if do.Image != "" {
+		return UpgradeChain(do)
+	}
+
 	chain, err := loaders.LoadChainDefinition(do.Name, false)
 	if err != nil {
 		return err
@@ -451,25 +537,36 @@ func UpdateChain(do *definitions.Do) error {
 	return nil
 }
 
+// RemoveChain removes the chain container, or the containers of all its
+// nodes if the chain is made of several validator nodes, and the chain
+// network. Removing a single node leaves the network and the chain files
+// in place.
 func RemoveChain(do *definitions.Do) error {
-	chain, err := loaders.LoadChainDefinition(do.Name, false)
-	if err != nil {
-		return err
-	}
-
-	if util.IsChain(chain.Name, false) {
-		if err = perform.DockerRemove(chain.Service, chain.Operations, do.RmD, do.Volumes, do.Force); err != nil {
+	for _, name := range chainNames(do.Name) {
+		chain, err := loaders.LoadChainDefinition(name, false)
+		if err != nil {
 			return err
 		}
-	} else {
-		log.Info("Chain container does not exist")
+
+		if util.IsChain(chain.Name, false) {
+			if err = perform.DockerRemove(chain.Service, chain.Operations, do.RmD, do.Volumes, do.Force); err != nil {
+				return err
+			}
+		} else {
+			log.WithField("=>", chain.Name).Info("Chain container does not exist")
+		}
+	}
+
+	if _, isNode := loaders.ChainNodeOf(do.Name); isNode {
+		return nil
+	}
+
+	if err := util.RemoveNetwork(util.ChainNetworkName(do.Name)); err != nil {
+		log.WithField("=>", do.Name).Warnf("Could not remove chain network: %v", err)
 	}
 
 	if do.File {
 		oldFile := util.GetFileByNameAndType("chains", do.Name)
-		if err != nil {
-			return err
-		}
 		log.WithField("file", oldFile).Warn("Removing file")
 		if err := os.Remove(oldFile); err != nil {
 			return err
@@ -539,13 +636,16 @@ func RegisterChain(do *definitions.Do) error {
 	}
 	log.WithField("image", chain.Service.Image).Debug("Chain loaded")
 
+	// the seed given and those of the chain definition
+	seeds := append(append([]string{}, do.Operations.Args...), chain.Seeds...)
+
 	// set chainid and other vars
 	envVars := []string{
-		fmt.Sprintf("CHAIN_ID=%s", do.ChainID),                 // of the etcb chain
-		fmt.Sprintf("PUBKEY=%s", do.Pubkey),                    // pubkey to register chain with
-		fmt.Sprintf("ETCB_CHAIN_ID=%s", etcbChain),             // chain id of the etcb chain
-		fmt.Sprintf("NODE_ADDR=%s", do.Gateway),                // etcb node to send the register tx to
-		fmt.Sprintf("NEW_P2P_SEEDS=%s", do.Operations.Args[0]), // seeds to register for the chain // TODO: deal with multi seed (needs support in tendermint)
+		fmt.Sprintf("CHAIN_ID=%s", do.ChainID),                    // of the etcb chain
+		fmt.Sprintf("PUBKEY=%s", do.Pubkey),                       // pubkey to register chain with
+		fmt.Sprintf("ETCB_CHAIN_ID=%s", etcbChain),                // chain id of the etcb chain
+		fmt.Sprintf("NODE_ADDR=%s", do.Gateway),                   // etcb node to send the register tx to
+		fmt.Sprintf("NEW_P2P_SEEDS=%s", strings.Join(seeds, ",")), // seeds to register for the chain
 	}
 	envVars = append(envVars, do.Env...)
 
@@ -0,0 +1,185 @@
+package chains
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eris-ltd/eris-cli/chains/edit"
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/util"
+
+	. "github.com/eris-ltd/common/go/common"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// NewChainNodes creates a chain made of do.Nodes validator nodes on the
+// local machine and starts them. Every node gets a data container and
+// a chain container of its own, named NAME-0, NAME-1, etc., and is seeded
+// with the addresses of the other nodes on the chain network. The nodes
+// are one unit for starting, stopping, logs and removal under the chain
+// name, and can be addressed one by one by their own names.
+//
+// The validator files are taken from the ~/.eris/chains/NAME/NAME_validator_NNN
+// directories [eris chains make] writes; they are made with it if missing.
+//
+//  do.Name  - name of the chain (required)
+//  do.Nodes - number of validator nodes (required)
+//
+// See NewChain for the rest of the parameters, which apply to every node.
+func NewChainNodes(do *definitions.Do) error {
+	if do.Nodes < 1 {
+		return fmt.Errorf("A chain needs at least one node, got %d", do.Nodes)
+	}
+	for _, f := range []struct{ flag, value string }{
+		{"--dir", do.Path},
+		{"--genesis", do.GenesisFile},
+		{"--priv", do.Priv},
+	} {
+		if f.value != "" {
+			return fmt.Errorf("The marmots take the validator files of the nodes from ~/.eris/chains/%s. Please drop the %s flag", do.Name, f.flag)
+		}
+	}
+
+	dirs, err := validatorDirs(do)
+	if err != nil {
+		return err
+	}
+
+	// All nodes have the chain ID of the shared genesis file.
+	if do.ChainID, err = getChainIDFromGenesis(filepath.Join(dirs[0], "genesis.json"), do.Name); err != nil {
+		return err
+	}
+
+	if err := writeNodesDefinition(do); err != nil {
+		return err
+	}
+
+	for i, dir := range dirs {
+		seeds := nodeSeeds(do.Name, i, len(dirs))
+		if err := setSeeds(filepath.Join(dir, "config.toml"), seeds); err != nil {
+			return err
+		}
+
+		doNode := *do
+		doNode.Name = util.ChainNodeName(do.Name, i)
+		doNode.Path = dir
+		doNode.Nodes = 0
+
+		log.WithFields(log.Fields{
+			"=>":    doNode.Name,
+			"seeds": seeds,
+		}).Info("Setting up chain node")
+		if err := newChain(&doNode); err != nil {
+			return fmt.Errorf("Error setting up node %s: %v", doNode.Name, err)
+		}
+	}
+
+	do.Result = "success"
+	return nil
+}
+
+// validatorDirs returns the directories with the files of every node's
+// validator, making them with [eris chains make] if any is missing.
+func validatorDirs(do *definitions.Do) ([]string, error) {
+	dirs := make([]string, do.Nodes)
+	missing := false
+	for i := range dirs {
+		dirs[i] = filepath.Join(ChainsPath, do.Name, strings.ToLower(fmt.Sprintf("%s_validator_%03d", do.Name, i)))
+		if !util.DoesDirExist(dirs[i]) {
+			missing = true
+		}
+	}
+	if !missing {
+		return dirs, nil
+	}
+
+	log.WithField("=>", do.Name).Warnf("Making %d validators", do.Nodes)
+	doMake := definitions.NowDo()
+	doMake.Name = do.Name
+	doMake.AccountTypes = []string{fmt.Sprintf("Validator:%d", do.Nodes)}
+	if err := MakeChain(doMake); err != nil {
+		return nil, err
+	}
+
+	for _, dir := range dirs {
+		if !util.DoesDirExist(dir) {
+			return nil, fmt.Errorf("The marmots could not find the validator files in %s after making the chain", dir)
+		}
+	}
+	return dirs, nil
+}
+
+// writeNodesDefinition writes the chain definition file with a nodes
+// section. An existing definition is kept if it has the same number of
+// nodes.
+func writeNodesDefinition(do *definitions.Do) error {
+	fileName := filepath.Join(ChainsPath, do.Name) + ".toml"
+	if _, err := os.Stat(fileName); err == nil {
+		chain, err := loaders.LoadChainDefinition(do.Name, false)
+		if err != nil {
+			return err
+		}
+		if chain.Nodes == nil || chain.Nodes.Count != do.Nodes {
+			return fmt.Errorf("The %s chain is already defined in %s with a different number of nodes. Remove the file or edit its nodes section", do.Name, fileName)
+		}
+		return nil
+	}
+
+	chain := loaders.MockChainDefinition(do.Name, do.ChainID, false)
+	chain.Nodes = &definitions.ChainNodes{Count: do.Nodes}
+
+	var err error
+	chain.Maintainer.Name, chain.Maintainer.Email, err = config.GitConfigUser()
+	if err != nil {
+		log.Debug(err.Error())
+	}
+
+	if err := WriteChainDefinitionFile(chain, fileName); err != nil {
+		return fmt.Errorf("error writing chain definition to file: %v", err)
+	}
+	return nil
+}
+
+// nodeSeeds returns the addresses of the other nodes of the chain name
+// made of count nodes, which the node i takes as seeds.
+func nodeSeeds(name string, i, count int) []string {
+	var seeds []string
+	for j := 0; j < count; j++ {
+		if j != i {
+			seeds = append(seeds, util.ChainNodeName(name, j)+":"+ChainP2PPort)
+		}
+	}
+	return seeds
+}
+
+// setSeeds sets the seeds field of a tendermint config.toml file,
+// creating the file if it doesn't exist.
+func setSeeds(file string, seeds []string) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	content = edit.SetLine(content, "seeds", edit.Quote(strings.Join(seeds, ",")))
+	return ioutil.WriteFile(file, content, 0644)
+}
+
+// chainNames returns the short names of the chains the named chain is
+// made of: its nodes, if it is made of several validator nodes, or the
+// chain itself otherwise.
+func chainNames(name string) []string {
+	chain, err := loaders.LoadChainDefinition(name, false)
+	if err != nil {
+		return []string{name}
+	}
+	if nodes := loaders.ChainNodeNames(chain); len(nodes) != 0 {
+		return nodes
+	}
+	return []string{name}
+}
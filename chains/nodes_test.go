@@ -0,0 +1,136 @@
+package chains
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/eris-ltd/eris-cli/config"
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/tests"
+	"github.com/eris-ltd/eris-cli/util"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestLogsChainNodes(t *testing.T) {
+	server, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	root, done := tests.WithErisRoot(t)
+	defer done()
+
+	const chain = "test-logs"
+	tests.WriteFiles(t, root, map[string]string{
+		"chains/" + chain + ".toml": "name = \"" + chain + "\"\n\n[nodes]\ncount = 2\n\n[service]\nimage = \"quay.io/eris/db\"\n",
+		"chains/default.toml":       "[service]\nimage = \"quay.io/eris/db\"\n",
+	})
+	server.AddImage("quay.io/eris/db")
+	for i := 0; i < 2; i++ {
+		node := util.ChainNodeName(chain, i)
+		c, err := util.DockerClient.CreateContainer(docker.CreateContainerOptions{
+			Name: util.ChainContainerName(node),
+			Config: &docker.Config{
+				Image:  "quay.io/eris/db",
+				Cmd:    []string{"erisdb"},
+				Labels: util.Labels(node, &def.Operation{ContainerType: def.TypeChain}),
+			},
+		})
+		if err != nil {
+			t.Fatalf("expected node container to be created, got %v", err)
+		}
+		server.WriteLog(c.ID, "block 1\nblock 2\n")
+	}
+
+	buf := new(bytes.Buffer)
+	defer func(w io.Writer) { config.GlobalConfig.Writer = w }(config.GlobalConfig.Writer)
+	config.GlobalConfig.Writer = buf
+	do := def.NowDo()
+	do.Name = chain
+	do.Follow = true
+	do.Tail = "all"
+	if err := LogsChain(do); err != nil {
+		t.Fatalf("expected logs to succeed, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	sort.Strings(lines)
+	expected := []string{"test-logs-0 | block 1", "test-logs-0 | block 2", "test-logs-1 | block 1", "test-logs-1 | block 2"}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("expected the lines prefixed with the node names %q, got %q", expected, lines)
+	}
+
+	do = def.NowDo()
+	do.Name = chain
+	do.Nodes = 2
+	do.GenesisFile = filepath.Join(root, "genesis.json")
+	if err := NewChainNodes(do); err == nil || !strings.Contains(err.Error(), "--genesis") {
+		t.Fatalf("expected the --genesis flag to be refused, got %v", err)
+	}
+}
+
+func TestSetSeeds(t *testing.T) {
+	dir, err := ioutil.TempDir("", "eris-seeds")
+	if err != nil {
+		t.Fatalf("expected temp dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, test := range []struct {
+		name     string
+		config   string // missing if empty
+		seeds    []string
+		expected string
+	}{
+		{
+			"missing file",
+			"",
+			[]string{"chain-1:46656", "chain-2:46656"},
+			"seeds = \"chain-1:46656,chain-2:46656\"\n",
+		},
+		{
+			"replace",
+			"# node\nmoniker = \"node\"\nseeds = \"\"\nfast_sync = true\n",
+			[]string{"chain-1:46656"},
+			"# node\nmoniker = \"node\"\nseeds = \"chain-1:46656\"\nfast_sync = true\n",
+		},
+		{
+			"commented out",
+			"# seeds = \"a:1\"\nmoniker = \"node\"\n\n[rpc]\nladdr = \"0.0.0.0:46657\"\n",
+			[]string{"chain-1:46656"},
+			"# seeds = \"a:1\"\nmoniker = \"node\"\nseeds = \"chain-1:46656\"\n\n[rpc]\nladdr = \"0.0.0.0:46657\"\n",
+		},
+		{
+			"seeds of a table",
+			"moniker = \"node\"\n\n[p2p]\nseeds = \"a:1\"\n",
+			[]string{"chain-1:46656"},
+			"moniker = \"node\"\nseeds = \"chain-1:46656\"\n\n[p2p]\nseeds = \"a:1\"\n",
+		},
+		{
+			"no trailing newline",
+			"moniker = \"node\"",
+			[]string{"chain-1:46656"},
+			"moniker = \"node\"\nseeds = \"chain-1:46656\"\n",
+		},
+	} {
+		file := filepath.Join(dir, "config.toml")
+		os.Remove(file)
+		if test.config != "" {
+			if err := ioutil.WriteFile(file, []byte(test.config), 0644); err != nil {
+				t.Fatalf("%s: expected config file, got %v", test.name, err)
+			}
+		}
+		if err := setSeeds(file, test.seeds); err != nil {
+			t.Fatalf("%s: expected seeds to be set, got %v", test.name, err)
+		}
+		if out, err := ioutil.ReadFile(file); err != nil || string(out) != test.expected {
+			t.Fatalf("%s: expected %q, got %q (%v)", test.name, test.expected, out, err)
+		}
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/eris-ltd/eris-cli/definitions"
 	"github.com/eris-ltd/eris-cli/loaders"
 	"github.com/eris-ltd/eris-cli/perform"
+	"github.com/eris-ltd/eris-cli/services"
 	"github.com/eris-ltd/eris-cli/util"
 
 	. "github.com/eris-ltd/common/go/common"
@@ -23,7 +24,30 @@ import (
 	"github.com/pborman/uuid"
 )
 
+const (
+	// tendermint RPC port inside the chain container
+	ChainRPCPort = "46657"
+	// tendermint peer port inside the chain container
+	ChainP2PPort = "46656"
+)
+
+// NewChain creates a chain and starts it. If do.Nodes is set, or if the
+// chain definition has a nodes section, the chain is made of that many
+// validator nodes instead (see NewChainNodes).
 func NewChain(do *definitions.Do) error {
+	if do.Nodes == 0 {
+		if chain, err := loaders.LoadChainDefinition(do.Name, false); err == nil && chain.Nodes != nil {
+			do.Nodes = chain.Nodes.Count
+		}
+	}
+	if do.Nodes != 0 {
+		return NewChainNodes(do)
+	}
+
+	return newChain(do)
+}
+
+func newChain(do *definitions.Do) error {
 	dir := filepath.Join(DataContainersPath, do.Name)
 	if util.DoesDirExist(dir) {
 		log.WithField("dir", dir).Debug("Chain data already exists in")
@@ -44,39 +68,33 @@ func InstallChain(do *definitions.Do) error {
 }
 
 func KillChain(do *definitions.Do) error {
-	chain, err := loaders.LoadChainDefinition(do.Name, false)
-	if err != nil {
-		return err
-	}
-
-	if do.Force {
-		do.Timeout = 0 //overrides 10 sec default
-	}
+	return services.StopGroup(do, nil, chainNames(do.Name))
+}
 
-	if util.IsChain(chain.Name, true) {
-		if err := perform.DockerStop(chain.Service, chain.Operations, do.Timeout); err != nil {
-			return err
-		}
-	} else {
-		log.Info("Chain not currently running. Skipping")
-	}
+func StartChain(do *definitions.Do) error {
+	name := do.Name
+	defer func() { do.Name = name }()
 
-	if do.Rm {
-		if err := perform.DockerRemove(chain.Service, chain.Operations, do.RmD, do.Volumes, do.Force); err != nil {
+	for _, chain := range chainNames(name) {
+		do.Name = chain
+		if _, err := startChain(do, false); err != nil {
 			return err
 		}
 	}
-
 	return nil
 }
 
-func StartChain(do *definitions.Do) error {
-	_, err := startChain(do, false)
+func ExecChain(do *definitions.Do) (buf *bytes.Buffer, err error) {
+	if do.Operations.Attach {
+		chain, err := loaders.LoadChainDefinition(do.Name, false)
+		if err != nil {
+			return nil, err
+		}
+		util.Merge(chain.Operations, do.Operations)
 
-	return err
-}
+		return perform.DockerExecAttach(chain.Service, chain.Operations)
+	}
 
-func ExecChain(do *definitions.Do) (buf *bytes.Buffer, err error) {
 	return startChain(do, true)
 }
 
@@ -163,6 +181,7 @@ func startChain(do *definitions.Do, exec bool) (buf *bytes.Buffer, err error) {
 
 		buf, err = perform.DockerExecService(chain.Service, chain.Operations)
 	} else {
+		setChainHealthCheck(chain)
 		err = perform.DockerRunService(chain.Service, chain.Operations)
 	}
 	if err != nil {
@@ -192,12 +211,16 @@ func bootDependencies(chain *definitions.Chain, do *definitions.Do) error {
 				return err
 			}
 
-			// Start corresponding service.
+			// Start corresponding service (or attach it to the chain
+			// networks if it runs already).
+			for _, network := range chain.Service.Networks {
+				util.JoinNetwork(srv.Service, network)
+			}
 			if !util.IsService(srv.Service.Name, true) {
 				log.WithField("=>", do.Name).Info("Dependency not running. Starting now")
-				if err = perform.DockerRunService(srv.Service, srv.Operations); err != nil {
-					return err
-				}
+			}
+			if err = perform.DockerRunService(srv.Service, srv.Operations); err != nil {
+				return err
 			}
 
 		}
@@ -216,6 +239,15 @@ func bootDependencies(chain *definitions.Chain, do *definitions.Do) error {
 	return nil
 }
 
+// when asked to wait for a chain which has no health check of its own,
+// consider it ready once the tendermint RPC port accepts connections
+func setChainHealthCheck(chain *definitions.Chain) {
+	if chain.Operations.Wait && chain.Service.HealthCheck == nil {
+		chain.Service.HealthCheck = definitions.BlankHealthCheck()
+		chain.Service.HealthCheck.Port = ChainRPCPort
+	}
+}
+
 // the main function for setting up a chain container
 // handles both "new" and "fetch" - most of the differentiating logic is in the container
 func setupChain(do *definitions.Do, cmd string) (err error) {
@@ -346,11 +378,13 @@ func setupChain(do *definitions.Do, cmd string) (err error) {
 		log.Debug(err.Error())
 	}
 
-	// write the chain definition file ...
+	// write the chain definition file (nodes use the one of their chain) ...
 	fileName := filepath.Join(ChainsPath, do.Name) + ".toml"
-	if _, err = os.Stat(fileName); err != nil {
-		if err = WriteChainDefinitionFile(chain, fileName); err != nil {
-			return fmt.Errorf("error writing chain definition to file: %v", err)
+	if _, isNode := loaders.ChainNodeOf(do.Name); !isNode {
+		if _, err = os.Stat(fileName); err != nil {
+			if err = WriteChainDefinitionFile(chain, fileName); err != nil {
+				return fmt.Errorf("error writing chain definition to file: %v", err)
+			}
 		}
 	}
 
@@ -359,8 +393,9 @@ func setupChain(do *definitions.Do, cmd string) (err error) {
 		return err
 	}
 	log.WithField("image", chain.Service.Image).Debug("Chain loaded")
-	chain.Operations.PublishAllPorts = do.Operations.PublishAllPorts // TODO: remove this and marshall into struct from cli directly
+	chain.Operations.PublishAllPorts = chain.Operations.PublishAllPorts || do.Operations.PublishAllPorts // TODO: remove this and marshall into struct from cli directly
 	chain.Operations.Ports = do.Operations.Ports
+	chain.Operations.Wait = do.Operations.Wait
 
 	// cmd should be "new" or "install"
 	chain.Service.Command = cmd
@@ -411,13 +446,14 @@ func setupChain(do *definitions.Do, cmd string) (err error) {
 		"image":        chain.Service.Image,
 	}).Debug("Performing chain container start")
 
+	setChainHealthCheck(chain)
 	err = perform.DockerRunService(chain.Service, chain.Operations)
 	// this err is caught in the defer above
 
 	log.Info("Moving priv_validator.json into eris-keys")
 	doKeys := definitions.NowDo()
 	doKeys.Name = do.Name
-	doKeys.Operations.Args = []string{"mintkey", "eris", fmt.Sprintf("%s/chains/%s/priv_validator.json", ErisContainerRoot, do.Name)}
+	doKeys.Operations.Args = []string{"mintkey", "eris", fmt.Sprintf("%s/chains/%s/priv_validator.json", ErisContainerRoot, do.ChainID)}
 	if out, err := ExecChain(doKeys); err != nil {
 		log.Error(out)
 		return fmt.Errorf("Error moving keys: %v", err)
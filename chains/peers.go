@@ -0,0 +1,287 @@
+package chains
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/eris-ltd/eris-cli/chains/edit"
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/util"
+
+	. "github.com/eris-ltd/common/go/common"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// PeersChain lists, adds, and removes the seeds of a chain. The seeds are
+// kept in the chain definition file and written to the config.toml file
+// of every chain node which has a data container. The nodes of a chain
+// made of several validator nodes keep each other as seeds as well.
+//
+//	do.Name            - name of the chain (required)
+//	do.Type            - ls, add, or rm (required)
+//	do.Operations.Args - HOST:PORT address of the seed to add or remove
+//	do.Restart         - restart the running chain nodes to connect to the
+//	                     new seeds
+//	do.Timeout         - seconds to wait for the chain nodes to stop
+//	do.JSON            - machine readable ls output
+func PeersChain(do *definitions.Do) error {
+	switch do.Type {
+	case "ls":
+		return listPeers(do.Name, do.JSON)
+	case "add", "rm":
+		if len(do.Operations.Args) != 1 {
+			return fmt.Errorf("Please give me the HOST:PORT address of the peer to %s", do.Type)
+		}
+	default:
+		return fmt.Errorf("unknown peers subcommand %q", do.Type)
+	}
+
+	if base, ok := loaders.ChainNodeOf(do.Name); ok {
+		return fmt.Errorf("%s is a node of the %s chain. Please change the peers of the chain", do.Name, base)
+	}
+	file := util.GetFileByNameAndType("chains", do.Name)
+	if file == "" {
+		return fmt.Errorf("There is no definition file for the %s chain in %s", do.Name, ChainsPath)
+	}
+
+	seeds, err := definitionSeeds(do.Name)
+	if err != nil {
+		return err
+	}
+	address := do.Operations.Args[0]
+	found := -1
+	for i, seed := range seeds {
+		if seed == address {
+			found = i
+		}
+	}
+	switch {
+	case do.Type == "add" && found >= 0:
+		return fmt.Errorf("%s is a seed of the %s chain already", address, do.Name)
+	case do.Type == "add":
+		if _, port, err := net.SplitHostPort(address); err != nil || port == "" {
+			return fmt.Errorf("Please give me the peer address as HOST:PORT, not %q", address)
+		}
+		seeds = append(seeds, address)
+	case found < 0:
+		return fmt.Errorf("%s is not a seed of the %s chain. See [eris chains peers %s ls]", address, do.Name, do.Name)
+	default:
+		seeds = append(seeds[:found], seeds[found+1:]...)
+	}
+
+	log.WithFields(log.Fields{
+		"=>":    do.Name,
+		"seeds": seeds,
+	}).Info("Writing chain definition seeds")
+	var value interface{}
+	if len(seeds) != 0 {
+		value = seeds
+	}
+	if err := edit.WriteField(file, "seeds", value); err != nil {
+		return err
+	}
+
+	chain, err := loaders.LoadChainDefinition(do.Name, false)
+	if err != nil {
+		return err
+	}
+	for _, node := range chainNames(do.Name) {
+		if !util.IsData(node) && !util.IsDataVolume(node) {
+			log.WithField("=>", node).Info("Chain has no data container, not writing its configuration")
+			continue
+		}
+		joined := strings.Join(configSeeds(chain, node), ",")
+		if err := updateChainConfig(node, func(b []byte) ([]byte, error) {
+			return edit.Set(b, "seeds", edit.Quote(joined), true)
+		}); err != nil {
+			return err
+		}
+		if do.Restart {
+			if err := restartChain(node, do.Timeout); err != nil {
+				return err
+			}
+		} else if util.IsChain(node, true) {
+			log.WithField("=>", node).Warn("The chain connects to the seeds when it starts. Restart it with [eris chains restart] or use --restart")
+		}
+	}
+	return nil
+}
+
+// chainPeer is an [eris chains peers ls] entry.
+type chainPeer struct {
+	Node       string `json:"node"`
+	Address    string `json:"address"`
+	Moniker    string `json:"moniker,omitempty"`
+	Direction  string `json:"direction,omitempty"`
+	Configured bool   `json:"configured"`
+	Connected  *bool  `json:"connected"` // nil if the node cannot tell
+}
+
+// listPeers shows the seeds of every node of the chain name, or of the
+// node name, along with the peers the running nodes are connected to.
+// The seeds of a running node which doesn't answer are shown connected
+// or not as unknown.
+func listPeers(name string, asJSON bool) error {
+	chain, err := loaders.LoadChainDefinition(name, false)
+	if err != nil {
+		return err
+	}
+	nodes := chainNames(name)
+	if chain.NodeOf != "" {
+		nodes = []string{name}
+	}
+
+	peers := []*chainPeer{}
+	for _, node := range nodes {
+		var live []*util.ChainPeer
+		known := true
+		if util.IsChain(node, true) {
+			if live, err = util.GetChainPeers(node); err != nil {
+				log.WithFields(log.Fields{
+					"=>":    node,
+					"error": err,
+				}).Warn("Cannot get the peers of the chain, showing its seeds only")
+				known = false
+			}
+		}
+
+		connected := make(map[*util.ChainPeer]bool)
+		for _, seed := range configSeeds(chain, node) {
+			peer := &chainPeer{Node: node, Address: seed, Configured: true}
+			if known {
+				peer.Connected = boolPtr(false)
+			}
+			var addresses []string
+			if len(live) != 0 {
+				addresses = seedAddresses(seed)
+			}
+			for _, p := range live {
+				if contains(addresses, p.ListenAddr) || contains(addresses, p.RemoteAddr) {
+					peer.Moniker, peer.Direction, peer.Connected = p.Moniker, peerDirection(p), boolPtr(true)
+					connected[p] = true
+				}
+			}
+			peers = append(peers, peer)
+		}
+		for _, p := range live {
+			if !connected[p] {
+				peers = append(peers, &chainPeer{
+					Node:      node,
+					Address:   p.ListenAddr,
+					Moniker:   p.Moniker,
+					Direction: peerDirection(p),
+					Connected: boolPtr(true),
+				})
+			}
+		}
+	}
+
+	w := config.GlobalConfig.Writer
+	if asJSON {
+		return printJSON(w, peers)
+	}
+
+	// 6 - minwidth, 1 - tabwidth (tab characters width), 5 - padding, ' ' - padchar, 0 - flags.
+	tw := tabwriter.NewWriter(w, 6, 1, 5, ' ', 0)
+	fmt.Fprintln(tw, "NODE\tPEER\tMONIKER\tDIRECTION\tCONFIGURED\tCONNECTED")
+	for _, p := range peers {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", p.Node, p.Address, p.Moniker, p.Direction, yesNo(p.Configured), connectedString(p.Connected))
+	}
+	return tw.Flush()
+}
+
+// seedAddresses returns the seed HOST:PORT address along with the
+// IP:PORT addresses the peers report for it: those of the chain container
+// named HOST on its networks (chain nodes take each other as seeds by
+// their names), or those HOST resolves to otherwise.
+func seedAddresses(seed string) []string {
+	addresses := []string{seed}
+	host, port, err := net.SplitHostPort(seed)
+	if err != nil || net.ParseIP(host) != nil {
+		return addresses
+	}
+
+	var ips []string
+	if container, err := util.DockerClient.InspectContainer(util.ChainContainerName(host)); err == nil {
+		for _, network := range container.NetworkSettings.Networks {
+			ips = append(ips, network.IPAddress)
+		}
+	} else if ips, err = net.LookupHost(host); err != nil {
+		log.WithFields(log.Fields{
+			"seed":  seed,
+			"error": err,
+		}).Debug("Cannot resolve the seed address")
+	}
+	for _, ip := range ips {
+		if ip != "" {
+			addresses = append(addresses, net.JoinHostPort(ip, port))
+		}
+	}
+	return addresses
+}
+
+func peerDirection(p *util.ChainPeer) string {
+	if p.IsOutbound {
+		return "outbound"
+	}
+	return "inbound"
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func connectedString(connected *bool) string {
+	if connected == nil {
+		return "unknown"
+	}
+	return yesNo(*connected)
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// configSeeds returns the seeds of the node of the chain: the other
+// nodes of the chain, if it has several, and the seeds of the chain
+// definition.
+func configSeeds(chain *definitions.Chain, node string) []string {
+	var seeds []string
+	if chain.Nodes != nil {
+		base := chain.Name
+		if chain.NodeOf != "" {
+			base = chain.NodeOf
+		}
+		if _, i, ok := util.SplitChainNodeName(node); ok {
+			seeds = nodeSeeds(base, i, chain.Nodes.Count)
+		}
+	}
+	return append(seeds, chain.Seeds...)
+}
+
+// definitionSeeds returns the seeds in the chain definition file as they
+// are written, without the profiles and --set values.
+func definitionSeeds(name string) ([]string, error) {
+	conf, err := config.LoadViperConfig(ChainsPath, name, "chain")
+	if err != nil {
+		return nil, err
+	}
+	return conf.GetStringSlice("seeds"), nil
+}
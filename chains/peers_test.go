@@ -0,0 +1,144 @@
+package chains
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/data"
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/tests"
+	"github.com/eris-ltd/eris-cli/util"
+
+	"github.com/eris-ltd/common/go/common"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestPeersChain(t *testing.T) {
+	server, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	root, done := tests.WithErisRoot(t)
+	defer done()
+
+	const chain = "test-peers"
+	files := map[string]string{
+		"chain/config.toml":         "moniker = \"node\"\nseeds = \"\"\n",
+		"chains/" + chain + ".toml": "# staging\nname = \"" + chain + "\"\nchain_id = \"" + chain + "\"\n\n[service]\nimage = \"quay.io/eris/db\"\n",
+		"chains/default.toml":       "[service]\nimage = \"quay.io/eris/db\"\n",
+	}
+	tests.WriteFiles(t, root, files)
+
+	do := def.NowDo()
+	do.Name = chain
+	do.Source = filepath.Join(root, "chain")
+	do.Destination = common.ErisContainerRoot + "/chains/" + chain
+	if err := data.ImportData(do); err != nil {
+		t.Fatalf("expected import to succeed, got %v", err)
+	}
+
+	for _, change := range []struct {
+		typ   string
+		addr  string
+		fails bool
+	}{
+		{"add", "10.0.0.1:46656", false},
+		{"add", "seed.example.com:46656", false},
+		{"add", "10.0.0.1:46656", true},
+		{"add", "10.0.0.2", true},
+		{"rm", "10.0.0.1:46656", false},
+		{"rm", "10.0.0.3:46656", true},
+	} {
+		do := def.NowDo()
+		do.Name = chain
+		do.Type = change.typ
+		do.Operations.Args = []string{change.addr}
+		if err := PeersChain(do); (err != nil) != change.fails {
+			t.Fatalf("expected peers %s %s to fail %v, got %v", change.typ, change.addr, change.fails, err)
+		}
+	}
+
+	definition, err := ioutil.ReadFile(filepath.Join(common.ChainsPath, chain+".toml"))
+	expected := "# staging\nname = \"" + chain + "\"\nchain_id = \"" + chain + "\"\nseeds = [\"seed.example.com:46656\"]\n\n[service]\nimage = \"quay.io/eris/db\"\n"
+	if err != nil || string(definition) != expected {
+		t.Fatalf("expected definition %q, got %q (%v)", expected, definition, err)
+	}
+
+	content, err := server.ReadFile(util.DataContainerName(chain), common.ErisContainerRoot+"/chains/"+chain+"/config.toml")
+	expected = "moniker = \"node\"\nseeds = \"seed.example.com:46656\"\n"
+	if err != nil || string(content) != expected {
+		t.Fatalf("expected config %q, got %q (%v)", expected, content, err)
+	}
+
+	buf := new(bytes.Buffer)
+	defer func(w io.Writer) { config.GlobalConfig.Writer = w }(config.GlobalConfig.Writer)
+	config.GlobalConfig.Writer = buf
+	do = def.NowDo()
+	do.Name = chain
+	do.Type = "ls"
+	do.JSON = true
+	expected = `[{"node":"` + chain + `","address":"seed.example.com:46656","configured":true,"connected":false}]` + "\n"
+	if err := PeersChain(do); err != nil || buf.String() != expected {
+		t.Fatalf("expected peers %q, got %q (%v)", expected, buf.String(), err)
+	}
+
+	// The running chain answers the status, but not the net_info method.
+	results := map[string]string{
+		"status": `{"node_info": {"network": "` + chain + `"}, "latest_block_height": 1}`,
+	}
+	rpc := tests.ServeChainRPC(t, server, chain, results)
+	defer rpc.Close()
+	buf.Reset()
+	expected = `[{"node":"` + chain + `","address":"seed.example.com:46656","configured":true,"connected":null}]` + "\n"
+	if err := PeersChain(do); err != nil || buf.String() != expected {
+		t.Fatalf("expected peers with unknown status %q, got %q (%v)", expected, buf.String(), err)
+	}
+
+	buf.Reset()
+	do.JSON = false
+	if err := PeersChain(do); err != nil || !strings.Contains(buf.String(), "seed.example.com:46656") || !strings.Contains(buf.String(), "unknown") {
+		t.Fatalf("expected the seed connected unknown, got %q (%v)", buf.String(), err)
+	}
+
+	// Seeds given by host name match the peers connected by IP address:
+	// chain containers by their addresses on their networks, other hosts
+	// by the addresses they resolve to.
+	for _, seed := range []string{"localhost:46656", "test-peers-seed:46656"} {
+		do := def.NowDo()
+		do.Name = chain
+		do.Type = "add"
+		do.Operations.Args = []string{seed}
+		if err := PeersChain(do); err != nil {
+			t.Fatalf("expected seed %s to be added, got %v", seed, err)
+		}
+	}
+	if _, err := util.DockerClient.CreateContainer(docker.CreateContainerOptions{
+		Name:   util.ChainContainerName("test-peers-seed"),
+		Config: &docker.Config{Image: "quay.io/eris/db"},
+	}); err != nil {
+		t.Fatalf("expected seed container to be created, got %v", err)
+	}
+	seed, err := util.DockerClient.InspectContainer(util.ChainContainerName("test-peers-seed"))
+	if err != nil || seed.NetworkSettings.IPAddress == "" {
+		t.Fatalf("expected seed container address, got %v (%v)", seed, err)
+	}
+	results["net_info"] = `{"peers": [
+		{"node_info": {"moniker": "seed", "listen_addr": "` + seed.NetworkSettings.IPAddress + `:46656"}, "is_outbound": true},
+		{"node_info": {"moniker": "local", "listen_addr": "127.0.0.1:46656"}, "is_outbound": true},
+		{"node_info": {"moniker": "other", "listen_addr": "10.0.0.9:46656"}}
+	]}`
+
+	buf.Reset()
+	do.JSON = true
+	expected = `[{"node":"` + chain + `","address":"seed.example.com:46656","configured":true,"connected":false},` +
+		`{"node":"` + chain + `","address":"localhost:46656","moniker":"local","direction":"outbound","configured":true,"connected":true},` +
+		`{"node":"` + chain + `","address":"test-peers-seed:46656","moniker":"seed","direction":"outbound","configured":true,"connected":true},` +
+		`{"node":"` + chain + `","address":"10.0.0.9:46656","moniker":"other","direction":"inbound","configured":false,"connected":true}]` + "\n"
+	if err := PeersChain(do); err != nil || buf.String() != expected {
+		t.Fatalf("expected connected seeds %q, got %q (%v)", expected, buf.String(), err)
+	}
+}
@@ -0,0 +1,111 @@
+package chains
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/util"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// How often [eris chains status --watch] refreshes.
+var statusInterval = 2 * time.Second
+
+// StatusChain writes the latest block height, hash, and time, the number
+// of validators and peers, the sync state, and the node version of chains
+// to the configured writer (see util.GetChainStatus), as a table or, if
+// do.JSON is set, as a JSON document. A chain made of several validator
+// nodes is shown node by node.
+//
+//  do.Operations.Args - chain names (defaults to all running chains)
+//  do.JSON            - machine readable output
+//  do.Watch           - refresh the output every couple of seconds until
+//                       interrupted
+//
+func StatusChain(do *definitions.Do) error {
+	var names []string
+	for _, name := range do.Operations.Args {
+		names = append(names, chainNames(name)...)
+	}
+	if len(do.Operations.Args) == 0 {
+		for _, chain := range util.ErisContainersByType(definitions.TypeChain, true) {
+			names = append(names, chain.ShortName)
+		}
+	}
+
+	for {
+		statuses := util.GetChainStatuses(names)
+		if err := printChainStatuses(config.GlobalConfig.Writer, statuses, do.JSON); err != nil {
+			return err
+		}
+		if !do.Watch {
+			return nil
+		}
+
+		time.Sleep(statusInterval)
+		if !do.JSON {
+			fmt.Fprintln(config.GlobalConfig.Writer)
+		}
+	}
+}
+
+func printChainStatuses(w io.Writer, statuses []*util.ChainStatus, asJSON bool) error {
+	if asJSON {
+		if statuses == nil {
+			statuses = []*util.ChainStatus{}
+		}
+		b, err := json.Marshal(statuses)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+
+	// 6 - minwidth, 1 - tabwidth (tab characters width), 5 - padding, ' ' - padchar, 0 - flags.
+	tw := tabwriter.NewWriter(w, 6, 1, 5, ' ', 0)
+	fmt.Fprintln(tw, "CHAIN\tSTATE\tHEIGHT\tBLOCK HASH\tBLOCK TIME\tVALIDATORS\tPEERS\tVERSION")
+	for _, s := range statuses {
+		state := "synced"
+		switch {
+		case !s.Running:
+			state = "stopped"
+		case s.Error != "":
+			state = "unreachable"
+			log.WithField("=>", s.Name).Warnf("Cannot query the chain: %v", s.Error)
+		case s.CatchingUp:
+			state = "catching up"
+		}
+
+		hash := s.BlockHash
+		if len(hash) > 10 {
+			hash = hash[:10]
+		}
+		blockTime := ""
+		if s.BlockTime != nil {
+			blockTime = s.BlockTime.Local().Format("2006-01-02 15:04:05")
+		}
+
+		if !s.Running || s.Error != "" {
+			fmt.Fprintf(tw, "%s\t%s\t\t\t\t\t\t\n", s.Name, state)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%d\t%d\t%s\n",
+			s.Name,
+			state,
+			s.Height,
+			hash,
+			blockTime,
+			s.Validators,
+			s.Peers,
+			s.Version,
+		)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,42 @@
+package chains
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/eris-ltd/eris-cli/tests"
+	"github.com/eris-ltd/eris-cli/util"
+)
+
+func TestStatusChainRunning(t *testing.T) {
+	server, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	const chain = "test-status"
+	blockTime := time.Date(2016, 9, 1, 12, 0, 0, 0, time.UTC)
+	rpc := tests.ServeChainRPC(t, server, chain, map[string]string{
+		"status":     `{"node_info": {"network": "` + chain + `", "version": "0.5.0"}, "latest_block_hash": "A0B1C2D3", "latest_block_height": 42, "latest_block_time": ` + strconv.FormatInt(blockTime.UnixNano(), 10) + `}`,
+		"validators": `{"block_height": 42, "bonded_validators": [{"address": "6D1A4E9FA2AD3F8B3E0A37E14D3F08D2AFE7A34B", "voting_power": 10}]}`,
+		"net_info":   `{"listening": true, "peers": [{"node_info": {"moniker": "seed", "listen_addr": "10.0.0.1:46656"}, "is_outbound": true}]}`,
+	})
+	defer rpc.Close()
+
+	status := util.GetChainStatus(chain)
+	if !status.Running || status.Error != "" || status.ChainID != chain || status.Height != 42 || status.BlockHash != "A0B1C2D3" {
+		t.Fatalf("expected the status from the chain RPC, got %+v", status)
+	}
+	if status.BlockTime == nil || !status.BlockTime.Equal(blockTime) {
+		t.Fatalf("expected block time %v, got %v", blockTime, status.BlockTime)
+	}
+	if status.Validators != 1 || status.Peers != 1 || status.Version != "0.5.0" || !status.CatchingUp {
+		t.Fatalf("expected one validator and an old block with a peer, got %+v", status)
+	}
+
+	if err := util.DockerClient.StopContainer(util.ChainContainerName(chain), 10); err != nil {
+		t.Fatalf("expected chain to stop, got %v", err)
+	}
+	if status := util.GetChainStatus(chain); status.Running || status.Error != "" {
+		t.Fatalf("expected the chain stopped, got %+v", status)
+	}
+}
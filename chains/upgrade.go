@@ -0,0 +1,210 @@
+package chains
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/eris-ltd/eris-cli/chains/edit"
+	"github.com/eris-ltd/eris-cli/data"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/perform"
+	"github.com/eris-ltd/eris-cli/util"
+
+	log "github.com/Sirupsen/logrus"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+var (
+	// How long an upgraded chain has to produce a block.
+	upgradeWait = time.Minute
+	// How often the upgraded chain height is checked.
+	upgradePoll = time.Second
+)
+
+// UpgradeChain moves a chain to another image without losing it if the
+// image doesn't work. For the chain, or for each of its nodes in turn if
+// it is made of several validator nodes, it
+//
+//  1. records the image and the configuration of the chain container;
+//  2. stops the chain and takes a data snapshot tagged pre-upgrade-TIME;
+//  3. recreates the container from the chain definition with do.Image
+//     and starts it;
+//  4. waits for the chain to produce a block, as reported by its RPC.
+//
+// If the chain doesn't produce a block in time, the new container is
+// replaced with the recorded one, the data is restored from the snapshot,
+// the chain is started again, and the failure is returned. The nodes
+// after a failed one are not upgraded. A chain which is not running is
+// upgraded without being started. Once every node is upgraded, do.Image
+// is written to the chain definition file as its service image, so that
+// the chain keeps it when it is recreated.
+//
+//  do.Name    - name of the chain (required)
+//  do.Image   - image to upgrade to (required)
+//  do.Pull    - pull the image first
+//  do.Timeout - seconds to wait for the chain to stop
+//
+func UpgradeChain(do *definitions.Do) error {
+	if do.Image == "" {
+		return fmt.Errorf("Please give me the image to upgrade the chain to")
+	}
+
+	if do.Pull {
+		log.WithField("image", do.Image).Warn("Pulling image")
+		if err := util.PullImage(do.Image, ioutil.Discard); err != nil {
+			return err
+		}
+	}
+
+	var upgraded []string
+	for _, name := range chainNames(do.Name) {
+		if err := upgradeChain(name, do.Image, do.Timeout); err != nil {
+			if len(upgraded) != 0 {
+				log.WithField("nodes", upgraded).Warn("These nodes are upgraded")
+			}
+			return err
+		}
+		upgraded = append(upgraded, name)
+	}
+
+	if err := writeUpgradedImage(do.Name, do.Image); err != nil {
+		return err
+	}
+
+	do.Result = "success"
+	return nil
+}
+
+// writeUpgradedImage sets the service image of the chain definition
+// file to image. A node of a chain made of several validator nodes has
+// no definition file of its own; it is left to the chain definition.
+func writeUpgradedImage(name, image string) error {
+	if base, ok := loaders.ChainNodeOf(name); ok {
+		log.WithField("=>", name).Warnf("The %s chain definition is left with its image. Upgrade the chain for the nodes to keep the new one", base)
+		return nil
+	}
+	file := util.GetFileByNameAndType("chains", name)
+	if file == "" {
+		log.WithField("=>", name).Warn("The chain has no definition file to write the new image to")
+		return nil
+	}
+
+	log.WithFields(log.Fields{
+		"=>":    name,
+		"image": image,
+	}).Info("Writing chain definition image")
+	if err := edit.WriteField(file, "service.image", image); err != nil {
+		return fmt.Errorf("The %s chain is upgraded to %s, but its definition file is not: %v", name, image, err)
+	}
+	return nil
+}
+
+func upgradeChain(name, image string, timeout uint) error {
+	chain, err := loaders.LoadChainDefinition(name, false)
+	if err != nil {
+		return err
+	}
+	if !util.IsChain(name, false) {
+		return fmt.Errorf("The %s chain has no container to upgrade. Start it with [eris chains start %s]", name, name)
+	}
+
+	previous, err := util.DockerClient.InspectContainer(chain.Operations.SrvContainerName)
+	if err != nil {
+		return util.DockerError(err)
+	}
+	if previous.Config.Image == image {
+		log.WithFields(log.Fields{
+			"=>":    name,
+			"image": image,
+		}).Warn("Chain already runs the image")
+		return nil
+	}
+	running := previous.State.Running
+
+	height := 0
+	if running {
+		if height, err = util.ChainHeight(name); err != nil {
+			log.WithField("=>", name).Debugf("Cannot get chain height: %v", err)
+		}
+		if err := perform.DockerStop(chain.Service, chain.Operations, timeout); err != nil {
+			return err
+		}
+	}
+
+	doSnapshot := definitions.NowDo()
+	doSnapshot.Name = name
+	doSnapshot.Tag = "pre-upgrade-" + time.Now().UTC().Format("20060102T150405")
+	if err := data.SnapshotData(doSnapshot); err != nil {
+		if running {
+			util.DockerClient.StartContainer(previous.ID, nil)
+		}
+		return fmt.Errorf("Cannot take a data snapshot before the upgrade, the chain is left as it was: %v", err)
+	}
+
+	log.WithFields(log.Fields{
+		"=>":       name,
+		"from":     previous.Config.Image,
+		"to":       image,
+		"snapshot": doSnapshot.Tag,
+	}).Warn("Upgrading chain")
+	chain.Service.Image = image
+	chain.Service.Command = loaders.ErisChainStart
+	// The data just snapshotted is mounted whatever the definition says.
+	chain.Service.AutoData = true
+	chain.Service.Environment = append(chain.Service.Environment, "CHAIN_ID="+chain.ChainID)
+	err = perform.DockerRecreate(chain.Service, chain.Operations, running)
+	if err == nil && running {
+		err = waitForBlocks(name, height)
+	}
+	if err == nil {
+		return nil
+	}
+
+	log.WithField("=>", name).Errorf("Upgrade failed, rolling back to %s: %v", previous.Config.Image, err)
+	if err2 := rollbackChain(name, previous, chain, doSnapshot.Tag, running); err2 != nil {
+		return fmt.Errorf("The %s chain upgrade to %s failed (%v) and so did the rollback: %v. Restore the %s data snapshot by hand", name, image, err, err2, doSnapshot.Tag)
+	}
+	return fmt.Errorf("The %s chain upgrade to %s failed and was rolled back to %s: %v", name, image, previous.Config.Image, err)
+}
+
+// waitForBlocks waits for the running chain to get past height, polling
+// the chain RPC (see util.ChainHeight).
+func waitForBlocks(name string, height int) error {
+	deadline := time.Now().Add(upgradeWait)
+	for time.Now().Before(deadline) {
+		if !util.IsChain(name, true) {
+			return fmt.Errorf("the chain container exited")
+		}
+		if h, err := util.ChainHeight(name); err == nil && h > height {
+			log.WithFields(log.Fields{
+				"=>":     name,
+				"height": h,
+			}).Info("Chain produces blocks")
+			return nil
+		}
+		time.Sleep(upgradePoll)
+	}
+	return fmt.Errorf("no new block past height %d in %v", height, upgradeWait)
+}
+
+// rollbackChain recreates the previous chain container and restores the
+// chain data from the snapshot taken before the upgrade.
+func rollbackChain(name string, previous *docker.Container, chain *definitions.Chain, snapshot string, start bool) error {
+	if err := perform.DockerRollback(previous, chain.Service, false); err != nil {
+		return err
+	}
+
+	doRestore := definitions.NowDo()
+	doRestore.Name = name
+	doRestore.Tag = snapshot
+	if err := data.RestoreData(doRestore); err != nil {
+		return err
+	}
+
+	if !start {
+		return nil
+	}
+	return util.DockerError(util.DockerClient.StartContainer(chain.Operations.SrvContainerName, nil))
+}
@@ -0,0 +1,85 @@
+package chains
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/eris-ltd/eris-cli/data"
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/perform"
+	"github.com/eris-ltd/eris-cli/tests"
+	"github.com/eris-ltd/eris-cli/util"
+)
+
+func TestUpgradeChain(t *testing.T) {
+	server, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	root, done := tests.WithErisRoot(t)
+	defer done()
+
+	// The chain produces a block every time its height is asked for.
+	var height int32
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": "", "result": [1, {"latest_block_height": %d}], "error": ""}`, atomic.AddInt32(&height, 1))
+	}))
+	defer rpc.Close()
+	_, port, _ := net.SplitHostPort(rpc.Listener.Addr().String())
+
+	const chain = "test-upgrade"
+	tests.WriteFiles(t, root, map[string]string{
+		"chains/" + chain + ".toml": "name = \"" + chain + "\"\nchain_id = \"" + chain + "\"\n\n[service]\nimage = \"quay.io/eris/db\"\ndata_container = true\nports = [\"127.0.0.1:" + port + ":46657\"]\n",
+		"chains/default.toml":       "[service]\nimage = \"quay.io/eris/db\"\n",
+	})
+	server.AddImage("quay.io/eris/db")
+	server.AddImage("quay.io/eris/db:next")
+
+	// The running container has settings of its own, which are not
+	// carried over to the upgraded one.
+	previous, err := loaders.LoadChainDefinition(chain, false)
+	if err != nil {
+		t.Fatalf("expected chain definition, got %v", err)
+	}
+	previous.Service.Command = loaders.ErisChainStart
+	previous.Service.Environment = []string{"CHAIN_ID=" + chain, "ERISDB_API=true"}
+	if err := perform.DockerRunService(previous.Service, previous.Operations); err != nil {
+		t.Fatalf("expected chain to start, got %v", err)
+	}
+
+	do := def.NowDo()
+	do.Name = chain
+	do.Image = "quay.io/eris/db:next"
+	if err := UpdateChain(do); err != nil {
+		t.Fatalf("expected upgrade to succeed, got %v", err)
+	}
+
+	upgraded, err := util.DockerClient.InspectContainer(util.ChainContainerName(chain))
+	if err != nil || !upgraded.State.Running || upgraded.Config.Image != do.Image {
+		t.Fatalf("expected chain running %s, got %+v (%v)", do.Image, upgraded, err)
+	}
+	if env := strings.Join(upgraded.Config.Env, " "); env != "CHAIN_ID="+chain {
+		t.Fatalf("expected the environment of the chain definition, got %q", env)
+	}
+	if !reflect.DeepEqual(upgraded.HostConfig.VolumesFrom, []string{util.DataContainerName(chain)}) {
+		t.Fatalf("expected the chain data container mounted, got %v", upgraded.HostConfig.VolumesFrom)
+	}
+	if snapshots, err := data.ListSnapshots(chain); err != nil || len(snapshots) != 1 || !strings.HasPrefix(snapshots[0].ID, "pre-upgrade-") {
+		t.Fatalf("expected the pre-upgrade snapshot, got %v (%v)", snapshots, err)
+	}
+
+	// The chain keeps the image when it's recreated from its definition.
+	definition, err := ioutil.ReadFile(filepath.Join(root, "chains", chain+".toml"))
+	expected := "name = \"" + chain + "\"\nchain_id = \"" + chain + "\"\n\n[service]\nimage = \"quay.io/eris/db:next\"\ndata_container = true\nports = [\"127.0.0.1:" + port + ":46657\"]\n"
+	if err != nil || string(definition) != expected {
+		t.Fatalf("expected definition %q, got %q (%v)", expected, definition, err)
+	}
+}
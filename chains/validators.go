@@ -0,0 +1,362 @@
+package chains
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/genesis"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/services"
+	"github.com/eris-ltd/eris-cli/util"
+
+	. "github.com/eris-ltd/common/go/common"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// ValidatorsChain lists, adds, and removes the validators of a chain.
+//
+// For a chain which has not started yet (it has no data), the genesis
+// files in the ~/.eris/chains/NAME directory and its subdirectories are
+// changed. The validator key is taken from the keys service (and
+// generated there if no key is given), or the public key of a validator
+// running elsewhere is given as is.
+//
+// For a running chain, bond and unbond transactions are signed by the
+// keys service and sent from the chain container, and the validators are
+// read from the chain RPC. A chain which has started but is not running
+// can only be listed, from its genesis file.
+//
+//  do.Name            - name of the chain (required)
+//  do.Type            - ls, add, or rm (required)
+//  do.Operations.Args - validator to remove: its public key, name, or the
+//                       address it unbonds to (for a running chain, its
+//                       public key or address)
+//  do.Address         - address of the validator key in the keys service
+//                       (required to bond on a running chain)
+//  do.Pubkey          - public key of a validator whose key is not in the
+//                       keys service (chains which have not started only)
+//  do.Amount          - amount to bond (required for add)
+//  do.UnbondTo        - address the bond goes to when unbonding (defaults
+//                       to the validator address)
+//  do.JSON            - machine readable ls output
+//
+func ValidatorsChain(do *definitions.Do) error {
+	nodes := chainNames(do.Name)
+	started := false
+	for _, node := range nodes {
+		if util.IsData(node) || util.IsDataVolume(node) {
+			started = true
+		}
+	}
+	running := util.IsChain(nodes[0], true)
+
+	switch do.Type {
+	case "ls":
+		if running {
+			return listRunningValidators(nodes[0], do.JSON)
+		}
+		if started {
+			log.WithField("=>", do.Name).Warn("The chain is not running, listing the genesis validators")
+		}
+		return listGenesisValidators(do.Name, do.JSON)
+	case "add":
+		if do.Amount <= 0 {
+			return fmt.Errorf("Please give me the amount to bond with --amount")
+		}
+	case "rm":
+		if len(do.Operations.Args) != 1 {
+			return fmt.Errorf("Please give me the validator to remove")
+		}
+	default:
+		return fmt.Errorf("unknown validators subcommand %q", do.Type)
+	}
+
+	if started && !running {
+		return fmt.Errorf("The %s chain has started, so its validators are bonded and unbonded by transactions. Start it with [eris chains start %s] first", do.Name, do.Name)
+	}
+
+	switch {
+	case do.Type == "add" && running:
+		return bondValidator(nodes[0], do)
+	case do.Type == "add":
+		return addGenesisValidator(do)
+	case running:
+		return unbondValidator(nodes[0], do.Operations.Args[0])
+	default:
+		return updateGenesisFiles(do.Name, func(g *genesis.Genesis) error {
+			removed, err := g.RemoveValidator(do.Operations.Args[0])
+			if err == nil {
+				log.WithField("pub key", removed.PubKey).Warn("Removing validator")
+			}
+			return err
+		})
+	}
+}
+
+func addGenesisValidator(do *definitions.Do) error {
+	address, pubKey := do.Address, do.Pubkey
+	if pubKey == "" {
+		var err error
+		if address, pubKey, err = validatorKey(address); err != nil {
+			return err
+		}
+	}
+	unbondTo := do.UnbondTo
+	if unbondTo == "" {
+		unbondTo = address
+	}
+	if unbondTo == "" {
+		return fmt.Errorf("Please give me the address to unbond the validator to with --unbond-to")
+	}
+
+	validator := genesis.NewValidator(pubKey, int64(do.Amount), unbondTo)
+	log.WithFields(log.Fields{
+		"pub key":   validator.PubKey,
+		"amount":    do.Amount,
+		"unbond to": unbondTo,
+	}).Warn("Adding validator")
+	return updateGenesisFiles(do.Name, func(g *genesis.Genesis) error {
+		return g.AddValidator(validator)
+	})
+}
+
+// validatorKey returns the address and the public key of the address key
+// in the keys service, which is generated if address is empty.
+func validatorKey(address string) (string, string, error) {
+	if err := checkKeysRunningOrStart(); err != nil {
+		return "", "", err
+	}
+
+	if address == "" {
+		buf, err := services.ExecHandler("keys", []string{"eris-keys", "gen", "--type", "ed25519,ripemd160", "--no-pass"})
+		if err != nil {
+			return "", "", fmt.Errorf("Cannot generate a validator key: %v", err)
+		}
+		address = strings.TrimSpace(buf.String())
+		log.WithField("address", address).Warn("Generated validator key")
+	}
+
+	buf, err := services.ExecHandler("keys", []string{"eris-keys", "pub", "--addr", address})
+	if err != nil {
+		return "", "", fmt.Errorf("Cannot get the public key of %s from the keys service: %v", address, err)
+	}
+	return strings.ToUpper(address), strings.ToUpper(strings.TrimSpace(buf.String())), nil
+}
+
+// updateGenesisFiles applies change to the genesis files of a chain which
+// has not started and writes them back. None is written unless all of
+// them are still valid after the change.
+func updateGenesisFiles(name string, change func(*genesis.Genesis) error) error {
+	files, err := chainGenesisFiles(name)
+	if err != nil {
+		return err
+	}
+
+	contents := make([][]byte, len(files))
+	for i, file := range files {
+		g, err := genesis.Load(file)
+		if err != nil {
+			return err
+		}
+		if err := change(g); err != nil {
+			return fmt.Errorf("%s: %v", file, err)
+		}
+		if contents[i], err = g.Marshal(); err != nil {
+			return err
+		}
+		if errs := genesis.Validate(contents[i]); len(errs) != 0 {
+			return fmt.Errorf("%s would not be valid: %v", file, errs[0])
+		}
+	}
+
+	for i, file := range files {
+		log.WithField("file", file).Info("Writing genesis file")
+		if err := ioutil.WriteFile(file, contents[i], 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chainGenesisFiles returns the genesis files in the ~/.eris/chains/NAME
+// directory and in its subdirectories.
+func chainGenesisFiles(name string) ([]string, error) {
+	if base, ok := loaders.ChainNodeOf(name); ok {
+		return nil, fmt.Errorf("%s is a node of the %s chain. Please change the validators of the chain", name, base)
+	}
+
+	dir := filepath.Join(ChainsPath, name)
+	var files []string
+	if _, err := os.Stat(filepath.Join(dir, "genesis.json")); err == nil {
+		files = append(files, filepath.Join(dir, "genesis.json"))
+	}
+	more, _ := filepath.Glob(filepath.Join(dir, "*", "genesis.json"))
+	files = append(files, more...)
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("There are no genesis files for the %s chain in %s. Make them with [eris chains make %s]", name, dir, name)
+	}
+	return files, nil
+}
+
+func listGenesisValidators(name string, asJSON bool) error {
+	file, err := GenesisFile(name)
+	if err != nil {
+		return err
+	}
+	g, err := genesis.Load(file)
+	if err != nil {
+		return err
+	}
+
+	w := config.GlobalConfig.Writer
+	if asJSON {
+		return printJSON(w, g.Validators)
+	}
+
+	// 6 - minwidth, 1 - tabwidth (tab characters width), 5 - padding, ' ' - padchar, 0 - flags.
+	tw := tabwriter.NewWriter(w, 6, 1, 5, ' ', 0)
+	fmt.Fprintln(tw, "PUB KEY\tAMOUNT\tNAME\tUNBOND TO")
+	for _, validator := range g.Validators {
+		var unbond []string
+		for _, u := range validator.UnbondTo {
+			unbond = append(unbond, u.Address)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", validator.PubKey, validator.Amount, validator.Name, strings.Join(unbond, ","))
+	}
+	return tw.Flush()
+}
+
+func listRunningValidators(name string, asJSON bool) error {
+	validators, err := util.GetChainValidators(name)
+	if err != nil {
+		return fmt.Errorf("Cannot get the validators of the %s chain: %v", name, err)
+	}
+
+	w := config.GlobalConfig.Writer
+	if asJSON {
+		if validators == nil {
+			validators = []*util.ChainValidator{}
+		}
+		return printJSON(w, validators)
+	}
+
+	// 6 - minwidth, 1 - tabwidth (tab characters width), 5 - padding, ' ' - padchar, 0 - flags.
+	tw := tabwriter.NewWriter(w, 6, 1, 5, ' ', 0)
+	fmt.Fprintln(tw, "ADDRESS\tPUB KEY\tVOTING POWER\tBOND HEIGHT\tLAST COMMIT")
+	for _, v := range validators {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\n", v.Address, v.PubKey, v.VotingPower, v.BondHeight, v.LastCommitHeight)
+	}
+	return tw.Flush()
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+// bondValidator sends a bond transaction from the running chain node. The
+// validator key pays the bond, so it has to have the funds.
+func bondValidator(name string, do *definitions.Do) error {
+	if do.Address == "" {
+		return fmt.Errorf("The validator key signs the bond transaction. Please give me its address in the keys service with --address")
+	}
+	address, pubKey, err := validatorKey(do.Address)
+	if err != nil {
+		return err
+	}
+	unbondTo := do.UnbondTo
+	if unbondTo == "" {
+		unbondTo = address
+	}
+
+	log.WithFields(log.Fields{
+		"=>":        name,
+		"address":   address,
+		"amount":    do.Amount,
+		"unbond to": unbondTo,
+	}).Warn("Bonding validator")
+	return sendChainTx(name, "bond",
+		"--addr", address,
+		"--pubkey", pubKey,
+		"--amt", strconv.FormatUint(do.Amount, 10),
+		"--unbond-to", unbondTo,
+	)
+}
+
+// unbondValidator sends an unbond transaction for the validator with the
+// id public key or address from the running chain node.
+func unbondValidator(name, id string) error {
+	validators, err := util.GetChainValidators(name)
+	if err != nil {
+		return fmt.Errorf("Cannot get the validators of the %s chain: %v", name, err)
+	}
+	address := ""
+	for _, v := range validators {
+		if strings.EqualFold(v.Address, id) || strings.EqualFold(v.PubKey.Data, id) {
+			address = v.Address
+		}
+	}
+	if address == "" {
+		return fmt.Errorf("There is no %s validator bonded on the %s chain. See [eris chains validators %s ls]", id, name, name)
+	}
+
+	height, err := util.ChainHeight(name)
+	if err != nil {
+		return err
+	}
+
+	if err := checkKeysRunningOrStart(); err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{
+		"=>":      name,
+		"address": address,
+		"height":  height,
+	}).Warn("Unbonding validator")
+	return sendChainTx(name, "unbond",
+		"--addr", address,
+		"--height", strconv.Itoa(height),
+	)
+}
+
+// sendChainTx signs a mintx transaction with the keys service and
+// broadcasts it from the running chain node.
+func sendChainTx(name, tx string, args ...string) error {
+	chainID := name
+	if chain, err := loaders.LoadChainDefinition(name, false); err == nil && chain.ChainID != "" {
+		chainID = chain.ChainID
+	}
+
+	do := definitions.NowDo()
+	do.Name = name
+	// [pv]: can't have 0.0.0.0 on OSX or Windows.
+	do.Operations.Args = append([]string{"mintx", tx,
+		"--node-addr", "http://0.0.0.0:46657",
+		"--sign-addr", "http://keys:4767",
+		"--chainID", chainID,
+	}, args...)
+	do.Operations.Args = append(do.Operations.Args, "--sign", "--broadcast")
+	do.Operations.PublishAllPorts = true
+	log.WithField("args", do.Operations.Args).Debug("Executing command")
+
+	buf, err := ExecChain(do)
+	if buf != nil {
+		io.Copy(config.GlobalConfig.Writer, buf)
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+package chains
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eris-ltd/eris-cli/config"
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/genesis"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/perform"
+	"github.com/eris-ltd/eris-cli/tests"
+	"github.com/eris-ltd/eris-cli/util"
+)
+
+func TestValidatorsChain(t *testing.T) {
+	_, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	root, done := tests.WithErisRoot(t)
+	defer done()
+
+	const (
+		chain   = "test-validators"
+		address = "6D1A4E9FA2AD3F8B3E0A37E14D3F08D2AFE7A34B"
+		pubKey1 = "F6C79CF0CB9D66B677988BCB9B8EADD9A091CD465A60542A8AB85476256DBA92"
+		pubKey2 = "0A1B2C3D4E5F60718293A4B5C6D7E8F90A1B2C3D4E5F60718293A4B5C6D7E8F9"
+		other   = "A0F9E1C2D3B4A5968778695A4B3C2D1E0F1A2B3C"
+	)
+	genesisFile := `{"chain_id": "` + chain + `", "accounts": [], "validators": [{"pub_key": [1, "` + pubKey1 + `"], "amount": 10, "unbond_to": [{"address": "` + address + `", "amount": 10}]}]}`
+	files := map[string]string{
+		"chains/" + chain + "/genesis.json":                             genesisFile,
+		"chains/" + chain + "/" + chain + "_validator_000/genesis.json": genesisFile,
+		"chains/" + chain + ".toml":                                     "name = \"" + chain + "\"\n\n[service]\nimage = \"quay.io/eris/db\"\n",
+		"chains/default.toml":                                           "[service]\nimage = \"quay.io/eris/db\"\n",
+	}
+	tests.WriteFiles(t, root, files)
+
+	validators := func(do *def.Do) error {
+		do.Name = chain
+		return ValidatorsChain(do)
+	}
+
+	do := def.NowDo()
+	do.Type = "add"
+	do.Pubkey = pubKey2
+	do.Amount = 5
+	if err := validators(do); err == nil {
+		t.Fatalf("expected add without an unbond address to fail")
+	}
+	do.UnbondTo = other
+	if err := validators(do); err != nil {
+		t.Fatalf("expected add to succeed, got %v", err)
+	}
+	if err := validators(do); err == nil {
+		t.Fatalf("expected adding the validator twice to fail")
+	}
+
+	do = def.NowDo()
+	do.Type = "rm"
+	do.Operations.Args = []string{address}
+	if err := validators(do); err != nil {
+		t.Fatalf("expected rm to succeed, got %v", err)
+	}
+
+	for _, file := range []string{"chains/" + chain + "/genesis.json", "chains/" + chain + "/" + chain + "_validator_000/genesis.json"} {
+		g, err := genesis.Load(filepath.Join(root, file))
+		if err != nil {
+			t.Fatalf("expected genesis file, got %v", err)
+		}
+		if len(g.Validators) != 1 || g.Validators[0].PubKey.Data != pubKey2 || g.Validators[0].UnbondTo[0].Address != other {
+			t.Fatalf("expected %s to have the added validator only, got %+v", file, g.Validators)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	defer func(w io.Writer) { config.GlobalConfig.Writer = w }(config.GlobalConfig.Writer)
+	config.GlobalConfig.Writer = buf
+	do = def.NowDo()
+	do.Type = "ls"
+	do.JSON = true
+	if err := validators(do); err != nil || !strings.Contains(buf.String(), pubKey2) {
+		t.Fatalf("expected the validator listed, got %q (%v)", buf.String(), err)
+	}
+
+	// Once the chain has data, the genesis is not changed anymore.
+	if err := perform.DockerCreateData(loaders.LoadDataDefinition(chain)); err != nil {
+		t.Fatalf("expected data container, got %v", err)
+	}
+	do = def.NowDo()
+	do.Type = "rm"
+	do.Operations.Args = []string{pubKey2}
+	if err := validators(do); err == nil {
+		t.Fatalf("expected rm on a started chain to fail")
+	}
+}
+
+func TestValidatorsChainRunning(t *testing.T) {
+	server, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	root, done := tests.WithErisRoot(t)
+	defer done()
+
+	const (
+		chain   = "test-running"
+		address = "6D1A4E9FA2AD3F8B3E0A37E14D3F08D2AFE7A34B"
+		pubKey  = "F6C79CF0CB9D66B677988BCB9B8EADD9A091CD465A60542A8AB85476256DBA92"
+	)
+	tests.WriteFiles(t, root, map[string]string{
+		"chains/" + chain + ".toml": "name = \"" + chain + "\"\n\n[service]\nimage = \"quay.io/eris/db\"\n",
+		"chains/default.toml":       "[service]\nimage = \"quay.io/eris/db\"\n",
+	})
+	rpc := tests.ServeChainRPC(t, server, chain, map[string]string{
+		"validators": `{"block_height": 42, "bonded_validators": [{"address": "` + address + `", "pub_key": [1, "` + pubKey + `"], "voting_power": 10, "bond_height": 1, "last_commit_height": 41}]}`,
+	})
+	defer rpc.Close()
+
+	buf := new(bytes.Buffer)
+	defer func(w io.Writer) { config.GlobalConfig.Writer = w }(config.GlobalConfig.Writer)
+	config.GlobalConfig.Writer = buf
+	do := def.NowDo()
+	do.Name = chain
+	do.Type = "ls"
+	do.JSON = true
+	if err := ValidatorsChain(do); err != nil {
+		t.Fatalf("expected ls to succeed, got %v", err)
+	}
+
+	var validators []*util.ChainValidator
+	if err := json.Unmarshal(buf.Bytes(), &validators); err != nil {
+		t.Fatalf("expected JSON output, got %v (%s)", err, buf)
+	}
+	if len(validators) != 1 || validators[0].Address != address || validators[0].PubKey.Data != pubKey || validators[0].VotingPower != 10 || validators[0].LastCommitHeight != 41 {
+		t.Fatalf("expected the validator bonded on the chain, got %+v", validators)
+	}
+}
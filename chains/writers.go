@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/eris-ltd/eris-cli/chains/edit"
 	def "github.com/eris-ltd/eris-cli/definitions"
 	srv "github.com/eris-ltd/eris-cli/services"
 
@@ -54,6 +55,13 @@ func WriteChainDefinitionFile(chainDef *def.Chain, fileName string) error {
 		enc.Indent = ""
 		writer.Write([]byte("name = \"" + chainDef.Name + "\"\n"))
 		writer.Write([]byte("chain_id = \"" + chainDef.ChainID + "\"\n"))
+		if len(chainDef.Seeds) != 0 {
+			writer.Write([]byte("seeds = " + edit.StringList(chainDef.Seeds) + "\n"))
+		}
+		if chainDef.Nodes != nil {
+			writer.Write([]byte("\n[nodes]\n"))
+			enc.Encode(chainDef.Nodes)
+		}
 		writer.Write([]byte("\n[service]\n"))
 		enc.Encode(chainDef.Service)
 		writer.Write([]byte("\n[maintainer]\n"))
@@ -9,6 +9,7 @@ import (
 	"github.com/eris-ltd/eris-cli/config"
 	def "github.com/eris-ltd/eris-cli/definitions"
 	"github.com/eris-ltd/eris-cli/list"
+	"github.com/eris-ltd/eris-cli/stats"
 
 	. "github.com/eris-ltd/common/go/common"
 	"github.com/spf13/cobra"
@@ -48,16 +49,27 @@ func buildChainsCommand() {
 	Chains.AddCommand(chainsEdit)
 	Chains.AddCommand(chainsStart)
 	Chains.AddCommand(chainsLogs)
+	Chains.AddCommand(chainsStats)
+	Chains.AddCommand(chainsStatus)
 	Chains.AddCommand(chainsInspect)
 	Chains.AddCommand(chainsStop)
 	Chains.AddCommand(chainsExec)
 	Chains.AddCommand(chainsCat)
+	Chains.AddCommand(chainsConfig)
+	Chains.AddCommand(chainsValidators)
+	Chains.AddCommand(chainsPeers)
 	Chains.AddCommand(chainsExport)
+	Chains.AddCommand(chainsBackup)
+	Chains.AddCommand(chainsRestore)
 	Chains.AddCommand(chainsRename)
 	Chains.AddCommand(chainsUpdate)
 	Chains.AddCommand(chainsRestart)
 	Chains.AddCommand(chainsRemove)
 	Chains.AddCommand(chainsGraduate)
+	Chains.AddCommand(chainsGenesis)
+	chainsGenesis.AddCommand(chainsGenesisValidate)
+	chainsGenesis.AddCommand(chainsGenesisShow)
+	chainsGenesis.AddCommand(chainsGenesisDiff)
 	// Chains.AddCommand(chainsMakeGenesis)
 	addChainsFlags()
 }
@@ -128,11 +140,22 @@ unless the --serverconf or --dir flag is passed.
 If you would like to create a genesis.json then please utilize [eris chains make]
 
 You can redefine the chain ports accessible over the network with the --ports flag.
+
+With the --nodes flag (or a [nodes] section with a count in the chain
+definition file) the chain is run as several validator nodes, each with
+a data container and a chain container of its own, named NAME-0, NAME-1,
+etc. The validator files are taken from ~/.eris/chains/NAME, and made
+with [eris chains make NAME --account-types=Validator:N] if missing. The
+nodes find each other on the chain network and publish their ports at
+random. [eris chains start], [stop], [logs] and [rm] given the chain name
+act on all nodes; given a node name, on that node alone.
 `,
 	Run: NewChain,
 	Example: `$ eris chains new simplechain --ports 4000 -- map the first port from the definition file to the host port 40000
 $ eris chains new simplechain --ports 40000,50000- -- redefine the first and the second port mapping and autoincrement the rest
-$ eris chains new simplechain --ports 46656:50000 -- redefine the specific port mapping (published host port:exposed container port)`,
+$ eris chains new simplechain --ports 46656:50000 -- redefine the specific port mapping (published host port:exposed container port)
+$ eris chains new testnet --nodes 4 -- run a chain with 4 validators in the testnet-0 ... testnet-3 containers
+$ eris chains stop testnet-2 -- take one validator down`,
 }
 
 var chainsRegister = &cobra.Command{
@@ -290,19 +313,58 @@ var chainsLogs = &cobra.Command{
 	Run:   LogChain,
 }
 
+var chainsStats = &cobra.Command{
+	Use:   "stats NAME",
+	Short: "Display resource usage of a running blockchain.",
+	Long: `Display a live stream of CPU, memory, network, and block I/O
+usage of a running blockchain, with the usage of its data container
+(if it is running) added up.
+
+The --no-stream flag displays the usage once and exits.`,
+	Run: StatsChain,
+}
+
+var chainsStatus = &cobra.Command{
+	Use:   "status [NAME...]",
+	Short: "Display the block height, peers, and sync state of chains.",
+	Long: `Display the block height, peers, and sync state of chains.
+
+Status queries the tendermint RPC of the named chains, or of all running
+chains, for the latest block height, hash, and time, the number of
+validators and peers, whether the node is catching up, and the node
+version.
+A chain made of several validator nodes is shown node by node.
+
+A node with peers whose latest block is more than a minute old is
+reported to be catching up. The --watch flag refreshes the output
+every couple of seconds until interrupted.`,
+	Example: `$ eris chains status simplechain
+$ eris chains status --json --watch`,
+	Run: StatusChain,
+}
+
 var chainsExec = &cobra.Command{
 	Use:   "exec NAME",
 	Short: "Run a command or interactive shell",
 	Long: `Run a command or interactive shell in a container
-with volumes-from the data container`,
+with volumes-from the data container.
+
+With the --attach flag, the command is run inside the running
+chain container instead (similar to [docker exec]), so it
+sees the chain processes and files. The eris tool then exits
+with the exit status of the command.`,
 	Run: ExecChain,
 }
 
 var chainsStop = &cobra.Command{
 	Use:   "stop NAME",
 	Short: "Stop a running blockchain.",
-	Long:  `Stop a running blockchain.`,
-	Run:   KillChain,
+	Long: `Stop a running blockchain.
+
+Running services and chains which depend on the chain are stopped
+first. The --cascade flag also stops the dependencies of the chain
+(e.g. keys) which nothing else running uses.`,
+	Run: KillChain,
 }
 
 var chainsInspect = &cobra.Command{
@@ -328,6 +390,38 @@ Command will return a machine readable version of the IPFS hash.`,
 	Run: ExportChain,
 }
 
+var chainsBackup = &cobra.Command{
+	Use:   "backup NAME",
+	Short: "Back up a chain into an archive.",
+	Long: `Back up a chain into an archive which can be restored on another machine.
+
+The gzipped tar archive holds a manifest, the chain definition file, the
+contents of the chain data container (blockchain database, config.toml,
+genesis.json, and priv_validator.json), and the keys of the validator and
+the genesis accounts found in the keys container. It is written to
+NAME.tar.gz unless the -o flag says otherwise.
+
+A running chain is stopped while its data is copied, for the database
+to be consistent, and started again afterwards.
+
+The archive holds private keys. Keep it safe.`,
+	Example: `$ eris chains backup simplechain -o simplechain.tar.gz`,
+	Run:     BackupChain,
+}
+
+var chainsRestore = &cobra.Command{
+	Use:   "restore FILE",
+	Short: "Restore a chain from an archive.",
+	Long: `Restore a chain from an archive written by [eris chains backup].
+
+Restore recreates the chain definition file and the chain data container,
+and adds the backed up keys to the keys container. The --as flag restores
+the chain under another name. The chain is not started.`,
+	Example: `$ eris chains restore simplechain.tar.gz
+$ eris chains restore simplechain.tar.gz --as otherchain`,
+	Run: RestoreChain,
+}
+
 var chainsRename = &cobra.Command{
 	Use:   "rename OLD_NAME NEW_NAME",
 	Short: "Rename a blockchain.",
@@ -360,8 +454,23 @@ Functionally this command will perform the following sequence:
 
 NOTE: If the chain uses data containers those will not be affected
 by the update command.
+
+With the --image flag, the chain is upgraded to that image instead,
+and rolled back if the upgrade fails:
+
+1. Record the image and configuration of the chain container.
+2. Stop the chain and take a data snapshot tagged pre-upgrade-TIME.
+3. Recreate the container from the chain definition with the new image
+   and start it.
+4. Wait up to a minute for the chain to produce a block.
+
+If no block comes, the previous container and the data snapshot are
+restored and the chain is started again. A chain made of several
+validator nodes is upgraded one node at a time, stopping at the first
+node which fails.
 `,
-	Run: UpdateChain,
+	Example: `$ eris chains update simplechain --image quay.io/eris/db:0.12.0`,
+	Run:     UpdateChain,
 }
 
 var chainsRestart = &cobra.Command{
@@ -402,6 +511,121 @@ $ eris chains cat simplechain genesis -- will display the genesis.json file from
 	Run: CatChain,
 }
 
+var chainsConfig = &cobra.Command{
+	Use:   "config NAME get|set|unset|edit [KEY] [VALUE]",
+	Short: "Read and change the configuration of a chain.",
+	Long: `Read and change the tendermint and eris:db configuration of a chain.
+
+Config works on the config.toml file in the chain data container, so the
+chain doesn't have to be recreated. Keys are dotted paths of the TOML tables
+(e.g. moniker or seeds, TABLE.KEY for the keys of a table). Values are
+taken as TOML values (e.g. 10, true, or ["a", "b"]) and as strings
+otherwise. Setting a key which is not in the file yet or a value of another
+type needs --force. Changes are made in place, keeping the comments of the
+file, those at the end of the changed lines included; edit opens the whole
+file in your $EDITOR. The chain reads its configuration when it starts, so
+use --restart to apply a change to a running chain.`,
+	Example: `$ eris chains config simplechain get moniker
+$ eris chains config simplechain set moniker marmot --restart
+$ eris chains config simplechain set fast_sync false
+$ eris chains config simplechain unset seeds
+$ eris chains config simplechain edit`,
+	Run: ConfigChain,
+}
+
+var chainsValidators = &cobra.Command{
+	Use:   "validators NAME ls|add|rm [VALIDATOR]",
+	Short: "List, add, and remove the validators of a chain.",
+	Long: `List, add, and remove the validators of a chain.
+
+Before a chain is started, its validators are those of the genesis files
+in ~/.eris/chains/NAME and its subdirectories, which add and rm change.
+The validator key is taken from the keys service with --address, or
+generated there if it's not given; --pubkey adds a validator whose key is
+elsewhere. rm takes the public key, the name, or the unbond_to address of
+the validator.
+
+Once the chain runs, ls shows the bonded validators, and add and rm send
+bond and unbond transactions signed by the keys service. The validator key
+(--address) pays the bond, so it has to be funded. rm takes the address or
+the public key of the validator.`,
+	Example: `$ eris chains validators simplechain ls
+$ eris chains validators simplechain add --amount 10000
+$ eris chains validators simplechain add --pubkey 0A1B... --unbond-to 6D1A... --amount 10000
+$ eris chains validators simplechain rm 0A1B...
+$ eris chains validators simplechain add --address 6D1A... --amount 10000 -- bonds on a running chain`,
+	Run: ValidatorsChain,
+}
+
+var chainsPeers = &cobra.Command{
+	Use:   "peers NAME ls|add|rm [ADDR]",
+	Short: "List, add, and remove the peers of a chain.",
+	Long: `List, add, and remove the peers of a chain.
+
+The seeds a chain connects to are kept in the seeds field of its definition
+file, so they are not lost when the chain is recreated. add and rm change
+the definition and the config.toml file in the data container of every
+chain node. The chain connects to its seeds when it starts, so use
+--restart to apply a change to a running chain. ls shows the configured
+seeds along with the peers the running chain is connected to.`,
+	Example: `$ eris chains peers simplechain ls
+$ eris chains peers simplechain add 10.0.0.4:46656 --restart
+$ eris chains peers simplechain rm 10.0.0.4:46656`,
+	Run: PeersChain,
+}
+
+var chainsGenesis = &cobra.Command{
+	Use:   "genesis",
+	Short: "Validate, inspect, and compare genesis files.",
+	Long: `Validate, inspect, and compare genesis files.
+
+The genesis subcommands take either paths to genesis.json files or chain
+names. The genesis file of a chain is looked up in ~/.eris/chains/NAME
+and in the directories [eris chains make] writes there. The commands
+run on the host and do not need Docker.`,
+	// Unlike the other commands, these don't connect to Docker.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) { setupLogging() },
+	Run:              func(cmd *cobra.Command, args []string) { cmd.Help() },
+}
+
+var chainsGenesisValidate = &cobra.Command{
+	Use:   "validate FILE|NAME...",
+	Short: "Check genesis files against the genesis schema.",
+	Long: `Check genesis files against the genesis schema.
+
+Validate reports unknown fields, an empty chain ID, malformed account
+addresses and validator public keys, negative or zero amounts, missing
+unbond_to entries, and duplicate accounts or validators. The command
+exits with an error if any of the files is not valid.`,
+	Example: `$ eris chains genesis validate simplechain
+$ eris chains genesis validate ~/.eris/chains/*/genesis.json`,
+	Run: ValidateGenesis,
+}
+
+var chainsGenesisShow = &cobra.Command{
+	Use:   "show FILE|NAME",
+	Short: "Display the accounts and validators of a genesis file.",
+	Long:  `Display the chain ID, accounts, and validators of a genesis file.`,
+	Example: `$ eris chains genesis show simplechain
+$ eris chains genesis show ./genesis.json --json`,
+	Run: ShowGenesis,
+}
+
+var chainsGenesisDiff = &cobra.Command{
+	Use:   "diff FILE|NAME FILE|NAME",
+	Short: "Compare two genesis files.",
+	Long: `Compare two genesis files.
+
+Accounts are matched by address and validators by public key, so
+reordering them is not a difference. Lines starting with - are only in
+the first file, lines starting with + are only in the second, and lines
+starting with ~ are changes. The command exits with an error if the
+files differ.`,
+	Example: `$ eris chains genesis diff simplechain otherchain
+$ eris chains genesis diff simplechain ./genesis.json`,
+	Run: DiffGenesis,
+}
+
 // var chainsMakeGenesis = &cobra.Command{
 // 	Use:   "make-genesis NAME KEY",
 // 	Short: "Generates a genesis file.",
@@ -418,6 +642,9 @@ $ eris chains cat simplechain genesis -- will display the genesis.json file from
 //----------------------------------------------------------------------
 
 func addChainsFlags() {
+	Chains.PersistentFlags().StringVarP(&do.Profile, "profile", "", "", "profile of the chain definitions to use (defaults to the eris.toml Profile)")
+	Chains.PersistentFlags().StringSliceVarP(&do.Settings, "set", "", []string{}, "set a chain definition field, e.g. --set service.restart=always")
+
 	chainsMake.PersistentFlags().StringSliceVarP(&do.AccountTypes, "account-types", "", []string{}, "what number of account types should we use? find these in ~/.eris/chains/account_types; incompatible with and overrides chain-type")
 	chainsMake.PersistentFlags().StringVarP(&do.ChainType, "chain-type", "", "", "which chain type definition should we use? find these in ~/.eris/chains/chain_types")
 	chainsMake.PersistentFlags().BoolVarP(&do.Tarball, "tar", "", false, "instead of making directories in ~/.eris/chains, make tarballs; incompatible with and overrides zip")
@@ -442,6 +669,8 @@ func addChainsFlags() {
 	buildFlag(chainsNew, do, "ports", "chain")
 	buildFlag(chainsNew, do, "links", "chain")
 	buildFlag(chainsNew, do, "api", "chain")
+	buildFlag(chainsNew, do, "wait", "chain")
+	chainsNew.PersistentFlags().IntVarP(&do.Nodes, "nodes", "", 0, "run the chain as this many validator nodes, each in a container of its own (NAME-0, NAME-1, ...)")
 	chainsNew.PersistentFlags().BoolVarP(&do.Logrotate, "logrotate", "z", false, "turn on logrotate as a dependency to handle long output")
 
 	// buildFlag(chainsRegister, do, "links", "chain")
@@ -464,17 +693,29 @@ func addChainsFlags() {
 	buildFlag(chainsStart, do, "env", "chain")
 	buildFlag(chainsStart, do, "links", "chain")
 	buildFlag(chainsStart, do, "api", "chain")
+	buildFlag(chainsStart, do, "wait", "chain")
 	chainsStart.PersistentFlags().BoolVarP(&do.Logrotate, "logrotate", "z", false, "turn on logrotate as a dependency to handle long output")
 
 	buildFlag(chainsLogs, do, "follow", "chain")
 	buildFlag(chainsLogs, do, "tail", "chain")
 
+	chainsStats.Flags().BoolVarP(&do.NoStream, "no-stream", "", false, "display the usage once instead of streaming it")
+	chainsStats.Flags().BoolVarP(&do.JSON, "json", "", false, "machine readable output")
+
+	chainsStatus.Flags().BoolVarP(&do.JSON, "json", "", false, "machine readable output")
+	chainsStatus.Flags().BoolVarP(&do.Watch, "watch", "w", false, "refresh the output until interrupted")
+
 	buildFlag(chainsExec, do, "publish", "chain")
 	buildFlag(chainsExec, do, "ports", "chain")
 	buildFlag(chainsExec, do, "interactive", "chain")
+	buildFlag(chainsExec, do, "attach", "chain")
 	buildFlag(chainsExec, do, "links", "chain")
 	chainsExec.Flags().StringVarP(&do.Image, "image", "", "", "docker image")
 
+	chainsBackup.Flags().StringVarP(&do.Destination, "output", "o", "", "archive path (defaults to NAME.tar.gz)")
+	buildFlag(chainsBackup, do, "timeout", "chain")
+	chainsRestore.Flags().StringVarP(&do.NewName, "as", "", "", "restore the chain under this name")
+
 	buildFlag(chainsRemove, do, "force", "chain")
 	buildFlag(chainsRemove, do, "file", "chain")
 	buildFlag(chainsRemove, do, "data", "chain")
@@ -483,17 +724,34 @@ func addChainsFlags() {
 
 	buildFlag(chainsUpdate, do, "pull", "chain")
 	buildFlag(chainsUpdate, do, "timeout", "chain")
+	chainsUpdate.Flags().StringVarP(&do.Image, "image", "", "", "upgrade the chain to this image, rolling back if it fails")
 	buildFlag(chainsUpdate, do, "env", "chain")
 	buildFlag(chainsUpdate, do, "links", "chain")
 
+	chainsConfig.Flags().BoolVarP(&do.Force, "force", "f", false, "add keys missing from the file and change the type of values")
+	chainsConfig.Flags().BoolVarP(&do.Restart, "restart", "", false, "restart the chain if it is running to apply the change")
+	buildFlag(chainsConfig, do, "timeout", "chain")
+
+	chainsValidators.Flags().StringVarP(&do.Address, "address", "", "", "address of the validator key in the keys service")
+	chainsValidators.Flags().StringVarP(&do.Pubkey, "pubkey", "", "", "public key of a validator whose key is not in the keys service")
+	chainsValidators.Flags().Uint64VarP(&do.Amount, "amount", "", 0, "amount to bond")
+	chainsValidators.Flags().StringVarP(&do.UnbondTo, "unbond-to", "", "", "address the bond goes to when unbonding (defaults to the validator address)")
+	chainsValidators.Flags().BoolVarP(&do.JSON, "json", "", false, "machine readable output")
+
+	chainsPeers.Flags().BoolVarP(&do.Restart, "restart", "", false, "restart the chain if it is running to connect to the peers")
+	buildFlag(chainsPeers, do, "timeout", "chain")
+	chainsPeers.Flags().BoolVarP(&do.JSON, "json", "", false, "machine readable output")
+
 	buildFlag(chainsStop, do, "rm", "chain")
 	buildFlag(chainsStop, do, "data", "chain")
 	buildFlag(chainsStop, do, "force", "chain")
 	buildFlag(chainsStop, do, "timeout", "chain")
+	buildFlag(chainsStop, do, "cascade", "chain")
 	buildFlag(chainsStop, do, "volumes", "chain")
 
 	buildFlag(chainsList, do, "known", "chain")
 	chainsList.Flags().BoolVarP(&do.JSON, "json", "", false, "machine readable output")
+	chainsGenesisShow.Flags().BoolVarP(&do.JSON, "json", "", false, "machine readable output")
 	chainsList.Flags().BoolVarP(&do.All, "all", "a", false, "show extended output")
 	chainsList.Flags().BoolVarP(&do.Quiet, "quiet", "q", false, "show a list of chain names")
 	chainsList.Flags().StringVarP(&do.Format, "format", "f", "", "alternate format for columnized output")
@@ -514,6 +772,20 @@ func LogChain(cmd *cobra.Command, args []string) {
 	IfExit(chns.LogsChain(do))
 }
 
+func StatsChain(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(1, "eq", cmd, args))
+	format := ""
+	if do.JSON {
+		format = "json"
+	}
+	IfExit(stats.Display(os.Stdout, def.TypeChain, args, format, !do.NoStream))
+}
+
+func StatusChain(cmd *cobra.Command, args []string) {
+	do.Operations.Args = args
+	IfExit(chns.StatusChain(do))
+}
+
 func ExecChain(cmd *cobra.Command, args []string) {
 	IfExit(ArgCheck(1, "ge", cmd, args))
 
@@ -532,7 +804,7 @@ func ExecChain(cmd *cobra.Command, args []string) {
 	config.GlobalConfig.InteractiveWriter = os.Stdout
 	config.GlobalConfig.InteractiveErrorWriter = os.Stderr
 	_, err := chns.ExecChain(do)
-	IfExit(err)
+	IfExitExec(err)
 }
 
 func KillChain(cmd *cobra.Command, args []string) {
@@ -631,6 +903,30 @@ func CatChain(cmd *cobra.Command, args []string) {
 	IfExit(chns.CatChain(do))
 }
 
+func ConfigChain(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(2, "ge", cmd, args))
+	do.Name = args[0]
+	do.Type = args[1]
+	do.Operations.Args = args[2:]
+	IfExit(chns.ConfigChain(do))
+}
+
+func ValidatorsChain(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(2, "ge", cmd, args))
+	do.Name = args[0]
+	do.Type = args[1]
+	do.Operations.Args = args[2:]
+	IfExit(chns.ValidatorsChain(do))
+}
+
+func PeersChain(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(2, "ge", cmd, args))
+	do.Name = args[0]
+	do.Type = args[1]
+	do.Operations.Args = args[2:]
+	IfExit(chns.PeersChain(do))
+}
+
 func PortsChain(cmd *cobra.Command, args []string) {
 	IfExit(ArgCheck(1, "ge", cmd, args))
 	do.Name = args[0]
@@ -689,6 +985,19 @@ func ListChains(cmd *cobra.Command, args []string) {
 	}
 }
 
+func BackupChain(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(1, "eq", cmd, args))
+	do.Name = args[0]
+	IfExit(chns.BackupChain(do))
+	fmt.Fprintln(config.GlobalConfig.Writer, do.Result)
+}
+
+func RestoreChain(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(1, "eq", cmd, args))
+	do.Source = args[0]
+	IfExit(chns.RestoreChain(do))
+}
+
 func RenameChain(cmd *cobra.Command, args []string) {
 	IfExit(ArgCheck(2, "eq", cmd, args))
 	do.Name = args[0]
@@ -723,6 +1032,24 @@ func GraduateChain(cmd *cobra.Command, args []string) {
 	IfExit(chns.GraduateChain(do))
 }
 
+func ValidateGenesis(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(1, "ge", cmd, args))
+	do.Operations.Args = args
+	IfExit(chns.ValidateGenesis(do))
+}
+
+func ShowGenesis(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(1, "eq", cmd, args))
+	do.Name = args[0]
+	IfExit(chns.ShowGenesis(do))
+}
+
+func DiffGenesis(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(2, "eq", cmd, args))
+	do.Operations.Args = args
+	IfExit(chns.DiffGenesis(do))
+}
+
 func MakeGenesisFile(cmd *cobra.Command, args []string) {
 	IfExit(ArgCheck(2, "ge", cmd, args))       //eq doesn't fly...
 	do.Chain.Name = strings.TrimSpace(args[0]) //trim for bash
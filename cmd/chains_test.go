@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const genesisFile = `{
+  "chain_id": "simplechain",
+  "accounts": [{"address": "6D1A4E9FA2AD3F8B3E0A37E14D3F08D2AFE7A34B", "amount": 9999999}],
+  "validators": [
+    {
+      "pub_key": [1, "F6C79CF0CB9D66B677988BCB9B8EADD9A091CD465A60542A8AB85476256DBA92"],
+      "amount": 10,
+      "unbond_to": [{"address": "6D1A4E9FA2AD3F8B3E0A37E14D3F08D2AFE7A34B", "amount": 10}]
+    }
+  ]
+}`
+
+// The genesis commands run in a process of their own, because the
+// marmots exit if they cannot connect to Docker.
+func TestChainsGenesisWithoutDocker(t *testing.T) {
+	if file := os.Getenv("ERIS_TEST_GENESIS_FILE"); file != "" {
+		InitializeConfig()
+		AddGlobalFlags()
+		AddCommands()
+		ErisCmd.SetArgs([]string{"chains", "genesis", "validate", file})
+		ErisCmd.Execute()
+		return
+	}
+
+	root, err := ioutil.TempDir("", "eris-root")
+	if err != nil {
+		t.Fatalf("expected temp dir, got %v", err)
+	}
+	defer os.RemoveAll(root)
+	file := filepath.Join(root, "genesis.json")
+	if err := ioutil.WriteFile(file, []byte(genesisFile), 0644); err != nil {
+		t.Fatalf("expected genesis file, got %v", err)
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestChainsGenesisWithoutDocker")
+	for _, env := range os.Environ() {
+		if !strings.HasPrefix(env, "DOCKER_") && !strings.HasPrefix(env, "ERIS=") {
+			cmd.Env = append(cmd.Env, env)
+		}
+	}
+	cmd.Env = append(cmd.Env, "ERIS="+root, "ERIS_TEST_GENESIS_FILE="+file)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("expected genesis validate to succeed without Docker, got %v: %s", err, out)
+	}
+}
@@ -37,7 +37,13 @@ Notes:
 
 At Eris, we use this functionality to formulate little JSONs
 and configs on the host and then "stick them back into the
-containers"`,
+containers"
+
+Instead of a data container, the data can be kept in a named
+Docker volume (see the storage setting of service and chain
+definitions, and Storage in eris.toml). All data commands work
+with either; [eris data migrate] moves a data container into
+a volume.`,
 	Run: func(cmd *cobra.Command, args []string) { cmd.Help() },
 }
 
@@ -50,6 +56,12 @@ func buildDataCommand() {
 	Data.AddCommand(dataExport)
 	Data.AddCommand(dataExec)
 	Data.AddCommand(dataRm)
+	Data.AddCommand(dataMigrate)
+	Data.AddCommand(dataSnapshot)
+	Data.AddCommand(dataSnapshots)
+	Data.AddCommand(dataRestore)
+	dataSnapshots.AddCommand(dataSnapshotsList)
+	dataSnapshots.AddCommand(dataSnapshotsPrune)
 	addDataFlags()
 }
 
@@ -78,7 +90,7 @@ Container path enters at /home/eris/.eris.`,
 var dataList = &cobra.Command{
 	Use:   "ls",
 	Short: "List the data containers eris manages for you",
-	Long: `List data containers.
+	Long: `List data containers and data volumes.
 
 The --json flag dumps the container or known files information
 in the JSON format.
@@ -134,10 +146,84 @@ var dataInspect = &cobra.Command{
 var dataRm = &cobra.Command{
 	Use:   "rm NAME",
 	Short: "Remove a data container",
-	Long:  `Remove a data container`,
+	Long:  `Remove a data container or a data volume`,
 	Run:   RmData,
 }
 
+var dataMigrate = &cobra.Command{
+	Use:   "migrate NAME",
+	Short: "Move a data container's contents into a data volume",
+	Long: `Move a data container's contents into a named Docker volume.
+
+The data volume is used in place of the data container from then on,
+and the data container is removed. The service or chain the data
+belongs to must be stopped; its container is removed too, so that
+it is recreated with the volume the next time it is started.`,
+	Example: `$ eris chains stop simplechain
+$ eris data migrate simplechain
+$ eris chains start simplechain`,
+	Run: MigrateData,
+}
+
+var dataSnapshot = &cobra.Command{
+	Use:   "snapshot NAME",
+	Short: "Save a versioned copy of a data container's contents",
+	Long: `Save a compressed copy of the /home/eris/.eris directory of a data
+container or a data volume on the host, under ~/.eris/snapshots/NAME.
+
+Next to each snapshot a manifest records its creation time, the image
+of the service or chain using the data, the chain height if the chain
+is running, and the checksum of the snapshot.
+
+Snapshots are named after their creation time unless given a tag with
+the --tag flag. The --keep flag prunes the older untagged snapshots,
+keeping that many; tagged snapshots are never pruned.`,
+	Example: `$ eris data snapshot simplechain -- take a snapshot
+$ eris data snapshot simplechain --tag before-upgrade -- take a tagged snapshot
+$ eris data snapshot simplechain --keep 5 -- take a snapshot and keep only the 5 newest ones`,
+	Run: SnapshotData,
+}
+
+var dataSnapshots = &cobra.Command{
+	Use:   "snapshots",
+	Short: "Manage data snapshots",
+	Long:  `List and prune the data snapshots taken with [eris data snapshot].`,
+	Run:   func(cmd *cobra.Command, args []string) { cmd.Help() },
+}
+
+var dataSnapshotsList = &cobra.Command{
+	Use:   "ls [NAME]",
+	Short: "List data snapshots",
+	Long: `List the snapshots of the named data, or of all data, oldest first.
+
+The --json flag dumps the snapshot manifests in the JSON format.`,
+	Run: ListSnapshots,
+}
+
+var dataSnapshotsPrune = &cobra.Command{
+	Use:   "prune NAME",
+	Short: "Remove old data snapshots",
+	Long: `Remove the oldest untagged snapshots of the named data, keeping
+the number given with the --keep flag. Tagged snapshots are kept.`,
+	Example: `$ eris data snapshots prune simplechain --keep 3`,
+	Run:     PruneSnapshots,
+}
+
+var dataRestore = &cobra.Command{
+	Use:   "restore NAME SNAPSHOT",
+	Short: "Replace a data container's contents with a snapshot",
+	Long: `Replace the contents of the /home/eris/.eris directory of a data
+container or a data volume with a snapshot taken with [eris data snapshot].
+The snapshot is checked against its manifest checksum first.
+
+If the service or chain using the data is running, it is stopped for the
+time of the restore and then started again. The data container is created
+if it doesn't exist.`,
+	Example: `$ eris data snapshots ls simplechain
+$ eris data restore simplechain before-upgrade`,
+	Run: RestoreData,
+}
+
 //----------------------------------------------------
 
 func addDataFlags() {
@@ -151,6 +237,12 @@ func addDataFlags() {
 
 	buildFlag(dataExec, do, "interactive", "data")
 
+	dataSnapshot.Flags().StringVarP(&do.Tag, "tag", "", "", "name the snapshot instead of using the creation time; tagged snapshots are never pruned")
+	dataSnapshot.Flags().IntVarP(&do.Keep, "keep", "", 0, "prune the older untagged snapshots, keeping that many (0 keeps all)")
+	dataSnapshotsList.Flags().BoolVarP(&do.JSON, "json", "", false, "machine readable output")
+	dataSnapshotsPrune.Flags().IntVarP(&do.Keep, "keep", "", 0, "number of untagged snapshots to keep (required)")
+	buildFlag(dataRestore, do, "timeout", "data")
+
 }
 
 func ListData(cmd *cobra.Command, args []string) {
@@ -189,6 +281,46 @@ func RmData(cmd *cobra.Command, args []string) {
 	IfExit(data.RmData(do))
 }
 
+func MigrateData(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(1, "eq", cmd, args))
+	do.Name = args[0]
+	IfExit(data.MigrateData(do))
+}
+
+func SnapshotData(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(1, "eq", cmd, args))
+	do.Name = args[0]
+	IfExit(data.SnapshotData(do))
+	fmt.Println(do.Result)
+}
+
+func ListSnapshots(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		cmd.Help()
+		Exit(fmt.Errorf("Please send the marmots at most one data container name"))
+	}
+	if len(args) == 1 {
+		do.Name = args[0]
+	}
+	IfExit(data.PrintSnapshots(do))
+}
+
+func PruneSnapshots(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(1, "eq", cmd, args))
+	if !cmd.Flags().Changed("keep") || do.Keep < 0 {
+		cmd.Help()
+		Exit(fmt.Errorf("Please tell the marmots how many snapshots to keep with the --keep flag"))
+	}
+	IfExit(data.PruneSnapshots(args[0], do.Keep))
+}
+
+func RestoreData(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(2, "eq", cmd, args))
+	do.Name = args[0]
+	do.Tag = args[1]
+	IfExit(data.RestoreData(do))
+}
+
 func ImportData(cmd *cobra.Command, args []string) {
 	IfExit(ArgCheck(3, "eq", cmd, args))
 	do.Name = args[0]
@@ -9,6 +9,8 @@ import (
 	"github.com/eris-ltd/eris-cli/config"
 	"github.com/eris-ltd/eris-cli/definitions"
 	"github.com/eris-ltd/eris-cli/initialize"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/perform"
 	"github.com/eris-ltd/eris-cli/util"
 	"github.com/eris-ltd/eris-cli/version"
 
@@ -36,23 +38,11 @@ Made with <3 by Eris Industries.
 Complete documentation is available at https://docs.erisindustries.com
 ` + "\nVersion:\n  " + VERSION,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		// Using stdout for less fuss with redirecting the log messages
-		// into a file (`eris > out`) or a viewer (`eris|more`).
-		log.SetOutput(os.Stdout)
-
-		// The baseline logging level (to record debug logging
-		// messages for remote logging, not for console).
-		log.SetLevel(log.DebugLevel)
-
-		log.SetFormatter(logger.ConsoleFormatter(log.WarnLevel))
-		if do.Verbose {
-			log.SetFormatter(logger.ConsoleFormatter(log.InfoLevel))
-		} else if do.Debug {
-			log.SetFormatter(logger.ConsoleFormatter(log.DebugLevel))
-		}
+		setupLogging()
 
 		util.DockerConnect(do.Verbose, do.MachineName)
 		ipfs.IpfsHost = config.GlobalConfig.Config.IpfsHost
+		loaders.Profile, loaders.Settings, loaders.Names = do.Profile, do.Settings, args
 
 		if os.Getenv("TEST_ON_WINDOWS") == "true" || os.Getenv("TEST_ON_MACOSX") == "true" {
 			return
@@ -103,6 +93,24 @@ Complete documentation is available at https://docs.erisindustries.com
 	},
 }
 
+// setupLogging sets the console logging up according to the global flags.
+func setupLogging() {
+	// Using stdout for less fuss with redirecting the log messages
+	// into a file (`eris > out`) or a viewer (`eris|more`).
+	log.SetOutput(os.Stdout)
+
+	// The baseline logging level (to record debug logging
+	// messages for remote logging, not for console).
+	log.SetLevel(log.DebugLevel)
+
+	log.SetFormatter(logger.ConsoleFormatter(log.WarnLevel))
+	if do.Verbose {
+		log.SetFormatter(logger.ConsoleFormatter(log.InfoLevel))
+	} else if do.Debug {
+		log.SetFormatter(logger.ConsoleFormatter(log.DebugLevel))
+	}
+}
+
 func Execute() {
 	// Handle panics within Execute().
 	defer func() {
@@ -135,6 +143,10 @@ func AddCommands() {
 	ErisCmd.AddCommand(Data)
 	buildListCommand()
 	ErisCmd.AddCommand(List)
+	buildStatsCommand()
+	ErisCmd.AddCommand(Stats)
+	buildEventsCommand()
+	ErisCmd.AddCommand(Events)
 	buildAgentsCommand()
 	ErisCmd.AddCommand(Agents)
 	buildCleanCommand()
@@ -232,3 +244,12 @@ func FlagCheck(num int, comp string, cmd *cobra.Command, flags []string) error {
 	}
 	return nil
 }
+
+// IfExitExec is IfExit for exec commands: when the command run inside
+// a container fails, the eris tool exits with the command exit status.
+func IfExitExec(err error) {
+	if exit, ok := err.(perform.ExecError); ok {
+		os.Exit(exit.Code)
+	}
+	IfExit(err)
+}
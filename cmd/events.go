@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"os"
+
+	"github.com/eris-ltd/eris-cli/events"
+
+	. "github.com/eris-ltd/common/go/common"
+	"github.com/spf13/cobra"
+)
+
+var Events = &cobra.Command{
+	Use:   "events",
+	Short: "Display a live stream of service, chain, and data events.",
+	Long: `Display a live stream of lifecycle events (create, start, die,
+stop, destroy, etc.) of Eris service, chain, and data containers.
+
+Containers are shown by their type and short name. The die events
+carry the exit code of the container, so a chain crash can be caught
+as it happens rather than by polling [eris ls --json].
+
+The --since flag replays past events from the given time on and the
+--until flag stops the stream when the given time passes. Both take
+a date and time (2016-01-02T15:04:05Z), a Unix timestamp, or
+a duration (10m) meaning that long ago.
+
+The --type flag narrows the stream to chain, service, or data events.
+
+The --json flag prints one JSON document per event.`,
+	Example: `$ eris events
+$ eris events --type chain --json
+$ eris events --since 1h --until 10m`,
+	Run: StreamEvents,
+}
+
+func buildEventsCommand() {
+	Events.Flags().StringVarP(&do.Since, "since", "", "", "show events since this time or for this long")
+	Events.Flags().StringVarP(&do.Until, "until", "", "", "stop streaming at this time or this long ago")
+	Events.Flags().StringVarP(&do.Type, "type", "", "", "show only chain, service, or data events")
+	Events.Flags().BoolVarP(&do.JSON, "json", "", false, "machine readable output")
+}
+
+func StreamEvents(cmd *cobra.Command, args []string) {
+	IfExit(ArgCheck(0, "eq", cmd, args))
+	format := ""
+	if do.JSON {
+		format = "json"
+	}
+
+	IfExit(events.Display(os.Stdout, do.Type, do.Since, do.Until, format, nil))
+}
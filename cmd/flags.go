@@ -31,7 +31,9 @@ func buildFlag(cmd *cobra.Command, do *definitions.Do, flag, typ string) { //doe
 	case "force": //collect discrepancies here...
 		cmd.Flags().BoolVarP(&do.Force, "force", "f", false, "kill the container instantly without waiting to exit") //why do we even have a timeout??
 	case "timeout":
-		cmd.Flags().UintVarP(&do.Timeout, "timeout", "t", 10, "manually set the timeout; overridden by --force")
+		cmd.Flags().UintVarP(&do.Timeout, "timeout", "t", 10, "manually set the timeout (for each container stopped); overridden by --force")
+	case "cascade":
+		cmd.Flags().BoolVarP(&do.Cascade, "cascade", "", false, fmt.Sprintf("also stop the dependencies of the %s which nothing else running uses", typ))
 	case "volumes":
 		cmd.Flags().BoolVarP(&do.Volumes, "vol", "o", false, "remove volumes")
 	case "rm-volumes":
@@ -51,9 +53,15 @@ func buildFlag(cmd *cobra.Command, do *definitions.Do, flag, typ string) { //doe
 		cmd.PersistentFlags().StringVarP(&do.Operations.Ports, "ports", "", "", "reassign ports")
 	case "interactive":
 		cmd.Flags().BoolVarP(&do.Operations.Interactive, "interactive", "i", false, "interactive shell")
+	case "wait":
+		cmd.PersistentFlags().BoolVarP(&do.Operations.Wait, "wait", "", false, fmt.Sprintf("block until the %s passes its health check (or is running if it has none)", typ))
 		//update
 	case "pull":
 		cmd.Flags().BoolVarP(&do.Pull, "pull", "p", false, fmt.Sprintf("pull an updated version of the %s's base service image from docker hub", typ))
+	case "attach":
+		cmd.Flags().BoolVarP(&do.Operations.Attach, "attach", "", false, fmt.Sprintf("run the command inside the running %s container rather than in a new one", typ))
+	case "rebuild":
+		cmd.Flags().BoolVarP(&do.Rebuild, "rebuild", "", false, fmt.Sprintf("rebuild the %s's image from its build section even if it is up to date", typ))
 	case "env":
 		cmd.PersistentFlags().StringSliceVarP(&do.Env, "env", "e", nil, "multiple env vars can be passed using the KEY1=val1,KEY2=val2 syntax") //last digit; 1 or 2?
 	case "links":
@@ -85,6 +93,8 @@ func buildFlag(cmd *cobra.Command, do *definitions.Do, flag, typ string) { //doe
 		}
 	case "services":
 		cmd.Flags().StringSliceVarP(&do.ServicesSlice, "services", "s", []string{}, "comma separated list of services to start")
+	case "parallel":
+		cmd.Flags().UintVarP(&do.Parallel, "parallel", "", 1, fmt.Sprintf("number of %ss (and their dependencies) to start at the same time", typ))
 	case "config":
 		cmd.PersistentFlags().StringVarP(&do.ConfigFile, "config", "c", "", "main config file (config.toml) for the chain")
 	case "serverconf":
@@ -186,16 +186,26 @@ var servicesLogs = &cobra.Command{
 var servicesExec = &cobra.Command{
 	Use:   "exec NAME",
 	Short: "Run a command or interactive shell",
-	Long:  "Run a command or interactive shell in a container with volumes-from the data container",
-	Run:   ExecService,
+	Long: `Run a command or interactive shell in a container
+with volumes-from the data container.
+
+With the --attach flag, the command is run inside the running
+service container instead (similar to [docker exec]), so it
+sees the service processes and files. The eris tool then exits
+with the exit status of the command.`,
+	Run: ExecService,
 }
 
 // stop stops a running service
 var servicesStop = &cobra.Command{
 	Use:   "stop NAME",
 	Short: "Stop a running service.",
-	Long:  `Stop a service which is currently running.`,
-	Run:   KillService,
+	Long: `Stop a service which is currently running.
+
+Running services and chains which depend on the service are stopped
+first. The --cascade flag also stops the dependencies of the service
+which nothing else running uses.`,
+	Run: KillService,
 }
 
 var servicesRename = &cobra.Command{
@@ -219,6 +229,10 @@ Functionally this command will perform the following sequence of steps:
 4. Rebuild the container from the updated image.
 5. Restart the service (if it was previously running).
 
+Services with a build section in their definition file have their
+image built locally instead of pulled: whenever the image is missing
+or older than the build context, or always with the --rebuild flag.
+
 NOTE: If the service uses data containers, those will not be affected
 by the [eris update] command.`,
 	Run: UpdateService,
@@ -247,6 +261,9 @@ Command will cat local service definition file.`,
 // cli flags
 
 func addServicesFlags() {
+	Services.PersistentFlags().StringVarP(&do.Profile, "profile", "", "", "profile of the service definitions to use (defaults to the eris.toml Profile)")
+	Services.PersistentFlags().StringSliceVarP(&do.Settings, "set", "", []string{}, "set a service definition field, e.g. --set service.ports=4001:4001,8080:8080")
+
 	buildFlag(servicesLogs, do, "follow", "service")
 	buildFlag(servicesLogs, do, "tail", "service")
 
@@ -256,8 +273,10 @@ func addServicesFlags() {
 	buildFlag(servicesExec, do, "publish", "service")
 	buildFlag(servicesExec, do, "ports", "service")
 	buildFlag(servicesExec, do, "interactive", "service")
+	buildFlag(servicesExec, do, "attach", "service")
 
 	buildFlag(servicesUpdate, do, "pull", "service")
+	buildFlag(servicesUpdate, do, "rebuild", "service")
 	buildFlag(servicesUpdate, do, "timeout", "service")
 	buildFlag(servicesUpdate, do, "env", "service")
 	buildFlag(servicesUpdate, do, "links", "service")
@@ -273,12 +292,15 @@ func addServicesFlags() {
 	buildFlag(servicesStart, do, "env", "service")
 	buildFlag(servicesStart, do, "links", "service")
 	buildFlag(servicesStart, do, "chain", "service")
+	buildFlag(servicesStart, do, "wait", "service")
+	buildFlag(servicesStart, do, "parallel", "service")
 
 	buildFlag(servicesStop, do, "rm", "service")
 	buildFlag(servicesStop, do, "volumes", "service")
 	buildFlag(servicesStop, do, "data", "service")
 	buildFlag(servicesStop, do, "force", "service")
 	buildFlag(servicesStop, do, "timeout", "service")
+	buildFlag(servicesStop, do, "cascade", "service")
 	servicesStop.Flags().BoolVarP(&do.All, "all", "a", false, "stop the primary service and its dependent services")
 	servicesStop.Flags().StringVarP(&do.ChainName, "chain", "c", "", "specify a chain the service should also stop")
 
@@ -320,7 +342,7 @@ func ExecService(cmd *cobra.Command, args []string) {
 	config.GlobalConfig.InteractiveWriter = os.Stdout
 	config.GlobalConfig.InteractiveErrorWriter = os.Stderr
 	_, err := srv.ExecService(do)
-	IfExit(err)
+	IfExitExec(err)
 }
 
 func KillService(cmd *cobra.Command, args []string) {
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"os"
+
+	"github.com/eris-ltd/eris-cli/stats"
+
+	. "github.com/eris-ltd/common/go/common"
+	"github.com/spf13/cobra"
+)
+
+var Stats = &cobra.Command{
+	Use:   "stats [NAME...]",
+	Short: "Display resource usage of running services and chains.",
+	Long: `Display a live stream of CPU, memory, network, and block I/O
+usage of running Eris services and chains.
+
+Each service or chain is shown by its short name, with the usage of
+its data container (if it is running) added up. The NAME arguments
+narrow the output to the services or chains given.
+
+The --no-stream flag displays the usage once and exits.
+
+The --json flag dumps the usage in the JSON format, one document per
+refresh.`,
+	Example: `$ eris stats
+$ eris stats keys ipfs --no-stream
+$ eris stats --json`,
+	Run: StatsAll,
+}
+
+func buildStatsCommand() {
+	Stats.Flags().BoolVarP(&do.NoStream, "no-stream", "", false, "display the usage once instead of streaming it")
+	Stats.Flags().BoolVarP(&do.JSON, "json", "", false, "machine readable output")
+}
+
+func StatsAll(cmd *cobra.Command, args []string) {
+	format := ""
+	if do.JSON {
+		format = "json"
+	}
+
+	IfExit(stats.Display(os.Stdout, "all", args, format, !do.NoStream))
+}
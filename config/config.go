@@ -33,6 +33,25 @@ type ErisConfig struct {
 	DockerCertPath string `json:"DockerCertPath,omitempty" yaml:"DockerCertPath,omitempty" toml:"DockerCertPath,omitempty"`
 	CrashReport    string `json:"CrashReport,omitempty" yaml:"CrashReport,omitempty" toml:"CrashReport,omitempty"`
 
+	// Default logging driver and options for service and chain
+	// containers which don't set their own.
+	LogDriver string            `json:"LogDriver,omitempty" yaml:"LogDriver,omitempty" toml:"LogDriver,omitempty"`
+	LogOpts   map[string]string `json:"LogOpts,omitempty" yaml:"LogOpts,omitempty" toml:"LogOpts,omitempty"`
+
+	// Registries and mirrors to pull images from, in order ("docker.io"
+	// is Docker Hub), and the number of attempts for each of them. The
+	// registries listed are taken as mirrors of each other.
+	Registries  []string `json:"Registries,omitempty" yaml:"Registries,omitempty" toml:"Registries,omitempty"`
+	PullRetries int      `json:"PullRetries,omitempty" yaml:"PullRetries,omitempty" toml:"PullRetries,omitzero"`
+
+	// Default storage ("container" or "volume") for the data of services
+	// and chains which don't set their own.
+	Storage string `json:"Storage,omitempty" yaml:"Storage,omitempty" toml:"Storage,omitempty"`
+
+	// Default profile of chain and service definitions (see the
+	// [profiles] tables of definition files).
+	Profile string `json:"Profile,omitempty" yaml:"Profile,omitempty" toml:"Profile,omitempty"`
+
 	Verbose bool
 }
 
@@ -127,6 +146,12 @@ func GetConfigValue(key string) string {
 		return GlobalConfig.Config.DockerCertPath
 	case "CrashReport":
 		return GlobalConfig.Config.CrashReport
+	case "LogDriver":
+		return GlobalConfig.Config.LogDriver
+	case "Storage":
+		return GlobalConfig.Config.Storage
+	case "Profile":
+		return GlobalConfig.Config.Profile
 	default:
 		return ""
 	}
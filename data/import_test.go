@@ -0,0 +1,67 @@
+package data
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eris-ltd/eris-cli/config"
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/tests"
+
+	"github.com/eris-ltd/common/go/common"
+)
+
+func TestImportExecExportData(t *testing.T) {
+	_, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	os.Setenv("ERIS_PULL_APPROVE", "true")
+	defer os.Unsetenv("ERIS_PULL_APPROVE")
+
+	dir, err := ioutil.TempDir("", "eris-data")
+	if err != nil {
+		t.Fatalf("expected temp dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	config.ChangeErisDir(filepath.Join(dir, "eris"))
+	common.InitErisDir()
+	defer config.ChangeErisDir(tests.ErisDir)
+
+	source := filepath.Join(dir, "import")
+	os.MkdirAll(filepath.Join(source, "dir"), 0755)
+	ioutil.WriteFile(filepath.Join(source, "dir", "file"), []byte("hello"), 0644)
+
+	do := def.NowDo()
+	do.Name = "fake"
+	do.Source = source
+	do.Destination = common.ErisContainerRoot
+	if err := ImportData(do); err != nil {
+		t.Fatalf("expected import to succeed, got %v", err)
+	}
+
+	do = def.NowDo()
+	do.Name = "fake"
+	do.Operations.Args = []string{"cat", common.ErisContainerRoot + "/dir/file"}
+	out, err := ExecData(do)
+	if err != nil {
+		t.Fatalf("expected exec to succeed, got %v", err)
+	}
+	if !strings.Contains(out.String(), "hello") {
+		t.Fatalf("expected file contents in exec output, got %q", out.String())
+	}
+
+	do = def.NowDo()
+	do.Name = "fake"
+	do.Source = common.ErisContainerRoot
+	do.Destination = filepath.Join(dir, "export")
+	if err := ExportData(do); err != nil {
+		t.Fatalf("expected export to succeed, got %v", err)
+	}
+	if content, err := ioutil.ReadFile(filepath.Join(dir, "export", "dir", "file")); string(content) != "hello" {
+		t.Fatalf("expected exported file contents to match, got %q (error %v)", content, err)
+	}
+}
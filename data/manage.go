@@ -28,6 +28,8 @@ func RenameData(do *definitions.Do) error {
 		if err != nil {
 			return err
 		}
+	} else if util.IsDataVolume(do.Name) {
+		return fmt.Errorf("Docker cannot rename volumes. Export the %s data and import it under the new name instead", do.Name)
 	} else {
 		return fmt.Errorf("I cannot find that data container. Please check the data container name you sent me.")
 	}
@@ -46,6 +48,12 @@ func InspectData(do *definitions.Do) error {
 		if err != nil {
 			return err
 		}
+	} else if util.IsDataVolume(do.Name) {
+		log.WithField("=>", do.Name).Info("Inspecting data volume")
+
+		if err := perform.DockerInspectVolume(loaders.LoadDataDefinition(do.Name), do.Operations.Args[0]); err != nil {
+			return err
+		}
 	} else {
 		return fmt.Errorf("I cannot find that data container. Please check the data container name you sent me.")
 	}
@@ -71,6 +79,14 @@ func RmData(do *definitions.Do) (err error) {
 				return err
 			}
 
+		} else if util.IsDataVolume(do.Name) {
+			log.WithField("=>", do.Name).Info("Removing data volume")
+
+			if err = perform.DockerRemoveDataVolume(loaders.LoadDataDefinition(do.Name)); err != nil {
+				log.Errorf("Error removing %s: %v", do.Name, err)
+				return err
+			}
+
 		} else {
 			err = fmt.Errorf("I cannot find that data container for %s. Please check the data container name you sent me.", do.Name)
 			log.Error(err)
@@ -88,3 +104,26 @@ func RmData(do *definitions.Do) (err error) {
 	do.Result = "success"
 	return err
 }
+
+// MigrateData moves the contents of the do.Name data container into a data
+// volume, which is used instead from then on. The service or chain the data
+// belongs to has to be stopped. See perform.DockerMigrateData.
+func MigrateData(do *definitions.Do) error {
+	if util.IsDataVolume(do.Name) {
+		return fmt.Errorf("The %s data is already kept in a volume", do.Name)
+	}
+	if !util.IsData(do.Name) {
+		return fmt.Errorf("I cannot find that data container. Please check the data container name you sent me.")
+	}
+
+	log.WithField("=>", do.Name).Info("Migrating data container to a volume")
+
+	ops := loaders.LoadDataDefinition(do.Name)
+	ops.DataVolumeName = util.DataVolumeName(do.Name)
+	if err := perform.DockerMigrateData(ops); err != nil {
+		return err
+	}
+
+	do.Result = "success"
+	return nil
+}
@@ -22,11 +22,13 @@ import (
 )
 
 // ImportData does what it says. It imports from a host's Source to a Dest
-// in a data container. It returns an error.
+// in a data container or a data volume. It returns an error.
 //
 //  do.Name                       - name of the data container to use (required)
 //  do.Source                     - directory which should be imported (required)
 //  do.Destination                - directory to _unload_ the payload into (required)
+//  do.Service.Storage            - where to keep the data if it doesn't exist yet
+//                                  (see util.DataStorage)
 //
 // If the named data container does not exist, it will be created
 // If do.Destination does not exist, it will be created
@@ -42,28 +44,17 @@ func ImportData(do *definitions.Do) error {
 		"to":   do.Destination,
 	}).Debug("Importing")
 
-	if util.IsData(do.Name) {
-		srv := PretendToBeAService(do.Name)
-		exists := perform.ContainerExists(srv.Operations.SrvContainerName)
-
-		if !exists {
-			return fmt.Errorf("There is no data container for service %q", do.Name, srv.Operations.SrvContainerName)
-		}
+	if ops, ok := loadData(do.Name); ok {
 		if err := checkErisContainerRoot(do, "import"); err != nil {
 			return err
 		}
 
-		containerName := util.DataContainerName(do.Name)
-
-		doCheck := definitions.NowDo()
-		doCheck.Name = do.Name
-		doCheck.Operations.Args = []string{"test", "-d", do.Destination}
-		_, err := ExecData(doCheck)
-		if err != nil {
-			if err := runData(containerName, []string{"mkdir", "-p", do.Destination}); err != nil {
+		check := *ops
+		check.Args = []string{"test", "-d", do.Destination}
+		if _, err := perform.DockerExecData(&check, nil); err != nil {
+			if err := runData(ops, []string{"mkdir", "-p", do.Destination}); err != nil {
 				return err
 			}
-			return ImportData(do)
 		}
 
 		reader, err := util.TarForDocker(do.Source, 0)
@@ -72,6 +63,12 @@ func ImportData(do *definitions.Do) error {
 		}
 		defer reader.Close()
 
+		containerName, done, err := mountData(ops)
+		if err != nil {
+			return err
+		}
+		defer done()
+
 		opts := docker.UploadToContainerOptions{
 			InputStream:          reader,
 			Path:                 do.Destination,
@@ -80,32 +77,73 @@ func ImportData(do *definitions.Do) error {
 
 		log.WithField("=>", containerName).Info("Copying into container")
 		log.WithField("path", do.Source).Debug()
-		if err := util.DockerClient.UploadToContainer(srv.Operations.SrvContainerName, opts); err != nil {
+		if err := util.DockerClient.UploadToContainer(containerName, opts); err != nil {
 			return util.DockerError(err)
 		}
 
 		//required b/c `docker cp` (UploadToContainer) goes in as root
 		// and eris images have the `eris` user by default
-		if err := runData(containerName, []string{"chown", "--recursive", "eris", do.Destination}); err != nil {
+		if err := runData(ops, []string{"chown", "--recursive", "eris", do.Destination}); err != nil {
 			return util.DockerError(err)
 		}
 
 	} else {
-		log.WithField("name", do.Name).Info("Data container does not exist, creating it")
-		ops := loaders.LoadDataDefinition(do.Name)
-		if err := perform.DockerCreateData(ops); err != nil {
-			return fmt.Errorf("Error creating data container %v.", err)
+		if err := createData(do.Name, do.Service); err != nil {
+			return err
 		}
-
 		return ImportData(do)
 	}
 	do.Result = "success"
 	return nil
 }
 
-func runData(name string, args []string) error {
+// createData creates the named data container or data volume, whichever
+// util.DataStorage says for srv (srv may be nil).
+func createData(name string, srv *definitions.Service) error {
+	storage, err := util.DataStorage(name, srv)
+	if err != nil {
+		return err
+	}
+
+	ops := loaders.LoadDataDefinition(name)
+	if storage == definitions.StorageVolume {
+		log.WithField("name", name).Info("Data volume does not exist, creating it")
+		ops.DataVolumeName = util.DataVolumeName(name)
+		if err := perform.DockerCreateDataVolume(ops); err != nil {
+			return fmt.Errorf("Error creating data volume %v.", err)
+		}
+	} else {
+		log.WithField("name", name).Info("Data container does not exist, creating it")
+		if err := perform.DockerCreateData(ops); err != nil {
+			return fmt.Errorf("Error creating data container %v.", err)
+		}
+	}
+	return nil
+}
+
+// loadData returns the data definition of the named data and whether the
+// data exists. Where the data is kept is looked up once here, so that the
+// rest of an operation can go by the definition.
+func loadData(name string) (*definitions.Operation, bool) {
+	ops := loaders.LoadDataDefinition(name)
+	return ops, ops.DataVolumeName != "" || util.IsData(name)
+}
+
+// mountData returns the name of a container the data is mounted in, for
+// the Docker archive calls, and a function to call when done with it.
+// That is the data container itself, or a throwaway container if the data
+// is kept in a data volume.
+func mountData(ops *definitions.Operation) (string, func(), error) {
+	if ops.DataVolumeName == "" {
+		return ops.DataContainerName, func() {}, nil
+	}
+	return perform.DockerMountData(ops)
+}
+
+func runData(ops *definitions.Operation, args []string) error {
 	doRun := definitions.NowDo()
-	doRun.Operations.DataContainerName = name
+	doRun.Operations.DataContainerName = ops.DataContainerName
+	doRun.Operations.DataVolumeName = ops.DataVolumeName
 	doRun.Operations.ContainerType = "data"
 	doRun.Operations.Args = args
 	_, err := perform.DockerRunData(doRun.Operations, nil)
@@ -116,10 +154,9 @@ func runData(name string, args []string) error {
 }
 
 func ExecData(do *definitions.Do) (buf *bytes.Buffer, err error) {
-	if util.IsData(do.Name) {
+	if ops, ok := loadData(do.Name); ok {
 		log.WithField("=>", do.Operations.DataContainerName).Info("Executing data container")
 
-		ops := loaders.LoadDataDefinition(do.Name)
 		util.Merge(ops, do.Operations)
 		buf, err = perform.DockerExecData(ops, nil)
 		if err != nil {
@@ -134,7 +171,7 @@ func ExecData(do *definitions.Do) (buf *bytes.Buffer, err error) {
 
 //export from: do.Source(in container), to: do.Destination(on host)
 func ExportData(do *definitions.Do) error {
-	if util.IsData(do.Name) {
+	if ops, ok := loadData(do.Name); ok {
 		wd, err := os.Getwd()
 		if err != nil {
 			return err
@@ -149,13 +186,11 @@ func ExportData(do *definitions.Do) error {
 			return err
 		}
 
-		containerName := util.DataContainerName(do.Name)
-		srv := PretendToBeAService(do.Name)
-		exists := perform.ContainerExists(srv.Operations.SrvContainerName)
-
-		if !exists {
-			return fmt.Errorf("There is no data container for that service.")
+		containerName, done, err := mountData(ops)
+		if err != nil {
+			return err
 		}
+		defer done()
 
 		reader, writer := io.Pipe()
 		defer reader.Close()
@@ -174,7 +209,7 @@ func ExportData(do *definitions.Do) error {
 		go func() {
 			log.WithField("=>", containerName).Info("Copying out of container")
 			log.WithField("path", do.Source).Debug()
-			IfExit(util.DockerClient.DownloadFromContainer(srv.Operations.SrvContainerName, opts))
+			IfExit(util.DockerClient.DownloadFromContainer(containerName, opts))
 			writer.Close()
 		}()
 
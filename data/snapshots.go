@@ -0,0 +1,378 @@
+package data
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/perform"
+	"github.com/eris-ltd/eris-cli/util"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/go-units"
+
+	. "github.com/eris-ltd/common/go/common"
+)
+
+// Snapshot IDs of untagged snapshots are their UTC creation times.
+const snapshotTimeFormat = "20060102T150405.000Z"
+
+var tagRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// Snapshot is the manifest of a data snapshot, kept next to its archive
+// as ID.json.
+type Snapshot struct {
+	Name    string    `json:"name"`
+	ID      string    `json:"id"`
+	Tagged  bool      `json:"tagged"`
+	Created time.Time `json:"created"`
+	Storage string    `json:"storage"`
+	Image   string    `json:"image,omitempty"`
+	Height  *int      `json:"chain_height,omitempty"`
+	Size    int64     `json:"size"`
+	SHA256  string    `json:"sha256"`
+}
+
+// SnapshotsPath returns the host directory data snapshots are kept in,
+// one subdirectory per data container name.
+func SnapshotsPath() string {
+	return filepath.Join(ErisRoot, "snapshots")
+}
+
+// Archive returns the path to the snapshot's compressed tarball.
+func (s *Snapshot) Archive() string {
+	return filepath.Join(SnapshotsPath(), s.Name, s.ID+".tar.gz")
+}
+
+func (s *Snapshot) manifest() string {
+	return filepath.Join(SnapshotsPath(), s.Name, s.ID+".json")
+}
+
+// SnapshotData stores a compressed tarball of the eris root directory of
+// the do.Name data container or data volume, along with a manifest, under
+// SnapshotsPath. It sets do.Result to the snapshot ID.
+//
+//  do.Name - name of the data container or volume (required)
+//  do.Tag  - snapshot ID to use instead of the creation time; tagged
+//            snapshots are never pruned
+//  do.Keep - if not zero, prune the older untagged snapshots, keeping
+//            that many (see PruneSnapshots)
+//
+func SnapshotData(do *definitions.Do) error {
+	if !util.IsData(do.Name) && !util.IsDataVolume(do.Name) {
+		return fmt.Errorf("I cannot find that data container. Please check the data container name you sent me.")
+	}
+
+	now := time.Now().UTC()
+	snapshot := &Snapshot{
+		Name:    do.Name,
+		ID:      now.Format(snapshotTimeFormat),
+		Created: now,
+		Storage: definitions.StorageContainer,
+	}
+	if do.Tag != "" {
+		if !tagRegexp.MatchString(do.Tag) {
+			return fmt.Errorf("Snapshot tags can only contain letters, digits, dots, dashes, and underscores")
+		}
+		snapshot.ID, snapshot.Tagged = do.Tag, true
+	}
+	if _, err := os.Stat(snapshot.manifest()); err == nil {
+		return fmt.Errorf("The %s snapshot of %s exists", snapshot.ID, do.Name)
+	}
+
+	ops := loaders.LoadDataDefinition(do.Name)
+	if ops.DataVolumeName != "" {
+		snapshot.Storage = definitions.StorageVolume
+	} else if data, err := util.DockerClient.InspectContainer(ops.DataContainerName); err == nil {
+		snapshot.Image = data.Config.Image
+	}
+
+	// Record what the data was used with, as far as it's known.
+	if owner := perform.DataOwner(ops); owner != nil {
+		snapshot.Image = owner.Info.Config.Image
+		if owner.Type == definitions.TypeChain && owner.Info.State.Running {
+			if height, err := util.ChainHeight(owner.ShortName); err == nil {
+				snapshot.Height = &height
+			} else {
+				log.WithField("=>", owner.ShortName).Debugf("Cannot get chain height: %v", err)
+			}
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"=>": do.Name,
+		"id": snapshot.ID,
+	}).Info("Taking data snapshot")
+
+	if err := os.MkdirAll(filepath.Join(SnapshotsPath(), do.Name), 0755); err != nil {
+		return err
+	}
+	if err := writeArchive(ops, snapshot); err != nil {
+		return err
+	}
+
+	manifest, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(snapshot.manifest(), manifest, 0644); err != nil {
+		os.Remove(snapshot.Archive())
+		return err
+	}
+
+	if do.Keep > 0 {
+		if err := PruneSnapshots(do.Name, do.Keep); err != nil {
+			return err
+		}
+	}
+
+	do.Result = snapshot.ID
+	return nil
+}
+
+// writeArchive writes the snapshot tarball, setting its size and checksum.
+// The archive only appears under its name once it's complete.
+func writeArchive(ops *definitions.Operation, snapshot *Snapshot) error {
+	partial := snapshot.Archive() + ".partial"
+	file, err := os.Create(partial)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(partial)
+	defer file.Close()
+
+	hash := sha256.New()
+	counter := &countingWriter{}
+	gz := gzip.NewWriter(io.MultiWriter(file, hash, counter))
+	if err := perform.DockerSnapshotData(ops, gz); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	snapshot.Size = counter.n
+	snapshot.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return os.Rename(partial, snapshot.Archive())
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// ListSnapshots returns the snapshots of the named data, or of all data
+// if name is empty, oldest first.
+func ListSnapshots(name string) ([]*Snapshot, error) {
+	pattern := filepath.Join(SnapshotsPath(), name, "*.json")
+	if name == "" {
+		pattern = filepath.Join(SnapshotsPath(), "*", "*.json")
+	}
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []*Snapshot
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		snapshot := new(Snapshot)
+		if err := json.Unmarshal(b, snapshot); err != nil {
+			return nil, fmt.Errorf("Cannot read snapshot manifest %s: %v", file, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.Sort(byCreated(snapshots))
+	return snapshots, nil
+}
+
+// PrintSnapshots writes the snapshots of the do.Name data (of all data if
+// do.Name is empty) to the configured writer, as a table or, if do.JSON is
+// set, as a JSON document.
+func PrintSnapshots(do *definitions.Do) error {
+	snapshots, err := ListSnapshots(do.Name)
+	if err != nil {
+		return err
+	}
+
+	if do.JSON {
+		if snapshots == nil {
+			snapshots = []*Snapshot{}
+		}
+		b, err := json.Marshal(snapshots)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(config.GlobalConfig.Writer, string(b))
+		return nil
+	}
+
+	// 6 - minwidth, 1 - tabwidth (tab characters width), 5 - padding, ' ' - padchar, 0 - flags.
+	tw := tabwriter.NewWriter(config.GlobalConfig.Writer, 6, 1, 5, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSNAPSHOT\tCREATED\tSTORAGE\tHEIGHT\tSIZE")
+	for _, s := range snapshots {
+		height := ""
+		if s.Height != nil {
+			height = fmt.Sprint(*s.Height)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.Name,
+			s.ID,
+			s.Created.Local().Format("2006-01-02 15:04:05"),
+			s.Storage,
+			height,
+			units.HumanSize(float64(s.Size)),
+		)
+	}
+	return tw.Flush()
+}
+
+// RestoreData replaces the contents of the do.Name data with a snapshot,
+// after checking the snapshot archive against the manifest checksum. The
+// data container or volume is created if it doesn't exist. The service or
+// chain using the data is stopped for the time of the restore and then
+// started again (see perform.DockerRestoreData).
+//
+//  do.Name    - name of the data container or volume (required)
+//  do.Tag     - snapshot ID or tag to restore (required)
+//  do.Timeout - seconds to wait for the service or chain to stop
+//
+func RestoreData(do *definitions.Do) error {
+	snapshot, err := findSnapshot(do.Name, do.Tag)
+	if err != nil {
+		return err
+	}
+	if err := verifySnapshot(snapshot); err != nil {
+		return err
+	}
+
+	if !util.IsData(do.Name) && !util.IsDataVolume(do.Name) {
+		if err := createData(do.Name, nil); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.Open(snapshot.Archive())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	archive, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	log.WithFields(log.Fields{
+		"=>": do.Name,
+		"id": snapshot.ID,
+	}).Info("Restoring data snapshot")
+
+	if err := perform.DockerRestoreData(loaders.LoadDataDefinition(do.Name), archive, do.Timeout); err != nil {
+		return err
+	}
+
+	do.Result = "success"
+	return nil
+}
+
+func findSnapshot(name, id string) (*Snapshot, error) {
+	snapshots, err := ListSnapshots(name)
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.ID == id {
+			return snapshot, nil
+		}
+	}
+	return nil, fmt.Errorf("There is no %s snapshot of %s. Check [eris data snapshots ls %s]", id, name, name)
+}
+
+func verifySnapshot(snapshot *Snapshot) error {
+	file, err := os.Open(snapshot.Archive())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return err
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(sum, snapshot.SHA256) {
+		return fmt.Errorf("The %s snapshot of %s is corrupt: checksum %s, expected %s", snapshot.ID, snapshot.Name, sum, snapshot.SHA256)
+	}
+	return nil
+}
+
+// PruneSnapshots removes the oldest untagged snapshots of the named data,
+// keeping the newest keep of them. Tagged snapshots are not counted and
+// are never removed.
+func PruneSnapshots(name string, keep int) error {
+	snapshots, err := ListSnapshots(name)
+	if err != nil {
+		return err
+	}
+
+	var untagged []*Snapshot
+	for _, snapshot := range snapshots {
+		if !snapshot.Tagged {
+			untagged = append(untagged, snapshot)
+		}
+	}
+	if len(untagged) <= keep {
+		return nil
+	}
+
+	for _, snapshot := range untagged[:len(untagged)-keep] {
+		log.WithFields(log.Fields{
+			"=>": name,
+			"id": snapshot.ID,
+		}).Info("Pruning data snapshot")
+		if err := os.Remove(snapshot.Archive()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.Remove(snapshot.manifest()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type byCreated []*Snapshot
+
+func (s byCreated) Len() int      { return len(s) }
+func (s byCreated) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byCreated) Less(i, j int) bool {
+	if s[i].Name != s[j].Name {
+		return s[i].Name < s[j].Name
+	}
+	return s[i].Created.Before(s[j].Created)
+}
@@ -0,0 +1,156 @@
+package data
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/perform"
+	"github.com/eris-ltd/eris-cli/tests"
+	"github.com/eris-ltd/eris-cli/util"
+
+	"github.com/eris-ltd/common/go/common"
+)
+
+func TestSnapshotData(t *testing.T) {
+	server, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	root, done := tests.WithErisRoot(t)
+	defer done()
+
+	srv := startKeys(t)
+	keys := common.ErisContainerRoot + "/keys.json"
+	source := filepath.Join(root, "import")
+	os.MkdirAll(source, 0755)
+	ioutil.WriteFile(filepath.Join(source, "keys.json"), []byte("first"), 0644)
+
+	do := def.NowDo()
+	do.Name = "keys"
+	do.Source = source
+	do.Destination = common.ErisContainerRoot
+	if err := ImportData(do); err != nil {
+		t.Fatalf("expected import to succeed, got %v", err)
+	}
+
+	do = def.NowDo()
+	do.Name = "keys"
+	do.Tag = "first"
+	if err := SnapshotData(do); err != nil {
+		t.Fatalf("expected snapshot to succeed, got %v", err)
+	}
+	if err := SnapshotData(do); err == nil {
+		t.Fatalf("expected snapshot with a duplicate tag to fail")
+	}
+
+	ioutil.WriteFile(filepath.Join(source, "keys.json"), []byte("second"), 0644)
+	ioutil.WriteFile(filepath.Join(source, "stray"), []byte("stray"), 0644)
+	do = def.NowDo()
+	do.Name = "keys"
+	do.Source = source
+	do.Destination = common.ErisContainerRoot
+	if err := ImportData(do); err != nil {
+		t.Fatalf("expected import to succeed, got %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		do = def.NowDo()
+		do.Name = "keys"
+		do.Keep = 2
+		if err := SnapshotData(do); err != nil {
+			t.Fatalf("expected snapshot to succeed, got %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	snapshots, err := ListSnapshots("keys")
+	if err != nil {
+		t.Fatalf("expected snapshots to be listed, got %v", err)
+	}
+	if len(snapshots) != 3 || snapshots[0].ID != "first" || !snapshots[0].Tagged {
+		t.Fatalf("expected tagged snapshot and two untagged ones, got %v", snapshots)
+	}
+	if snapshots[0].Image != "quay.io/eris/keys" || snapshots[0].Storage != def.StorageContainer || snapshots[0].SHA256 == "" {
+		t.Fatalf("expected manifest details, got %+v", snapshots[0])
+	}
+
+	do = def.NowDo()
+	do.Name = "keys"
+	do.Tag = "first"
+	if err := RestoreData(do); err != nil {
+		t.Fatalf("expected restore to succeed, got %v", err)
+	}
+	if !util.IsService("keys", true) {
+		t.Fatalf("expected service to be running again after restore")
+	}
+	content, err := server.ReadFile(srv.Operations.SrvContainerName, keys)
+	if err != nil || string(content) != "first" {
+		t.Fatalf("expected restored file contents, got %q (%v)", content, err)
+	}
+	if _, err := server.ReadFile(srv.Operations.SrvContainerName, common.ErisContainerRoot+"/stray"); err == nil {
+		t.Fatalf("expected files added after the snapshot to be removed")
+	}
+
+	// A damaged archive is refused.
+	ioutil.WriteFile(snapshots[1].Archive(), []byte("garbage"), 0644)
+	do.Tag = snapshots[1].ID
+	if err := RestoreData(do); err == nil {
+		t.Fatalf("expected restore of a corrupt snapshot to fail")
+	}
+
+	if err := PruneSnapshots("keys", 0); err != nil {
+		t.Fatalf("expected prune to succeed, got %v", err)
+	}
+	if snapshots, _ := ListSnapshots(""); len(snapshots) != 1 || snapshots[0].ID != "first" {
+		t.Fatalf("expected only the tagged snapshot to be left, got %v", snapshots)
+	}
+}
+
+func TestSnapshotDataChainHeight(t *testing.T) {
+	server, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	_, done := tests.WithErisRoot(t)
+	defer done()
+
+	const chain = "test-height"
+	rpc := tests.ServeChainRPC(t, server, chain, map[string]string{
+		"status": `{"node_info": {"network": "` + chain + `"}, "latest_block_height": 42}`,
+	})
+	defer rpc.Close()
+	if err := perform.DockerCreateData(loaders.LoadDataDefinition(chain)); err != nil {
+		t.Fatalf("expected data container, got %v", err)
+	}
+
+	do := def.NowDo()
+	do.Name = chain
+	do.Tag = "running"
+	if err := SnapshotData(do); err != nil {
+		t.Fatalf("expected snapshot to succeed, got %v", err)
+	}
+	snapshots, err := ListSnapshots(chain)
+	if err != nil || len(snapshots) != 1 || snapshots[0].Height == nil || *snapshots[0].Height != 42 {
+		t.Fatalf("expected snapshot at the chain height, got %v (%v)", snapshots, err)
+	}
+}
+
+// startKeys runs the keys service along with its data container.
+func startKeys(t *testing.T) *def.ServiceDefinition {
+	srv := def.BlankServiceDefinition()
+	srv.Name = "keys"
+	srv.Service.Name = "keys"
+	srv.Service.Image = "quay.io/eris/keys"
+	srv.Service.AutoData = true
+	srv.Operations.ContainerType = def.TypeService
+	srv.Operations.SrvContainerName = util.ServiceContainerName("keys")
+	srv.Operations.DataContainerName = util.DataContainerName("keys")
+	srv.Operations.Labels = util.Labels("keys", srv.Operations)
+
+	if err := perform.DockerRunService(srv.Service, srv.Operations); err != nil {
+		t.Fatalf("expected service to start, got %v", err)
+	}
+	return srv
+}
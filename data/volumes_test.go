@@ -0,0 +1,140 @@
+package data
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/perform"
+	"github.com/eris-ltd/eris-cli/tests"
+	"github.com/eris-ltd/eris-cli/util"
+
+	"github.com/eris-ltd/common/go/common"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestDataVolume(t *testing.T) {
+	_, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	os.Setenv("ERIS_PULL_APPROVE", "true")
+	defer os.Unsetenv("ERIS_PULL_APPROVE")
+
+	dir, err := ioutil.TempDir("", "eris-data")
+	if err != nil {
+		t.Fatalf("expected temp dir, got %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	source := filepath.Join(dir, "import")
+	os.MkdirAll(source, 0755)
+	ioutil.WriteFile(filepath.Join(source, "file"), []byte("hello"), 0644)
+
+	do := def.NowDo()
+	do.Name = "fake"
+	do.Source = source
+	do.Destination = common.ErisContainerRoot
+	do.Service.Storage = def.StorageVolume
+	if err := ImportData(do); err != nil {
+		t.Fatalf("expected import to succeed, got %v", err)
+	}
+	if util.IsData("fake") || !util.IsDataVolume("fake") {
+		t.Fatalf("expected data volume rather than data container")
+	}
+
+	volumes, err := util.ErisVolumes()
+	if err != nil || len(volumes) != 1 || volumes[0].ShortName != "fake" || volumes[0].Type != def.TypeData {
+		t.Fatalf("expected data volume to be listed, got %v (%v)", volumes, err)
+	}
+
+	do = def.NowDo()
+	do.Name = "fake"
+	do.Operations.Args = []string{"cat", common.ErisContainerRoot + "/file"}
+	out, err := ExecData(do)
+	if err != nil {
+		t.Fatalf("expected exec to succeed, got %v", err)
+	}
+	if !strings.Contains(out.String(), "hello") {
+		t.Fatalf("expected file contents in exec output, got %q", out.String())
+	}
+
+	do = def.NowDo()
+	do.Name = "fake"
+	do.Source = common.ErisContainerRoot
+	do.Destination = filepath.Join(dir, "export")
+	if err := ExportData(do); err != nil {
+		t.Fatalf("expected export to succeed, got %v", err)
+	}
+	if content, err := ioutil.ReadFile(filepath.Join(dir, "export", "file")); string(content) != "hello" {
+		t.Fatalf("expected exported file contents to match, got %q (error %v)", content, err)
+	}
+
+	// No throwaway containers are left behind.
+	if list, _ := util.DockerClient.ListContainers(docker.ListContainersOptions{All: true}); len(list) != 0 {
+		t.Fatalf("expected no containers, got %v", list)
+	}
+
+	do = def.NowDo()
+	do.Operations.Args = []string{"fake"}
+	if err := RmData(do); err != nil {
+		t.Fatalf("expected data volume to be removed, got %v", err)
+	}
+	if util.IsDataVolume("fake") {
+		t.Fatalf("expected data volume to be removed")
+	}
+}
+
+func TestMigrateData(t *testing.T) {
+	server, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	os.Setenv("ERIS_PULL_APPROVE", "true")
+	defer os.Unsetenv("ERIS_PULL_APPROVE")
+
+	srv := startKeys(t)
+	srv.Operations.Args = []string{"touch", "/home/eris/.eris/keys.json"}
+	if _, err := perform.DockerExecService(srv.Service, srv.Operations); err != nil {
+		t.Fatalf("expected exec to succeed, got %v", err)
+	}
+
+	do := def.NowDo()
+	do.Name = "keys"
+	if err := MigrateData(do); err == nil {
+		t.Fatalf("expected migration of running service data to fail")
+	}
+
+	if err := perform.DockerStop(srv.Service, srv.Operations, 10); err != nil {
+		t.Fatalf("expected service to stop, got %v", err)
+	}
+	if err := MigrateData(do); err != nil {
+		t.Fatalf("expected migration to succeed, got %v", err)
+	}
+	if util.IsData("keys") || util.IsService("keys", false) || !util.IsDataVolume("keys") {
+		t.Fatalf("expected data and service containers to be replaced by a data volume")
+	}
+	if err := MigrateData(do); err == nil {
+		t.Fatalf("expected second migration to fail")
+	}
+
+	// The service is recreated with the volume even though it asks
+	// for nothing in particular.
+	if err := perform.DockerRunService(srv.Service, srv.Operations); err != nil {
+		t.Fatalf("expected service to start, got %v", err)
+	}
+	if _, err := server.ReadFile(srv.Operations.SrvContainerName, "/home/eris/.eris/keys.json"); err != nil {
+		t.Fatalf("expected migrated file in service container, got %v", err)
+	}
+	if util.IsData("keys") {
+		t.Fatalf("expected no data container to be created")
+	}
+
+	if err := perform.DockerRemove(srv.Service, srv.Operations, true, true, true); err != nil {
+		t.Fatalf("expected service to be removed, got %v", err)
+	}
+	if util.IsDataVolume("keys") {
+		t.Fatalf("expected data volume to be removed with the service")
+	}
+}
@@ -0,0 +1,12 @@
+package definitions
+
+type Build struct {
+	// directory sent to Docker as the build context (relative to the services directory)
+	Context string `json:"context,omitempty" yaml:"context,omitempty" toml:"context,omitempty"`
+	// path to the Dockerfile within the context (default "Dockerfile")
+	Dockerfile string `json:"dockerfile,omitempty" yaml:"dockerfile,omitempty" toml:"dockerfile,omitempty"`
+	// build-time variables, as with `docker build --build-arg`
+	Args map[string]string `json:"args,omitempty" yaml:"args,omitempty" toml:"args,omitempty"`
+	// name to tag the built image with (default is the service image)
+	Tag string `json:"tag,omitempty" yaml:"tag,omitempty" toml:"tag,omitempty"`
+}
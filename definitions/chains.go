@@ -7,6 +7,12 @@ type Chain struct {
 	ChainID string `mapstructure:"chain_id" json:"chain_id" yaml:"chain_id" toml:"chain_id"`
 	// type of the chain
 	ChainType string `mapstructure:"chain_type" json:"chain_type" yaml:"chain_type" toml:"chain_type"`
+	// validator nodes the chain is made of (local testnets)
+	Nodes *ChainNodes `mapstructure:"nodes" json:"nodes,omitempty" yaml:"nodes,omitempty" toml:"nodes,omitempty"`
+	// addresses (HOST:PORT) of the peers the chain nodes connect to
+	Seeds []string `mapstructure:"seeds" json:"seeds,omitempty" yaml:"seeds,omitempty" toml:"seeds,omitempty"`
+	// name of the chain the definition is a node of (set when loading a node)
+	NodeOf string `mapstructure:"-" json:"-" yaml:"-" toml:"-"`
 
 	// same fields as in the Service Struct/Service Specification
 	Service      *Service      `json:"service,omitempty" yaml:"service,omitempty" toml:"service,omitempty"`
@@ -17,6 +23,14 @@ type Chain struct {
 	Operations   *Operation
 }
 
+// ChainNodes describes a chain run as several validator nodes on the
+// local machine, each in a chain container of its own named NAME-0,
+// NAME-1, etc.
+type ChainNodes struct {
+	// number of validator nodes
+	Count int `mapstructure:"count" json:"count" yaml:"count" toml:"count"`
+}
+
 func BlankChain() *Chain {
 	return &Chain{
 		Service:    BlankService(),
@@ -7,6 +7,7 @@ const (
 	LabelShortName = Namespace + ":" + "NAME"
 	LabelType      = Namespace + ":" + "TYPE"
 	LabelService   = Namespace + ":" + "SERVICE"
+	LabelChain     = Namespace + ":" + "CHAIN"
 	LabelSwarm     = Namespace + ":" + "SWARM"
 	LabelMachine   = Namespace + ":" + "MACHINE"
 	LabelUser      = Namespace + ":" + "USER"
@@ -17,4 +18,7 @@ const (
 	TypeChain   = "chain"
 	TypeService = "service"
 	TypeData    = "data"
+
+	StorageContainer = "container"
+	StorageVolume    = "volume"
 )
@@ -4,14 +4,19 @@ type Do struct {
 	AddDir        bool     `mapstructure:"," json:"," yaml:"," toml:","`
 	Actions       bool     `mapstructure:"," json:"," yaml:"," toml:","`
 	Force         bool     `mapstructure:"," json:"," yaml:"," toml:","`
+	Cascade       bool     `mapstructure:"," json:"," yaml:"," toml:","`
 	File          bool     `mapstructure:"," json:"," yaml:"," toml:","`
 	Pull          bool     `mapstructure:"," json:"," yaml:"," toml:","`
+	Rebuild       bool     `mapstructure:"," json:"," yaml:"," toml:","`
 	Quiet         bool     `mapstructure:"," json:"," yaml:"," toml:","`
 	JSON          bool     `mapstructure:"," json:"," yaml:"," toml:","`
 	All           bool     `mapstructure:"," json:"," yaml:"," toml:","`
 	Follow        bool     `mapstructure:"," json:"," yaml:"," toml:","`
+	Watch         bool     `mapstructure:"," json:"," yaml:"," toml:","`
+	NoStream      bool     `mapstructure:"," json:"," yaml:"," toml:","`
 	Logrotate     bool     `mapstructure:"," json:"," yaml:"," toml:","`
 	Run           bool     `mapstructure:"," json:"," yaml:"," toml:","`
+	Restart       bool     `mapstructure:"," json:"," yaml:"," toml:","`
 	Rm            bool     `mapstructure:"," json:"," yaml:"," toml:","`
 	RmImage       bool     `mapstructure:"," json:"," yaml:"," toml:","`
 	RmD           bool     `mapstructure:"," json:"," yaml:"," toml:","`
@@ -30,11 +35,17 @@ type Do struct {
 	Lines         int      `mapstructure:"," json:"," yaml:"," toml:","` // XXX: for tail and logs
 	Timeout       uint     `mapstructure:"," json:"," yaml:"," toml:","`
 	N             uint     `mapstructure:"," json:"," yaml:"," toml:","`
+	Parallel      uint     `mapstructure:"," json:"," yaml:"," toml:","`
+	Nodes         int      `mapstructure:"," json:"," yaml:"," toml:","`
+	Amount        uint64   `mapstructure:"," json:"," yaml:"," toml:","`
 	Address       string   `mapstructure:"," json:"," yaml:"," toml:","`
 	Pubkey        string   `mapstructure:"," json:"," yaml:"," toml:","`
+	UnbondTo      string   `mapstructure:"," json:"," yaml:"," toml:","`
 	Type          string   `mapstructure:"," json:"," yaml:"," toml:","`
 	Task          string   `mapstructure:"," json:"," yaml:"," toml:","`
 	Tail          string   `mapstructure:"," json:"," yaml:"," toml:","`
+	Since         string   `mapstructure:"," json:"," yaml:"," toml:","`
+	Until         string   `mapstructure:"," json:"," yaml:"," toml:","`
 	ChainName     string   `mapstructure:"," json:"," yaml:"," toml:","`
 	ChainType     string   `mapstructure:"," json:"," yaml:"," toml:","`
 	GenesisFile   string   `mapstructure:"," json:"," yaml:"," toml:","`
@@ -50,6 +61,7 @@ type Do struct {
 	CSV           string   `mapstructure:"," json:"," yaml:"," toml:","`
 	NewName       string   `mapstructure:"," json:"," yaml:"," toml:","`
 	Format        string   `mapstructure:"," json:"," yaml:"," toml:","`
+	Profile       string   `mapstructure:"," json:"," yaml:"," toml:","`
 	Priv          string   `mapstructure:"," json:"," yaml:"," toml:","`
 	Volume        string   `mapstructure:"," json:"," yaml:"," toml:","`
 	EPMConfigFile string   `mapstructure:"," json:"," yaml:"," toml:","`
@@ -65,6 +77,7 @@ type Do struct {
 	ServicesSlice []string `mapstructure:"," json:"," yaml:"," toml:","`
 	ConfigOpts    []string `mapstructure:"," json:"," yaml:"," toml:","`
 	AccountTypes  []string `mapstructure:"," json:"," yaml:"," toml:","`
+	Settings      []string `mapstructure:"," json:"," yaml:"," toml:","`
 
 	// update 
 	Branch  string `mapstructure:"," json:"," yaml:"," toml:","`
@@ -83,6 +96,10 @@ type Do struct {
 	Source      string `mapstructure:"," json:"," yaml:"," toml:","`
 	Destination string `mapstructure:"," json:"," yaml:"," toml:","`
 
+	//data snapshots
+	Tag  string `mapstructure:"," json:"," yaml:"," toml:","`
+	Keep int    `mapstructure:"," json:"," yaml:"," toml:","`
+
 	//listing functions
 	Known     bool `mapstructure:"," json:"," yaml:"," toml:","`
 	Running   bool `mapstructure:"," json:"," yaml:"," toml:","`
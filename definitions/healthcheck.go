@@ -0,0 +1,31 @@
+package definitions
+
+const (
+	// Defaults used for health checks which omit timing settings.
+	HealthCheckInterval = 1  // seconds between probes
+	HealthCheckRetries  = 30 // probes before giving up
+	HealthCheckTimeout  = 5  // seconds a single probe may take
+)
+
+type HealthCheck struct {
+	// command to run inside the container; a zero exit status means ready
+	Command string `json:"command,omitempty" yaml:"command,omitempty" toml:"command,omitempty"`
+	// exposed container port to probe, e.g. "46657" or "46657/tcp"
+	Port string `json:"port,omitempty" yaml:"port,omitempty" toml:"port,omitempty"`
+	// if set together with port, probe with an HTTP GET instead of a TCP dial
+	Path string `json:"path,omitempty" yaml:"path,omitempty" toml:"path,omitempty"`
+	// seconds between probes
+	Interval int `json:"interval,omitempty,omitzero" yaml:"interval,omitempty" toml:"interval,omitempty,omitzero"`
+	// number of probes before giving up
+	Retries int `json:"retries,omitempty,omitzero" yaml:"retries,omitempty" toml:"retries,omitempty,omitzero"`
+	// seconds a single probe may take
+	Timeout int `json:"timeout,omitempty,omitzero" yaml:"timeout,omitempty" toml:"timeout,omitempty,omitzero"`
+}
+
+func BlankHealthCheck() *HealthCheck {
+	return &HealthCheck{
+		Interval: HealthCheckInterval,
+		Retries:  HealthCheckRetries,
+		Timeout:  HealthCheckTimeout,
+	}
+}
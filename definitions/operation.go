@@ -6,12 +6,15 @@ type Operation struct {
 	SrvContainerID    string            `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
 	DataContainerName string            `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
 	DataContainerID   string            `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
+	DataVolumeName    string            `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
 	ContainerType     string            `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
 	Remove            bool              `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
 	Privileged        bool              `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
 	Interactive       bool              `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
+	Attach            bool              `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
 	Follow            bool              `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
 	SkipCheck         bool              `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
+	Wait              bool              `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
 	AppName           string            `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
 	DockerHostConn    string            `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
 	Volume            string            `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
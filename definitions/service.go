@@ -7,6 +7,8 @@ type Service struct {
 	Image string `json:"image,omitempty" yaml:"image,omitempty" toml:"image,omitempty"`
 	// whether eris should automagically handle a data container for this service
 	AutoData bool `json:"data_container" yaml:"data_container" toml:"data_container"`
+	// where the data is kept: "container" (a data container, the default) or "volume" (a named docker volume)
+	Storage string `mapstructure:"storage" json:"storage,omitempty" yaml:"storage,omitempty" toml:"storage,omitempty"`
 	// restart policy: "always" or "max:<#attempts>"
 	Restart string `json:",omitempty" yaml:",omitempty" toml:",omitempty"`
 	// maps directly to docker cmd
@@ -27,6 +29,11 @@ type Service struct {
 	EnvFile []string `mapstructure:"env_file" json:"env_file,omitempty" yaml:"env_file,omitempty" toml:"env_file,omitempty"`
 	// maps directly to docker net
 	Net string `json:"net,omitempty" yaml:"net,omitempty" toml:"net,omitempty"`
+	// user-defined docker networks to attach to (created if missing); links
+	// become network-scoped once the container is attached to a network
+	Networks []string `mapstructure:"networks" json:"networks,omitempty" yaml:"networks,omitempty" toml:"networks,omitempty"`
+	// names the container answers to on its networks (besides the service name)
+	Aliases []string `mapstructure:"aliases" json:"aliases,omitempty" yaml:"aliases,omitempty" toml:"aliases,omitempty"`
 	// maps directly to docker PID
 	PID string `json:"pid,omitempty" yaml:"pid,omitempty" toml:"pid,omitempty"`
 	// maps directly to docker DNS
@@ -47,6 +54,40 @@ type Service struct {
 	CPUShares int64 `mapstructure:"cpu_shares" json:"cpu_shares,omitempty,omitzero" yaml:"cpu_shares,omitempty" toml:"cpu_shares,omitempty,omitzero"`
 	// maps directly to docker mem_limit
 	MemLimit int64 `mapstructure:"mem_limit" json:"memory,omitempty,omitzero" yaml:"memory,omitempty" toml:"memory,omitempty,omitzero"`
+	// maps directly to docker memory-swap (memory plus swap, in bytes; -1 for unlimited swap)
+	MemSwap int64 `mapstructure:"memswap_limit" json:"memswap_limit,omitempty,omitzero" yaml:"memswap_limit,omitempty" toml:"memswap_limit,omitempty,omitzero"`
+	// maps directly to docker memory-reservation (soft memory limit, in bytes)
+	MemReservation int64 `mapstructure:"mem_reservation" json:"mem_reservation,omitempty,omitzero" yaml:"mem_reservation,omitempty" toml:"mem_reservation,omitempty,omitzero"`
+	// maps directly to docker cpu-quota (microseconds of CPU time per cpu_period)
+	CPUQuota int64 `mapstructure:"cpu_quota" json:"cpu_quota,omitempty,omitzero" yaml:"cpu_quota,omitempty" toml:"cpu_quota,omitempty,omitzero"`
+	// maps directly to docker cpu-period (microseconds)
+	CPUPeriod int64 `mapstructure:"cpu_period" json:"cpu_period,omitempty,omitzero" yaml:"cpu_period,omitempty" toml:"cpu_period,omitempty,omitzero"`
+	// maps directly to docker cpuset-cpus, e.g. "0-2" or "1,3"
+	CPUSet string `mapstructure:"cpuset" json:"cpuset,omitempty" yaml:"cpuset,omitempty" toml:"cpuset,omitempty"`
+	// maps directly to docker pids-limit
+	PidsLimit int64 `mapstructure:"pids_limit" json:"pids_limit,omitempty,omitzero" yaml:"pids_limit,omitempty" toml:"pids_limit,omitempty,omitzero"`
+	// maps directly to docker ulimit, e.g. "nofile=1024:2048"
+	Ulimits []string `mapstructure:"ulimits" json:"ulimits,omitempty" yaml:"ulimits,omitempty" toml:"ulimits,omitempty"`
+	// maps directly to docker tmpfs, e.g. "/run:rw,size=64m"
+	Tmpfs []string `mapstructure:"tmpfs" json:"tmpfs,omitempty" yaml:"tmpfs,omitempty" toml:"tmpfs,omitempty"`
+	// maps directly to docker read-only
+	ReadOnly bool `mapstructure:"read_only" json:"read_only,omitempty" yaml:"read_only,omitempty" toml:"read_only,omitempty"`
+	// maps directly to docker security-opt, e.g. "seccomp=/path/profile.json" or "apparmor=eris"
+	SecurityOpt []string `mapstructure:"security_opt" json:"security_opt,omitempty" yaml:"security_opt,omitempty" toml:"security_opt,omitempty"`
+	// maps directly to docker oom-score-adj
+	OOMScoreAdj int `mapstructure:"oom_score_adj" json:"oom_score_adj,omitempty,omitzero" yaml:"oom_score_adj,omitempty" toml:"oom_score_adj,omitempty,omitzero"`
+	// maps directly to docker shm-size (in bytes)
+	ShmSize int64 `mapstructure:"shm_size" json:"shm_size,omitempty,omitzero" yaml:"shm_size,omitempty" toml:"shm_size,omitempty,omitzero"`
+	// maps directly to docker log-driver, e.g. "json-file", "syslog" or "journald"
+	LogDriver string `mapstructure:"log_driver" json:"log_driver,omitempty" yaml:"log_driver,omitempty" toml:"log_driver,omitempty"`
+	// maps directly to docker log-opt, e.g. max-size = "10m" for the json-file driver
+	LogOpts map[string]string `mapstructure:"log_opts" json:"log_opts,omitempty" yaml:"log_opts,omitempty" toml:"log_opts,omitempty"`
+
+	// how to build the image locally rather than pull it
+	Build *Build `mapstructure:"build" json:"build,omitempty" yaml:"build,omitempty" toml:"build,omitempty"`
+
+	// how to tell the service is ready to be used by its dependents
+	HealthCheck *HealthCheck `mapstructure:"healthcheck" json:"healthcheck,omitempty" yaml:"healthcheck,omitempty" toml:"healthcheck,omitempty"`
 
 	// an env variable to set for when we are running `eris exec` so we can find the main container
 	ExecHost string `mapstructure:"exec_host" json:"memory,omitempty,omitzero" yaml:"memory,omitempty" toml:"memory,omitempty,omitzero"`
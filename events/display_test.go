@@ -0,0 +1,77 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/perform"
+	"github.com/eris-ltd/eris-cli/tests"
+	"github.com/eris-ltd/eris-cli/util"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestDisplayErisEvents(t *testing.T) {
+	server, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	server.AddImage("quay.io/eris/keys")
+
+	since := strconv.FormatInt(time.Now().Unix(), 10)
+
+	srv := def.BlankServiceDefinition()
+	srv.Name = "keys"
+	srv.Service.Name = "keys"
+	srv.Service.Image = "quay.io/eris/keys"
+	srv.Operations.ContainerType = def.TypeService
+	srv.Operations.SrvContainerName = util.ServiceContainerName("keys")
+	srv.Operations.Labels = util.Labels("keys", srv.Operations)
+	if err := perform.DockerRunService(srv.Service, srv.Operations); err != nil {
+		t.Fatalf("expected service to start, got %v", err)
+	}
+	if err := server.ExitContainer(srv.Operations.SrvContainerName, 1); err != nil {
+		t.Fatalf("expected service to exit, got %v", err)
+	}
+
+	// Not an eris container.
+	if _, err := util.DockerClient.CreateContainer(docker.CreateContainerOptions{
+		Name:   "other",
+		Config: &docker.Config{Image: "quay.io/eris/keys"},
+	}); err != nil {
+		t.Fatalf("expected container to be created, got %v", err)
+	}
+
+	out := new(bytes.Buffer)
+	if err := Display(out, def.TypeService, since, "0s", "json", nil); err != nil {
+		t.Fatalf("expected events, got %v", err)
+	}
+
+	var actions []string
+	decoder := json.NewDecoder(out)
+	for decoder.More() {
+		var event Event
+		if err := decoder.Decode(&event); err != nil {
+			t.Fatalf("expected JSON events, got %v", err)
+		}
+		if event.ShortName != "keys" || event.Type != def.TypeService || event.FullName != srv.Operations.SrvContainerName {
+			t.Fatalf("expected keys service event, got %+v", event)
+		}
+		if event.Action == "die" && (event.ExitCode == nil || *event.ExitCode != 1) {
+			t.Fatalf("expected exit code 1, got %v", event.ExitCode)
+		}
+		actions = append(actions, event.Action)
+	}
+	if strings.Join(actions, " ") != "create start die" {
+		t.Fatalf("expected create, start and die events, got %v", actions)
+	}
+
+	out.Reset()
+	if err := Display(out, def.TypeChain, since, "0s", "", nil); err != nil || out.Len() != 0 {
+		t.Fatalf("expected no chain events, got %q (error %v)", out.String(), err)
+	}
+}
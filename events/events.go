@@ -0,0 +1,177 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/util"
+
+	log "github.com/Sirupsen/logrus"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// Event is a lifecycle event of an Eris container.
+type Event struct {
+	Time      time.Time `json:"time"`
+	Type      string    `json:"type"`
+	ShortName string    `json:"name"`
+	FullName  string    `json:"container"`
+	Action    string    `json:"action"`
+
+	// ExitCode is set for "die" events.
+	ExitCode *int `json:"exit_code,omitempty"`
+}
+
+// Display writes the lifecycle events of Eris containers to w, one per
+// line, either human readable or as JSON documents if format is "json".
+//
+//  t      - container type to show events for (def.TypeChain,
+//           def.TypeService, def.TypeData), all types if empty
+//  since  - show past events from this time on (see ParseTime)
+//  until  - stop when this time passes (see ParseTime)
+//  done   - stop when signaled (may be nil)
+//
+// Without until, Display keeps streaming events until done is signaled
+// or the Docker daemon closes the connection.
+func Display(w io.Writer, t, since, until, format string, done <-chan bool) error {
+	log.WithFields(log.Fields{
+		"type":  t,
+		"since": since,
+		"until": until,
+	}).Debug("Streaming events")
+
+	opts, err := options(t, since, until, time.Now())
+	if err != nil {
+		return err
+	}
+
+	ch := make(chan *docker.APIEvents)
+	opts.Events, opts.Done = ch, done
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- util.DockerClient.Events(opts)
+	}()
+
+	for event := range ch {
+		e := Translate(event)
+		if e == nil || (t != "" && e.Type != t) {
+			continue
+		}
+		if err := write(w, e, format); err != nil {
+			return err
+		}
+	}
+
+	if err := <-errs; err != nil {
+		return util.DockerError(err)
+	}
+	return nil
+}
+
+// Translate converts a Docker event into an Eris one, with the full
+// container name translated into the short name and type. Translate
+// returns nil if the event is not about an Eris container.
+func Translate(event *docker.APIEvents) *Event {
+	details := util.EventDetails(event)
+	if details == nil {
+		return nil
+	}
+
+	e := &Event{
+		Type:      details.Type,
+		ShortName: details.ShortName,
+		FullName:  details.FullName,
+		Action:    event.Action,
+	}
+	if e.Action == "" {
+		e.Action = event.Status
+	}
+
+	if event.TimeNano != 0 {
+		e.Time = time.Unix(0, event.TimeNano)
+	} else {
+		e.Time = time.Unix(event.Time, 0)
+	}
+
+	if e.Action == "die" {
+		if code, err := strconv.Atoi(event.Actor.Attributes["exitCode"]); err == nil {
+			e.ExitCode = &code
+		}
+	}
+	return e
+}
+
+// ParseTime converts the --since and --until flag values into the Docker
+// API timestamp format. A value can be an RFC 3339 date and time
+// (2016-01-02T15:04:05Z), a Unix timestamp, or a duration (10m, 1h30m)
+// meaning that long before now. An empty value is returned as is.
+func ParseTime(value string, now time.Time) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return timestamp(t), nil
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return timestamp(now.Add(-d)), nil
+	}
+
+	return "", fmt.Errorf("Cannot parse time %q. Use a date and time (2016-01-02T15:04:05Z), a Unix timestamp, or a duration (10m)", value)
+}
+
+func options(t, since, until string, now time.Time) (opts docker.EventsOptions, err error) {
+	switch t {
+	case "", def.TypeChain, def.TypeService, def.TypeData:
+	default:
+		return opts, fmt.Errorf("Don't know the type %q to show events for. Use %s, %s, or %s", t, def.TypeChain, def.TypeService, def.TypeData)
+	}
+
+	if opts.Since, err = ParseTime(since, now); err != nil {
+		return opts, err
+	}
+	if opts.Until, err = ParseTime(until, now); err != nil {
+		return opts, err
+	}
+
+	label := def.LabelEris
+	if t != "" {
+		label = def.LabelType + "=" + t
+	}
+	opts.Filters = map[string][]string{
+		"type":  {"container"},
+		"label": {label},
+	}
+	return opts, nil
+}
+
+func timestamp(t time.Time) string {
+	return fmt.Sprintf("%d.%09d", t.Unix(), t.Nanosecond())
+}
+
+func write(w io.Writer, e *Event, format string) error {
+	if format == "json" {
+		b, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+
+	line := []string{e.Time.Format(time.RFC3339), e.Type, e.ShortName, e.Action}
+	if e.ExitCode != nil {
+		line = append(line, fmt.Sprintf("(exit code %d)", *e.ExitCode))
+	}
+	_, err := fmt.Fprintln(w, strings.Join(line, " "))
+	return err
+}
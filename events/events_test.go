@@ -0,0 +1,85 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+var parseTimeTests = []struct {
+	value    string
+	expected string
+	err      bool
+}{
+	{"", "", false},
+	{"1451747045", "1451747045", false},
+	{"1451747045.5", "1451747045.5", false},
+	{"2016-01-02T15:04:05Z", "1451747045.000000000", false},
+	{"10m", "1451746445.000000000", false},
+	{"yesterday", "", true},
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Unix(1451747045, 0)
+	for _, test := range parseTimeTests {
+		timestamp, err := ParseTime(test.value, now)
+		if (err != nil) != test.err {
+			t.Fatalf("expected error=%v for %q, got %v", test.err, test.value, err)
+		}
+		if timestamp != test.expected {
+			t.Fatalf("expected %q for %q, got %q", test.expected, test.value, timestamp)
+		}
+	}
+}
+
+func TestOptions(t *testing.T) {
+	opts, err := options(def.TypeChain, "", "", time.Now())
+	if err != nil {
+		t.Fatalf("expected options, got %v", err)
+	}
+	if label := opts.Filters["label"]; len(label) != 1 || label[0] != def.LabelType+"="+def.TypeChain {
+		t.Fatalf("expected chain label filter, got %v", opts.Filters)
+	}
+
+	if _, err := options("volume", "", "", time.Now()); err == nil {
+		t.Fatalf("expected unknown type error, got nil")
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	event := Translate(&docker.APIEvents{
+		Action: "die",
+		Type:   "container",
+		Actor: docker.APIActor{
+			ID: "0123456789ab",
+			Attributes: map[string]string{
+				"name":             "eris_chain_simplechain_1",
+				"exitCode":         "137",
+				"image":            "quay.io/eris/db",
+				def.LabelEris:      "true",
+				def.LabelShortName: "simplechain",
+				def.LabelType:      def.TypeChain,
+			},
+		},
+		TimeNano: 1451747045000000000,
+	})
+	if event == nil {
+		t.Fatalf("expected event, got nil")
+	}
+	if event.ShortName != "simplechain" || event.Type != def.TypeChain || event.FullName != "eris_chain_simplechain_1" {
+		t.Fatalf("expected simplechain chain event, got %+v", event)
+	}
+	if event.ExitCode == nil || *event.ExitCode != 137 {
+		t.Fatalf("expected exit code 137, got %v", event.ExitCode)
+	}
+	if !event.Time.Equal(time.Unix(1451747045, 0)) {
+		t.Fatalf("expected event time, got %v", event.Time)
+	}
+
+	if event := Translate(&docker.APIEvents{Action: "pull", Type: "image"}); event != nil {
+		t.Fatalf("expected image events to be skipped, got %+v", event)
+	}
+}
@@ -0,0 +1,124 @@
+package genesis
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Diff returns the differences between two genesis files, one per line,
+// prefixed with "-" for what's only in a, "+" for what's only in b, and
+// "~" for what's changed. Accounts are matched by address and validators
+// by public key, so reordering them is not a difference.
+func Diff(a, b *Genesis) []string {
+	var diff []string
+	changed := func(what string, x, y interface{}) {
+		diff = append(diff, fmt.Sprintf("~ %s: %v -> %v", what, x, y))
+	}
+
+	if a.ChainID != b.ChainID {
+		changed("chain_id", a.ChainID, b.ChainID)
+	}
+	if a.GenesisTime != b.GenesisTime {
+		changed("genesis_time", a.GenesisTime, b.GenesisTime)
+	}
+	if pa, pb := compact(a.Params), compact(b.Params); pa != pb {
+		changed("params", pa, pb)
+	}
+
+	accountsA, orderA := accountsByAddress(a)
+	accountsB, orderB := accountsByAddress(b)
+	for _, address := range union(orderA, orderB) {
+		x, y := accountsA[address], accountsB[address]
+		switch {
+		case y == nil:
+			diff = append(diff, fmt.Sprintf("- account %s", address))
+		case x == nil:
+			diff = append(diff, fmt.Sprintf("+ account %s", address))
+		default:
+			what := "account " + address
+			if x.Amount != y.Amount {
+				changed(what+" amount", x.Amount, y.Amount)
+			}
+			if x.Name != y.Name {
+				changed(what+" name", x.Name, y.Name)
+			}
+			if px, py := compact(x.Permissions), compact(y.Permissions); px != py {
+				changed(what+" permissions", px, py)
+			}
+		}
+	}
+
+	validatorsA, orderA := validatorsByPubKey(a)
+	validatorsB, orderB := validatorsByPubKey(b)
+	for _, key := range union(orderA, orderB) {
+		x, y := validatorsA[key], validatorsB[key]
+		switch {
+		case y == nil:
+			diff = append(diff, fmt.Sprintf("- validator %s", key))
+		case x == nil:
+			diff = append(diff, fmt.Sprintf("+ validator %s", key))
+		default:
+			what := "validator " + key
+			if x.Amount != y.Amount {
+				changed(what+" amount", x.Amount, y.Amount)
+			}
+			if x.Name != y.Name {
+				changed(what+" name", x.Name, y.Name)
+			}
+			if ux, uy := marshal(x.UnbondTo), marshal(y.UnbondTo); ux != uy {
+				changed(what+" unbond_to", ux, uy)
+			}
+		}
+	}
+
+	return diff
+}
+
+// accountsByAddress returns the accounts of g by their address, and the
+// addresses in the order of the file.
+func accountsByAddress(g *Genesis) (map[string]*Account, []string) {
+	m := make(map[string]*Account)
+	var order []string
+	for _, account := range g.Accounts {
+		address := strings.ToUpper(account.Address)
+		m[address] = account
+		order = append(order, address)
+	}
+	return m, order
+}
+
+// validatorsByPubKey returns the validators of g by their public key, and
+// the public keys in the order of the file.
+func validatorsByPubKey(g *Genesis) (map[string]*Validator, []string) {
+	m := make(map[string]*Validator)
+	var order []string
+	for _, validator := range g.Validators {
+		key := strings.ToUpper(validator.PubKey.Data)
+		m[key] = validator
+		order = append(order, key)
+	}
+	return m, order
+}
+
+// union returns the keys in a followed by those in b but not in a.
+func union(a, b []string) []string {
+	seen := make(map[string]bool)
+	var list []string
+	for _, k := range append(a, b...) {
+		if !seen[k] {
+			seen[k] = true
+			list = append(list, k)
+		}
+	}
+	return list
+}
+
+func marshal(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(bytes.TrimSpace(b))
+}
@@ -0,0 +1,277 @@
+package genesis
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	// PubKeyTypeEd25519 is the type byte of ed25519 public keys.
+	PubKeyTypeEd25519 = 1
+
+	addressLength = 20 // bytes
+	ed25519Length = 32 // bytes
+)
+
+// Genesis is the genesis.json of an eris:db chain.
+type Genesis struct {
+	GenesisTime string           `json:"genesis_time,omitempty"`
+	ChainID     string           `json:"chain_id"`
+	Params      *json.RawMessage `json:"params,omitempty"`
+	Accounts    []*Account       `json:"accounts"`
+	Validators  []*Validator     `json:"validators"`
+}
+
+// Account is an account funded at the genesis.
+type Account struct {
+	Address     string           `json:"address"`
+	Amount      int64            `json:"amount"`
+	Name        string           `json:"name,omitempty"`
+	Permissions *json.RawMessage `json:"permissions,omitempty"`
+}
+
+// Validator is a validator bonded at the genesis.
+type Validator struct {
+	PubKey   PubKey      `json:"pub_key"`
+	Amount   int64       `json:"amount"`
+	Name     string      `json:"name,omitempty"`
+	UnbondTo []*UnbondTo `json:"unbond_to"`
+}
+
+// UnbondTo is where a validator bond goes when the validator unbonds.
+type UnbondTo struct {
+	Address string `json:"address"`
+	Amount  int64  `json:"amount"`
+}
+
+// PubKey is a public key encoded as a [type, "hex"] pair.
+type PubKey struct {
+	Type byte
+	Data string
+}
+
+func (k PubKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]interface{}{k.Type, k.Data})
+}
+
+func (k *PubKey) UnmarshalJSON(b []byte) error {
+	var pair []json.RawMessage
+	if err := json.Unmarshal(b, &pair); err != nil || len(pair) != 2 {
+		return fmt.Errorf("public key should be a [type, \"hex\"] pair, got %s", b)
+	}
+	if err := json.Unmarshal(pair[0], &k.Type); err != nil {
+		return fmt.Errorf("public key type should be a byte, got %s", pair[0])
+	}
+	if err := json.Unmarshal(pair[1], &k.Data); err != nil {
+		return fmt.Errorf("public key should be a hex string, got %s", pair[1])
+	}
+	return nil
+}
+
+func (k PubKey) String() string {
+	return k.Data
+}
+
+// fields are the known top-level fields of a genesis file.
+var fields = map[string]bool{
+	"genesis_time": true,
+	"chain_id":     true,
+	"params":       true,
+	"accounts":     true,
+	"validators":   true,
+}
+
+// Load reads and parses a genesis file. It only fails if the file cannot
+// be read or is not a genesis file at all; see Validate for the rest.
+func Load(file string) (*Genesis, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	g, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+	return g, nil
+}
+
+// Parse parses the contents of a genesis file.
+func Parse(b []byte) (*Genesis, error) {
+	g := new(Genesis)
+	if err := json.Unmarshal(b, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Validate checks the genesis file contents against the genesis schema
+// and returns the problems found, if any: unknown fields, a missing chain
+// ID, malformed addresses and public keys, amounts which are not positive,
+// and duplicate accounts or validators.
+func Validate(b []byte) []error {
+	var (
+		errs []error
+		raw  map[string]json.RawMessage
+	)
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return []error{err}
+	}
+	var unknown []string
+	for field := range raw {
+		if !fields[field] {
+			unknown = append(unknown, field)
+		}
+	}
+	sort.Strings(unknown)
+	for _, field := range unknown {
+		errs = append(errs, fmt.Errorf("unknown field %q", field))
+	}
+
+	g, err := Parse(b)
+	if err != nil {
+		return append(errs, err)
+	}
+	return append(errs, g.Validate()...)
+}
+
+// Validate checks the parsed genesis. See the Validate function.
+func (g *Genesis) Validate() []error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if strings.TrimSpace(g.ChainID) == "" {
+		fail("chain_id is empty")
+	}
+
+	addresses := make(map[string]int)
+	for i, account := range g.Accounts {
+		what := fmt.Sprintf("accounts[%d]", i)
+		if err := checkAddress(account.Address); err != nil {
+			fail("%s: %v", what, err)
+		} else if j, ok := addresses[strings.ToUpper(account.Address)]; ok {
+			fail("%s: address %s is a duplicate of accounts[%d]", what, account.Address, j)
+		} else {
+			addresses[strings.ToUpper(account.Address)] = i
+		}
+		if account.Amount < 0 {
+			fail("%s: amount %d is negative", what, account.Amount)
+		}
+	}
+
+	if len(g.Validators) == 0 {
+		fail("there are no validators")
+	}
+	keys := make(map[string]int)
+	for i, validator := range g.Validators {
+		what := fmt.Sprintf("validators[%d]", i)
+		if err := checkPubKey(validator.PubKey); err != nil {
+			fail("%s: %v", what, err)
+		} else if j, ok := keys[strings.ToUpper(validator.PubKey.Data)]; ok {
+			fail("%s: public key is a duplicate of validators[%d]", what, j)
+		} else {
+			keys[strings.ToUpper(validator.PubKey.Data)] = i
+		}
+		if validator.Amount <= 0 {
+			fail("%s: amount %d is not positive", what, validator.Amount)
+		}
+		if len(validator.UnbondTo) == 0 {
+			fail("%s: unbond_to is empty", what)
+		}
+		for j, unbond := range validator.UnbondTo {
+			if err := checkAddress(unbond.Address); err != nil {
+				fail("%s.unbond_to[%d]: %v", what, j, err)
+			}
+			if unbond.Amount <= 0 {
+				fail("%s.unbond_to[%d]: amount %d is not positive", what, j, unbond.Amount)
+			}
+		}
+	}
+
+	return errs
+}
+
+func checkAddress(address string) error {
+	b, err := hex.DecodeString(address)
+	if err != nil {
+		return fmt.Errorf("address %q is not hex", address)
+	}
+	if len(b) != addressLength {
+		return fmt.Errorf("address %s is %d bytes long, expected %d", address, len(b), addressLength)
+	}
+	return nil
+}
+
+func checkPubKey(key PubKey) error {
+	if key.Type != PubKeyTypeEd25519 {
+		return fmt.Errorf("public key type %d is unknown, expected %d (ed25519)", key.Type, PubKeyTypeEd25519)
+	}
+	b, err := hex.DecodeString(key.Data)
+	if err != nil {
+		return fmt.Errorf("public key %q is not hex", key.Data)
+	}
+	if len(b) != ed25519Length {
+		return fmt.Errorf("public key %s is %d bytes long, expected %d", key.Data, len(b), ed25519Length)
+	}
+	return nil
+}
+
+// Show writes the genesis accounts and validators to w, as tables or as
+// a JSON document if format is "json".
+func Show(w io.Writer, g *Genesis, format string) error {
+	if format == "json" {
+		b, err := json.MarshalIndent(g, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "CHAIN ID\t%s\n", g.ChainID)
+	if g.GenesisTime != "" {
+		fmt.Fprintf(buf, "GENESIS TIME\t%s\n", g.GenesisTime)
+	}
+	buf.WriteString("\t\t\t\n")
+
+	fmt.Fprintln(buf, "ACCOUNT\tAMOUNT\tNAME\tPERMISSIONS")
+	for _, account := range g.Accounts {
+		fmt.Fprintf(buf, "%s\t%d\t%s\t%s\n", account.Address, account.Amount, account.Name, compact(account.Permissions))
+	}
+	buf.WriteString("\t\t\t\n")
+
+	fmt.Fprintln(buf, "VALIDATOR\tAMOUNT\tNAME\tUNBOND TO")
+	for _, validator := range g.Validators {
+		var unbond []string
+		for _, u := range validator.UnbondTo {
+			unbond = append(unbond, fmt.Sprintf("%s:%d", u.Address, u.Amount))
+		}
+		fmt.Fprintf(buf, "%s\t%d\t%s\t%s\n", validator.PubKey, validator.Amount, validator.Name, strings.Join(unbond, ","))
+	}
+
+	// 6 - minwidth, 1 - tabwidth (tab characters width), 5 - padding, ' ' - padchar, 0 - flags.
+	tw := tabwriter.NewWriter(w, 6, 1, 5, ' ', 0)
+	buf.WriteTo(tw)
+	return tw.Flush()
+}
+
+// compact returns raw JSON without insignificant spaces, or "" if it's nil.
+func compact(raw *json.RawMessage) string {
+	if raw == nil {
+		return ""
+	}
+	buf := new(bytes.Buffer)
+	if err := json.Compact(buf, *raw); err != nil {
+		return string(*raw)
+	}
+	return buf.String()
+}
@@ -0,0 +1,228 @@
+package genesis
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const (
+	address1 = "6D1A4E9FA2AD3F8B3E0A37E14D3F08D2AFE7A34B"
+	address2 = "A0F9E1C2D3B4A5968778695A4B3C2D1E0F1A2B3C"
+	pubKey1  = "F6C79CF0CB9D66B677988BCB9B8EADD9A091CD465A60542A8AB85476256DBA92"
+	pubKey2  = "0A1B2C3D4E5F60718293A4B5C6D7E8F90A1B2C3D4E5F60718293A4B5C6D7E8F9"
+)
+
+var validGenesis = `{
+  "chain_id": "simplechain",
+  "accounts": [
+    {"address": "` + address1 + `", "amount": 9999999, "name": "root"},
+    {"address": "` + address2 + `", "amount": 0}
+  ],
+  "validators": [
+    {
+      "pub_key": [1, "` + pubKey1 + `"],
+      "amount": 10,
+      "name": "val",
+      "unbond_to": [{"address": "` + address1 + `", "amount": 10}]
+    }
+  ]
+}`
+
+var validateTests = []struct {
+	name     string
+	genesis  string
+	expected []string
+}{
+	{"valid", validGenesis, nil},
+	{
+		"unknown field",
+		strings.Replace(validGenesis, `"chain_id"`, `"chainid": "x", "chain_id"`, 1),
+		[]string{`unknown field "chainid"`},
+	},
+	{
+		"empty chain id",
+		strings.Replace(validGenesis, `"simplechain"`, `""`, 1),
+		[]string{"chain_id is empty"},
+	},
+	{
+		"short address",
+		strings.Replace(validGenesis, address2, "A0F9", 1),
+		[]string{"accounts[1]: address A0F9 is 2 bytes long, expected 20"},
+	},
+	{
+		"duplicate address",
+		strings.Replace(validGenesis, address2, strings.ToLower(address1), 1),
+		[]string{"accounts[1]: address " + strings.ToLower(address1) + " is a duplicate of accounts[0]"},
+	},
+	{
+		"negative amount",
+		strings.Replace(validGenesis, `"amount": 0`, `"amount": -1`, 1),
+		[]string{"accounts[1]: amount -1 is negative"},
+	},
+	{
+		"pubkey type",
+		strings.Replace(validGenesis, `[1, "`, `[2, "`, 1),
+		[]string{"validators[0]: public key type 2 is unknown, expected 1 (ed25519)"},
+	},
+	{
+		"pubkey encoding",
+		strings.Replace(validGenesis, pubKey1, "not hex", 1),
+		[]string{`validators[0]: public key "not hex" is not hex`},
+	},
+	{
+		"unbond to",
+		strings.Replace(validGenesis, `"unbond_to": [{"address": "`+address1+`", "amount": 10}]`, `"unbond_to": []`, 1),
+		[]string{"validators[0]: unbond_to is empty"},
+	},
+	{
+		"no validators",
+		`{"chain_id": "simplechain", "accounts": [], "validators": []}`,
+		[]string{"there are no validators"},
+	},
+	{
+		"not a genesis",
+		`{"chain_id": "simplechain", "validators": [{"pub_key": "x"}]}`,
+		[]string{`public key should be a [type, "hex"] pair, got "x"`},
+	},
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range validateTests {
+		errs := Validate([]byte(test.genesis))
+		if len(errs) != len(test.expected) {
+			t.Fatalf("%s: expected %d errors, got %v", test.name, len(test.expected), errs)
+		}
+		for i, err := range errs {
+			if err.Error() != test.expected[i] {
+				t.Fatalf("%s: expected error %q, got %q", test.name, test.expected[i], err)
+			}
+		}
+	}
+}
+
+var diffTests = []struct {
+	name     string
+	edit     func(g *Genesis)
+	expected []string
+}{
+	{"same", func(g *Genesis) {}, nil},
+	{
+		"reordered",
+		func(g *Genesis) { g.Accounts[0], g.Accounts[1] = g.Accounts[1], g.Accounts[0] },
+		nil,
+	},
+	{
+		"chain id",
+		func(g *Genesis) { g.ChainID = "otherchain" },
+		[]string{"~ chain_id: simplechain -> otherchain"},
+	},
+	{
+		"accounts",
+		func(g *Genesis) {
+			g.Accounts[0].Amount = 1
+			g.Accounts = g.Accounts[:1]
+		},
+		[]string{
+			"~ account " + address1 + " amount: 9999999 -> 1",
+			"- account " + address2,
+		},
+	},
+	{
+		"validators",
+		func(g *Genesis) {
+			g.Validators = append(g.Validators, &Validator{
+				PubKey: PubKey{Type: PubKeyTypeEd25519, Data: pubKey2},
+				Amount: 5,
+			})
+		},
+		[]string{"+ validator " + pubKey2},
+	},
+}
+
+func TestDiff(t *testing.T) {
+	for _, test := range diffTests {
+		a, err := Parse([]byte(validGenesis))
+		if err != nil {
+			t.Fatalf("expected genesis to parse, got %v", err)
+		}
+		b, _ := Parse([]byte(validGenesis))
+		test.edit(b)
+
+		diff := Diff(a, b)
+		if strings.Join(diff, "\n") != strings.Join(test.expected, "\n") {
+			t.Fatalf("%s: expected diff %q, got %q", test.name, test.expected, diff)
+		}
+	}
+}
+
+func TestShow(t *testing.T) {
+	g, err := Parse([]byte(validGenesis))
+	if err != nil {
+		t.Fatalf("expected genesis to parse, got %v", err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := Show(buf, g, "json"); err != nil {
+		t.Fatalf("expected show to succeed, got %v", err)
+	}
+	g2, err := Parse(buf.Bytes())
+	if err != nil {
+		t.Fatalf("expected JSON output to parse, got %v", err)
+	}
+	if diff := Diff(g, g2); len(diff) != 0 {
+		t.Fatalf("expected JSON output to match the genesis, got %q", diff)
+	}
+
+	buf.Reset()
+	if err := Show(buf, g, ""); err != nil {
+		t.Fatalf("expected show to succeed, got %v", err)
+	}
+	for _, s := range []string{"simplechain", address1, address2, pubKey1, address1 + ":10"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Fatalf("expected table to contain %q, got %s", s, buf)
+		}
+	}
+}
+
+func TestValidators(t *testing.T) {
+	g, err := Parse([]byte(validGenesis))
+	if err != nil {
+		t.Fatalf("expected genesis to parse, got %v", err)
+	}
+
+	if err := g.AddValidator(NewValidator(strings.ToLower(pubKey1), 5, address2)); err == nil {
+		t.Fatalf("expected adding a duplicate validator to fail")
+	}
+	if err := g.AddValidator(NewValidator("0A1B", 5, address2)); err == nil {
+		t.Fatalf("expected adding a malformed public key to fail")
+	}
+	if err := g.AddValidator(NewValidator(strings.ToLower(pubKey2), 5, strings.ToLower(address2))); err != nil {
+		t.Fatalf("expected validator to be added, got %v", err)
+	}
+
+	b, err := g.Marshal()
+	if err != nil {
+		t.Fatalf("expected genesis to marshal, got %v", err)
+	}
+	if errs := Validate(b); len(errs) != 0 {
+		t.Fatalf("expected genesis to stay valid, got %v", errs)
+	}
+	if !strings.Contains(string(b), pubKey2) || !strings.Contains(string(b), address2) {
+		t.Fatalf("expected upper case public key and address, got %s", b)
+	}
+
+	if _, err := g.RemoveValidator("nobody"); err == nil {
+		t.Fatalf("expected removing an unknown validator to fail")
+	}
+	removed, err := g.RemoveValidator(strings.ToLower(address2))
+	if err != nil || removed.PubKey.Data != pubKey2 {
+		t.Fatalf("expected validator to be removed by address, got %v (%v)", removed, err)
+	}
+	if removed, err = g.RemoveValidator("val"); err != nil || removed.PubKey.Data != pubKey1 {
+		t.Fatalf("expected validator to be removed by name, got %v (%v)", removed, err)
+	}
+	if len(g.Validators) != 0 {
+		t.Fatalf("expected no validators left, got %d", len(g.Validators))
+	}
+}
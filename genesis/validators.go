@@ -0,0 +1,79 @@
+package genesis
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// NewValidator returns a validator with the hex ed25519 public key which
+// bonds amount and unbonds it to the unbondTo address.
+func NewValidator(pubKey string, amount int64, unbondTo string) *Validator {
+	return &Validator{
+		PubKey: PubKey{Type: PubKeyTypeEd25519, Data: strings.ToUpper(pubKey)},
+		Amount: amount,
+		UnbondTo: []*UnbondTo{{
+			Address: strings.ToUpper(unbondTo),
+			Amount:  amount,
+		}},
+	}
+}
+
+// AddValidator adds a validator to the genesis, unless it has one with
+// the same public key already.
+func (g *Genesis) AddValidator(v *Validator) error {
+	if err := checkPubKey(v.PubKey); err != nil {
+		return err
+	}
+	for _, validator := range g.Validators {
+		if strings.EqualFold(validator.PubKey.Data, v.PubKey.Data) {
+			return fmt.Errorf("%s is a validator already", v.PubKey)
+		}
+	}
+	g.Validators = append(g.Validators, v)
+	return nil
+}
+
+// RemoveValidator removes the validator with the id public key, name, or
+// unbond_to address from the genesis and returns it. It fails if there's
+// no such validator or if there are several.
+func (g *Genesis) RemoveValidator(id string) (*Validator, error) {
+	found := -1
+	for i, validator := range g.Validators {
+		if !validator.is(id) {
+			continue
+		}
+		if found >= 0 {
+			return nil, fmt.Errorf("%s names several validators, use the public key", id)
+		}
+		found = i
+	}
+	if found < 0 {
+		return nil, fmt.Errorf("there is no %s validator", id)
+	}
+
+	removed := g.Validators[found]
+	g.Validators = append(g.Validators[:found], g.Validators[found+1:]...)
+	return removed, nil
+}
+
+func (v *Validator) is(id string) bool {
+	if strings.EqualFold(v.PubKey.Data, id) || (v.Name != "" && v.Name == id) {
+		return true
+	}
+	for _, unbond := range v.UnbondTo {
+		if strings.EqualFold(unbond.Address, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// Marshal returns the genesis file contents of the genesis.
+func (g *Genesis) Marshal() ([]byte, error) {
+	b, err := json.MarshalIndent(g, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
@@ -3,7 +3,6 @@ package initialize
 import (
 	"bytes"
 	"fmt"
-	"io"
 	"io/ioutil"
 	"os"
 	"path"
@@ -12,15 +11,11 @@ import (
 
 	"github.com/eris-ltd/eris-cli/util"
 
-	"github.com/docker/docker/pkg/jsonmessage"
-	"github.com/docker/docker/pkg/term"
-
 	ver "github.com/eris-ltd/eris-cli/version"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/eris-ltd/common/go/common"
 	"github.com/eris-ltd/common/go/ipfs"
-	docker "github.com/fsouza/go-dockerclient"
 )
 
 // XXX all files in this sequence must be added to both
@@ -104,54 +99,11 @@ func pullDefaultImages() error {
 
 	log.Warn("Pulling default Docker images from quay.io")
 
-	// XXX can't use perform.PullImage b/c import cycle :(
-	// it's essentially re-implemented here w/ a bit more opinion
-	// fail over to docker hub is quay is down/firewalled
-	auth := docker.AuthConfiguration{}
-
 	for i, image := range images {
-		var tag string = "latest"
-
-		nameSplit := strings.Split(image, ":")
-		if len(nameSplit) == 2 {
-			tag = nameSplit[1]
-		}
-		if len(nameSplit) == 3 {
-			tag = nameSplit[2]
-		}
-		image = nameSplit[0]
 		img := path.Join(ver.ERIS_REG_DEF, image)
 
-		r, w := io.Pipe()
-		opts := docker.PullImageOptions{
-			Repository:    img,
-			Registry:      ver.ERIS_REG_DEF,
-			Tag:           tag,
-			OutputStream:  w,
-			RawJSONStream: true,
-		}
-
-		if os.Getenv("ERIS_PULL_APPROVE") == "true" {
-			opts.OutputStream = ioutil.Discard
-		}
-
 		log.WithField("image", img).Warnf("Pulling image %d out of %d", i+1, len(images))
-
-		ch := make(chan error, 1)
-		go func() {
-			defer w.Close()
-			defer close(ch)
-
-			if err := util.DockerClient.PullImage(opts, auth); err != nil {
-				opts.Repository = image
-				opts.Registry = ver.ERIS_REG_BAK
-				if err := util.DockerClient.PullImage(opts, auth); err != nil {
-					ch <- util.DockerError(err)
-				}
-			}
-		}()
-		jsonmessage.DisplayJSONMessagesStream(r, os.Stdout, os.Stdout.Fd(), term.IsTerminal(os.Stdout.Fd()), nil)
-		if err, ok := <-ch; ok {
+		if err := util.PullImage(img, os.Stdout); err != nil {
 			return err
 		}
 
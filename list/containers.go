@@ -21,15 +21,15 @@ import (
 const (
 	// `eris ls` format.
 	standardTmplHeader = "{{toupper .}}\tON\tCONTAINER ID\tDATA CONTAINER"
-	standardTmpl       = "{{.ShortName}}\t{{asterisk .Info.State.Running}}\t{{short .Info.ID}}\t{{short (dependent .ShortName)}}"
+	standardTmpl       = "{{.ShortName}}{{node .}}\t{{asterisk .Info.State.Running}}\t{{short .Info.ID}}\t{{dependent .ShortName}}"
 
 	// `eris ls -a` format.
 	extendedTmplHeader = "{{toupper .}}\tON\tCONTAINER ID\tDATA CONTAINER\tIMAGE\tCOMMAND\tPORTS"
-	extendedTmpl       = "{{.ShortName}}\t{{asterisk .Info.State.Running}}\t{{short .Info.ID}}\t{{short (dependent .ShortName)}}\t{{.Info.Config.Image}}\t{{.Info.Config.Cmd}}\t{{ports .Info}}"
+	extendedTmpl       = "{{.ShortName}}{{node .}}\t{{asterisk .Info.State.Running}}\t{{short .Info.ID}}\t{{dependent .ShortName}}\t{{.Info.Config.Image}}\t{{.Info.Config.Cmd}}\t{{ports .Info}}"
 
-	// Data section.
-	dataTmplHeader = "{{toupper .}}\tON\tCONTAINER ID"
-	dataTmpl       = "{{.ShortName}}\t{{asterisk .Info.State.Running}}\t{{short .Info.ID}}"
+	// Data section (data volumes have no container).
+	dataTmplHeader = "{{toupper .}}\tON\tCONTAINER ID\tVOLUME"
+	dataTmpl       = "{{.ShortName}}\t{{if .Volume}}-\t\t{{.Volume.Name}}{{else}}{{asterisk .Info.State.Running}}\t{{short .Info.ID}}\t{{end}}"
 )
 
 var (
@@ -50,21 +50,25 @@ var (
 			}
 			return "-"
 		},
-		// Truncate the longer ID version (handy for copying and pasting).
-		"short": func(id string) string {
-			if len(id) <= 10 {
-				return id
+		"short": short,
+		// Show the chain a chain node belongs to.
+		"node": func(container *util.Details) string {
+			if chain := container.Labels[def.LabelChain]; chain != "" {
+				return " (" + chain + ")"
 			}
-
-			return id[:10]
+			return ""
 		},
-		// Show a dependent data container name if it exists
-		// for the given short name of a service or a chain.
+		// Show a dependent data container ID or data volume name
+		// if it exists for the given short name of a service or a chain.
 		"dependent": func(name string) string {
 			for _, container := range erisContainers {
-				if container.ShortName == name && container.Type == def.TypeData {
-					return container.Info.ID
+				if container.ShortName != name || container.Type != def.TypeData {
+					continue
 				}
+				if container.Volume != nil {
+					return container.Volume.Name
+				}
+				return short(container.Info.ID)
 			}
 			return ""
 		},
@@ -75,6 +79,15 @@ var (
 	}
 )
 
+// short truncates the longer ID version (handy for copying and pasting).
+func short(id string) string {
+	if len(id) <= 10 {
+		return id
+	}
+
+	return id[:10]
+}
+
 // Containers display container information on the console in a format
 // specified by the "format" parameter: the default "" and "extended" use the
 // predefined Go templates, "json" dumps the JSON document of container
@@ -99,6 +112,13 @@ func Containers(t, format string, running bool) error {
 		erisContainers = append(erisContainers, details)
 		return true
 	}, false)
+	if t == def.TypeData || t == "all" {
+		volumes, err := util.ErisVolumes()
+		if err != nil {
+			return err
+		}
+		erisContainers = append(erisContainers, volumes...)
+	}
 
 	// Keys for the parameter map.
 	const (
@@ -256,6 +276,13 @@ func jsonContainers(t string, running bool) error {
 		}
 		return true
 	}, running)
+	if (t == def.TypeData || t == "all") && !running {
+		volumes, err := util.ErisVolumes()
+		if err != nil {
+			return err
+		}
+		erisContainers = append(erisContainers, volumes...)
+	}
 
 	b, err := json.Marshal(erisContainers)
 	if err != nil {
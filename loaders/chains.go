@@ -5,7 +5,6 @@ import (
 	"path"
 	"path/filepath"
 
-	"github.com/eris-ltd/eris-cli/config"
 	"github.com/eris-ltd/eris-cli/definitions"
 	"github.com/eris-ltd/eris-cli/util"
 	"github.com/eris-ltd/eris-cli/version"
@@ -36,7 +35,15 @@ func LoadChainDefinition(chainName string, newCont bool) (*definitions.Chain, er
 		return nil, err
 	}
 
-	chainConf, err := config.LoadViperConfig(filepath.Join(ChainsPath), chainName, "chain")
+	// Nodes of a chain made of several validators are loaded from
+	// the definition of that chain.
+	configName := chainName
+	nodeOf, isNode := ChainNodeOf(chainName)
+	if isNode {
+		configName = nodeOf
+	}
+
+	chainConf, err := loadDefinition(filepath.Join(ChainsPath), configName, "chain")
 	if err != nil {
 		return nil, err
 	}
@@ -48,6 +55,32 @@ func LoadChainDefinition(chainName string, newCont bool) (*definitions.Chain, er
 		return nil, err
 	}
 
+	if err = checkLimits(chain.Service); err != nil {
+		return nil, err
+	}
+
+	network := util.ChainNetworkName(chain.Name)
+	if isNode {
+		chain.NodeOf = nodeOf
+		if chain.ChainID == "" {
+			chain.ChainID = nodeOf
+		}
+		chain.Operations.Labels = util.SetLabel(chain.Operations.Labels, definitions.LabelChain, nodeOf)
+
+		// All nodes publish the same ports, so let Docker pick
+		// the host ones.
+		chain.Operations.PublishAllPorts = true
+		network = util.ChainNetworkName(nodeOf)
+	}
+
+	// Chains get a network of their own on which they answer to their
+	// name and to "chain", whatever their container name is. Nodes
+	// share the network of their chain.
+	if chain.Service.Net == "" {
+		util.JoinNetwork(chain.Service, network)
+		chain.Service.Aliases = append(chain.Service.Aliases, util.ChainAlias)
+	}
+
 	// Docker 1.6 (which eris doesn't support) had different linking mechanism.
 	if util.IsMinimalDockerClientVersion() {
 		if chain.Dependencies != nil {
@@ -65,6 +98,38 @@ func LoadChainDefinition(chainName string, newCont bool) (*definitions.Chain, er
 	return chain, nil
 }
 
+// ChainNodeOf returns the name of the chain the chainName node belongs to
+// and true, if chainName has no definition file of its own and names one
+// of the nodes of a chain made of several validators (see ChainNodeNames).
+func ChainNodeOf(chainName string) (string, bool) {
+	if util.GetFileByNameAndType("chains", chainName) != "" {
+		return "", false
+	}
+	base, i, ok := util.SplitChainNodeName(chainName)
+	if !ok {
+		return "", false
+	}
+	chainConf, err := loadDefinition(filepath.Join(ChainsPath), base, "chain")
+	if err != nil {
+		return "", false
+	}
+	return base, i < chainConf.GetInt("nodes.count")
+}
+
+// ChainNodeNames returns the short names of the nodes of the chain, or
+// nil if the chain is not made of several validator nodes.
+func ChainNodeNames(chain *definitions.Chain) []string {
+	if chain.Nodes == nil || chain.NodeOf != "" {
+		return nil
+	}
+
+	var names []string
+	for i := 0; i < chain.Nodes.Count; i++ {
+		names = append(names, util.ChainNodeName(chain.Name, i))
+	}
+	return names
+}
+
 // Convert the chain def to a service def but keep the "eris_chains" containers prefix and set the chain id
 func ChainsAsAService(chainName string, newCont bool) (*definitions.ServiceDefinition, error) {
 	chain, err := LoadChainDefinition(chainName, newCont)
@@ -106,6 +171,14 @@ func ServiceDefFromChain(chain *definitions.Chain, cmd string) *definitions.Serv
 }
 
 func ConnectToAChain(srv *definitions.Service, ops *definitions.Operation, name, internalName string, link, mount bool) {
+	// Join the chain network instead of linking if the chain
+	// can be found there by the internal name.
+	if link && srv.Net == "" {
+		util.JoinNetwork(srv, util.ChainNetworkName(name))
+		if internalName == name || internalName == util.ChainAlias {
+			link = false
+		}
+	}
 	connectToAService(srv, ops, definitions.TypeChain, name, internalName, link, mount)
 }
 
@@ -135,6 +208,8 @@ func MarshalChainDefinition(chainConf *viper.Viper, chain *definitions.Chain) er
 
 	util.Merge(chain.Service, chnTemp.Service)
 	chain.ChainID = chnTemp.ChainID
+	chain.Nodes = chnTemp.Nodes
+	chain.Seeds = chnTemp.Seeds
 
 	// toml bools don't really marshal well
 	// data_container can be in the chain or
@@ -151,7 +226,7 @@ func MarshalChainDefinition(chainConf *viper.Viper, chain *definitions.Chain) er
 }
 
 func setChainDefaults(chain *definitions.Chain) error {
-	cfg, err := config.LoadViperConfig(filepath.Join(ChainsPath), "default", "chain")
+	cfg, err := loadDefinition(filepath.Join(ChainsPath), "default", "chain")
 	if err != nil {
 		return err
 	}
@@ -17,6 +17,9 @@ func LoadDataDefinition(dataName string) *definitions.Operation {
 	ops.ContainerType = definitions.TypeData
 	ops.SrvContainerName = util.DataContainerName(dataName)
 	ops.DataContainerName = util.DataContainerName(dataName)
+	if util.ExistingData(dataName) == definitions.StorageVolume {
+		ops.DataVolumeName = util.DataVolumeName(dataName)
+	}
 	ops.Labels = util.Labels(dataName, ops)
 
 	return ops
@@ -0,0 +1,284 @@
+package loaders
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/eris-ltd/eris-cli/config"
+
+	log "github.com/Sirupsen/logrus"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	// Profile is the profile of the chain and service definitions to use
+	// ([eris chains|services --profile]). If it's empty, the eris.toml
+	// Profile default is used.
+	Profile string
+
+	// Settings are KEY=VALUE values of definition fields, KEY being
+	// a dotted path such as service.image ([eris chains|services --set]).
+	Settings []string
+
+	// Names are the names of the definitions Profile and Settings apply
+	// to, those given on the command line. Other definitions, such as
+	// the dependencies of a service or the chains default.toml file, are
+	// read with the eris.toml Profile default only.
+	Names []string
+)
+
+var variableRe = regexp.MustCompile(`\$(\$?)\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?\}`)
+
+// loadDefinition reads a definition file like config.LoadViperConfig
+// does and applies the overlays to it (see ApplyOverlays).
+func loadDefinition(configPath, configName, typ string) (*viper.Viper, error) {
+	conf, err := config.LoadViperConfig(configPath, configName, typ)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, settings := Profile, Settings
+	if !isNamed(configName) {
+		profile, settings = "", nil
+	}
+	if profile == "" && config.GlobalConfig != nil && config.GlobalConfig.Config != nil {
+		// The default profile is for the definitions which have it.
+		if p := config.GlobalConfig.Config.Profile; p != "" && conf.IsSet("profiles."+p) {
+			profile = p
+		}
+	}
+	return ApplyOverlays(conf, configName, profile, settings)
+}
+
+// isNamed returns true if the definition name is one of Names.
+func isNamed(name string) bool {
+	for _, n := range Names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyOverlays returns the name definition with
+//
+//  1. the [profiles.PROFILE] table of it merged in, if profile isn't
+//     empty;
+//  2. the KEY=VALUE settings merged in, values of list fields being
+//     separated by commas;
+//  3. ${VAR} and ${VAR:-default} in the values replaced with the
+//     environment variables ($${VAR} stands for ${VAR} itself).
+//
+// The merges follow util.Merge: lists are appended to, tables are merged,
+// and other values are replaced unless the new ones are empty. Other
+// profiles are dropped. A definition without profiles ignores profile.
+func ApplyOverlays(conf *viper.Viper, name, profile string, settings []string) (*viper.Viper, error) {
+	tree := normalizeSettings(conf.AllSettings()).(map[string]interface{})
+
+	profiles, _ := tree["profiles"].(map[string]interface{})
+	delete(tree, "profiles")
+	if profile != "" && len(profiles) != 0 {
+		overlay, ok := profiles[profile].(map[string]interface{})
+		if !ok {
+			var known []string
+			for p := range profiles {
+				known = append(known, p)
+			}
+			sort.Strings(known)
+			return nil, fmt.Errorf("The %s definition has no %s profile (it has %s)", name, profile, strings.Join(known, ", "))
+		}
+		log.WithFields(log.Fields{
+			"=>":      name,
+			"profile": profile,
+		}).Debug("Applying definition profile")
+		mergeSettings(tree, overlay)
+	}
+
+	overlay, err := parseSettings(settings, tree)
+	if err != nil {
+		return nil, err
+	}
+	mergeSettings(tree, overlay)
+
+	interpolated := interpolateSettings(tree, name).(map[string]interface{})
+
+	v := viper.New()
+	for key, value := range interpolated {
+		v.Set(key, value)
+	}
+	return v, nil
+}
+
+// parseSettings turns KEY=VALUE settings into a settings tree. Lists in
+// tree take the comma separated values. Settings without = continue the
+// previous one, as the --set flag splits values at commas.
+func parseSettings(settings []string, tree map[string]interface{}) (map[string]interface{}, error) {
+	var joined []string
+	for _, setting := range settings {
+		if !strings.Contains(setting, "=") && len(joined) != 0 {
+			joined[len(joined)-1] += "," + setting
+			continue
+		}
+		joined = append(joined, setting)
+	}
+
+	overlay := make(map[string]interface{})
+	for _, setting := range joined {
+		parts := strings.SplitN(setting, "=", 2)
+		if len(parts) != 2 || strings.Trim(parts[0], ".") == "" {
+			return nil, fmt.Errorf("Please give me settings as KEY=VALUE, not %q", setting)
+		}
+		path := strings.Split(parts[0], ".")
+
+		var value interface{} = parts[1]
+		if _, ok := lookupSetting(tree, path).([]interface{}); ok {
+			var list []interface{}
+			for _, item := range strings.Split(parts[1], ",") {
+				list = append(list, item)
+			}
+			value = list
+		}
+
+		node := overlay
+		for _, key := range path[:len(path)-1] {
+			next, ok := node[key].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				node[key] = next
+			}
+			node = next
+		}
+		node[path[len(path)-1]] = value
+	}
+	return overlay, nil
+}
+
+// mergeSettings merges over into base like util.Merge merges structs.
+func mergeSettings(base, over map[string]interface{}) {
+	for key, value := range over {
+		baseKey := settingKey(base, key)
+		switch current := base[baseKey].(type) {
+		case map[string]interface{}:
+			if value, ok := value.(map[string]interface{}); ok {
+				mergeSettings(current, value)
+				continue
+			}
+		case []interface{}:
+			if value, ok := value.([]interface{}); ok {
+				base[baseKey] = append(current, value...)
+				continue
+			}
+		}
+
+		// Don't overwrite with zero values (0, "", false).
+		if value == nil || reflect.DeepEqual(value, reflect.Zero(reflect.TypeOf(value)).Interface()) {
+			continue
+		}
+		base[baseKey] = value
+	}
+}
+
+// settingKey returns the key of m which is key regardless of case, as
+// definitions are read case insensitively, or key if there's none.
+func settingKey(m map[string]interface{}, key string) string {
+	if _, ok := m[key]; ok {
+		return key
+	}
+	for k := range m {
+		if strings.EqualFold(k, key) {
+			return k
+		}
+	}
+	return key
+}
+
+func lookupSetting(tree map[string]interface{}, path []string) interface{} {
+	var value interface{} = tree
+	for _, key := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[settingKey(m, key)]
+	}
+	return value
+}
+
+// normalizeSettings turns the map[interface{}]interface{} tables YAML
+// definitions are read into into map[string]interface{} ones.
+func normalizeSettings(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			m[k] = normalizeSettings(v)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			m[fmt.Sprint(k)] = normalizeSettings(v)
+		}
+		return m
+	case []map[string]interface{}:
+		list := make([]interface{}, len(value))
+		for i, v := range value {
+			list[i] = normalizeSettings(v)
+		}
+		return list
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i, v := range value {
+			list[i] = normalizeSettings(v)
+		}
+		return list
+	case []string:
+		list := make([]interface{}, len(value))
+		for i, v := range value {
+			list[i] = v
+		}
+		return list
+	default:
+		return value
+	}
+}
+
+// interpolateSettings replaces the variables in the string values.
+func interpolateSettings(value interface{}, name string) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for k, v := range value {
+			value[k] = interpolateSettings(v, name)
+		}
+		return value
+	case []interface{}:
+		for i, v := range value {
+			value[i] = interpolateSettings(v, name)
+		}
+		return value
+	case string:
+		return variableRe.ReplaceAllStringFunc(value, func(match string) string {
+			m := variableRe.FindStringSubmatch(match)
+			if m[1] != "" {
+				return match[1:]
+			}
+			if v := os.Getenv(m[2]); v != "" {
+				return v
+			}
+			if !strings.Contains(match, ":-") {
+				log.WithFields(log.Fields{
+					"=>":       name,
+					"variable": m[2],
+				}).Warn("Variable is not set, using an empty string")
+			}
+			return m[3]
+		})
+	default:
+		return value
+	}
+}
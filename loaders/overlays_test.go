@@ -0,0 +1,40 @@
+package loaders
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eris-ltd/eris-cli/tests"
+)
+
+func TestLoadServiceDefinitionOverlays(t *testing.T) {
+	_, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	root, done := tests.WithErisRoot(t)
+	defer done()
+
+	tests.WriteFiles(t, root, map[string]string{
+		"services/app.toml": "name = \"app\"\n\n[service]\nimage = \"quay.io/eris/base\"\nports = [\"1\"]\n\n[dependencies]\nservices = [\"dep\"]\n\n[profiles.prod.service]\nports = [\"2\"]\n",
+		"services/dep.toml": "name = \"dep\"\n\n[service]\nimage = \"quay.io/eris/base\"\nports = [\"9\"]\n\n[profiles.dev.service]\nports = [\"3\"]\n",
+	})
+	Profile, Settings, Names = "prod", []string{"service.ports=4"}, []string{"app"}
+	defer func() { Profile, Settings, Names = "", nil, nil }()
+
+	app, err := LoadServiceDefinition("app", false)
+	if err != nil {
+		t.Fatalf("expected service definition, got %v", err)
+	}
+	if ports := strings.Join(app.Service.Ports, " "); ports != "1 2 4" {
+		t.Fatalf("expected the profile and settings applied, got ports %q", ports)
+	}
+
+	// The dependency has no prod profile and takes no --set values.
+	dep, err := LoadServiceDefinition("dep", false)
+	if err != nil {
+		t.Fatalf("expected dependency definition, got %v", err)
+	}
+	if ports := strings.Join(dep.Service.Ports, " "); ports != "9" {
+		t.Fatalf("expected the dependency as written, got ports %q", ports)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/eris-ltd/eris-cli/config"
 	"github.com/eris-ltd/eris-cli/definitions"
 	"github.com/eris-ltd/eris-cli/util"
 
@@ -40,6 +39,10 @@ func LoadServiceDefinition(servName string, newCont bool) (*definitions.ServiceD
 		return nil, err
 	}
 
+	if err = checkLimits(srv.Service); err != nil {
+		return nil, err
+	}
+
 	// Docker 1.6 (which eris doesn't support) had different linking mechanism.
 	if util.IsMinimalDockerClientVersion() {
 		addDependencyVolumesAndLinks(srv.Dependencies, srv.Service, srv.Operations)
@@ -150,18 +153,48 @@ func connectToAService(srv *definitions.Service, ops *definitions.Operation, typ
 }
 
 func loadServiceDefinition(servName string) (*viper.Viper, error) {
-	return config.LoadViperConfig(filepath.Join(ServicesPath), servName, "service")
+	return loadDefinition(filepath.Join(ServicesPath), servName, "service")
 }
 
-// Services must be given an image. Flame out if they do not.
+// Services must be given an image. Flame out if they do not. Services
+// built locally run the image their build section tags, and their
+// build context is relative to the services directory.
 func checkImage(srv *definitions.Service) error {
+	if srv.Build != nil {
+		if srv.Build.Context == "" {
+			return fmt.Errorf("A \"context\" field is required in the build section of the service definition file.")
+		}
+		if !filepath.IsAbs(srv.Build.Context) {
+			srv.Build.Context = filepath.Join(ServicesPath, srv.Build.Context)
+		}
+
+		if srv.Build.Tag != "" {
+			srv.Image = srv.Build.Tag
+		}
+	}
+
 	if srv.Image == "" {
+		if srv.Build != nil {
+			return fmt.Errorf("An \"image\" field or a \"tag\" field in the build section is required in the service definition file.")
+		}
 		return fmt.Errorf("An \"image\" field is required in the service definition file.")
 	}
 
 	return nil
 }
 
+// Resource limits given in the Docker command line format have to parse.
+func checkLimits(srv *definitions.Service) error {
+	if _, err := util.Ulimits(srv.Ulimits); err != nil {
+		return err
+	}
+	if _, err := util.Tmpfs(srv.Tmpfs); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func addDependencyVolumesAndLinks(deps *definitions.Dependencies, srv *definitions.Service, ops *definitions.Operation) {
 	if deps != nil {
 		for i, dep := range deps.Services {
@@ -0,0 +1,147 @@
+package perform
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	log "github.com/Sirupsen/logrus"
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/util"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/term"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// DockerBuildService builds the srv.Image image from the srv.Build section
+// of the service definition (similar to `docker build -t srv.Image -f
+// srv.Build.Dockerfile srv.Build.Context`). The image is only built if it
+// doesn't exist, if any file in the build context is newer than the image,
+// or if force is true. DockerBuildService does nothing for services which
+// aren't built locally.
+//
+//  srv.Build.Context     - build context directory
+//  srv.Build.Dockerfile  - Dockerfile path within the context
+//  srv.Build.Args        - build-time variables
+//
+func DockerBuildService(srv *def.Service, force bool) error {
+	if srv.Build == nil {
+		return nil
+	}
+
+	if !force {
+		stale, err := imageStale(srv.Image, srv.Build.Context)
+		if err != nil {
+			return err
+		}
+		if !stale {
+			log.WithField("image", srv.Image).Debug("Image is up to date. Not building")
+			return nil
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"image":      srv.Image,
+		"context":    srv.Build.Context,
+		"dockerfile": srv.Build.Dockerfile,
+	}).Warn("Building image (may take a few minutes)")
+
+	r, w := io.Pipe()
+	opts := docker.BuildImageOptions{
+		Name:           srv.Image,
+		Dockerfile:     srv.Build.Dockerfile,
+		ContextDir:     srv.Build.Context,
+		RmTmpContainer: true,
+		OutputStream:   w,
+		RawJSONStream:  true,
+	}
+
+	var args []string
+	for name := range srv.Build.Args {
+		args = append(args, name)
+	}
+	sort.Strings(args)
+	for _, name := range args {
+		opts.BuildArgs = append(opts.BuildArgs, docker.BuildArg{Name: name, Value: srv.Build.Args[name]})
+	}
+
+	ch := make(chan error, 1)
+	go func() {
+		defer w.Close()
+		defer close(ch)
+
+		if err := util.DockerClient.BuildImage(opts); err != nil {
+			ch <- err
+		}
+	}()
+
+	// Build errors (e.g. a failing RUN instruction) come in the stream.
+	displayErr := jsonmessage.DisplayJSONMessagesStream(r, os.Stdout, os.Stdout.Fd(), term.IsTerminal(os.Stdout.Fd()), nil)
+
+	// The display stops at the first error message; drain the rest of
+	// the stream so that BuildImage can return.
+	io.Copy(ioutil.Discard, r)
+	if err, ok := <-ch; ok {
+		return util.DockerError(err)
+	}
+	if displayErr != nil {
+		return fmt.Errorf("Cannot build the %s image: %v", srv.Image, displayErr)
+	}
+
+	if _, err := util.DockerClient.InspectImage(srv.Image); err != nil {
+		return fmt.Errorf("Cannot find the %s image after building it: %v", srv.Image, util.DockerError(err))
+	}
+
+	log.WithField("image", srv.Image).Info("Image built")
+	return nil
+}
+
+// imageStale returns true if the image doesn't exist or if it's older
+// than any file or directory in the build context.
+func imageStale(name, context string) (bool, error) {
+	image, err := util.DockerClient.InspectImage(name)
+	if err == docker.ErrNoSuchImage {
+		return true, nil
+	}
+	if err != nil {
+		return false, util.DockerError(err)
+	}
+
+	stale := false
+	err = filepath.Walk(context, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(image.Created) {
+			stale = true
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("Cannot read the build context: %v", err)
+	}
+	return stale, nil
+}
+
+// containerOutdated builds the srv.Image image if its build context
+// changed and returns true if the name container doesn't run that
+// image anymore.
+func containerOutdated(srv *def.Service, name string) (bool, error) {
+	if err := DockerBuildService(srv, false); err != nil {
+		return false, err
+	}
+
+	container, err := util.DockerClient.InspectContainer(name)
+	if err != nil {
+		return false, util.DockerError(err)
+	}
+	image, err := util.DockerClient.InspectImage(srv.Image)
+	if err != nil {
+		return false, util.DockerError(err)
+	}
+	return container.Image != image.ID, nil
+}
@@ -0,0 +1,112 @@
+package perform
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/tests"
+	"github.com/eris-ltd/eris-cli/util"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestBuildServiceFailure(t *testing.T) {
+	server, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	server.Build = func(opts docker.BuildImageOptions) error {
+		return fmt.Errorf("The command '/bin/sh -c false' returned a non-zero code: 1")
+	}
+
+	srv := buildService(t)
+	defer os.RemoveAll(srv.Service.Build.Context)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- DockerBuildService(srv.Service, false)
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil || !strings.Contains(err.Error(), "returned a non-zero code") {
+			t.Fatalf("expected build to fail, got %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("expected build to return")
+	}
+}
+
+func TestRunServiceRebuilt(t *testing.T) {
+	_, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	srv := buildService(t)
+	defer os.RemoveAll(srv.Service.Build.Context)
+
+	run := func() *docker.Container {
+		if err := DockerRunService(srv.Service, srv.Operations); err != nil {
+			t.Fatalf("expected service to start, got %v", err)
+		}
+		container, err := util.DockerClient.InspectContainer(srv.Operations.SrvContainerName)
+		if err != nil {
+			t.Fatalf("expected service container, got %v", err)
+		}
+		if err := DockerStop(srv.Service, srv.Operations, 10); err != nil {
+			t.Fatalf("expected service to stop, got %v", err)
+		}
+		return container
+	}
+
+	built := run()
+
+	// Nothing changed in the build context.
+	if container := run(); container.ID != built.ID {
+		t.Fatalf("expected container %v to be started again, got %v", built.ID, container.ID)
+	}
+
+	// A changed Dockerfile rebuilds the image and recreates the container.
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(srv.Service.Build.Context, "Dockerfile"), later, later); err != nil {
+		t.Fatalf("expected Dockerfile touched, got %v", err)
+	}
+	rebuilt := run()
+	if rebuilt.ID == built.ID || rebuilt.Image == built.Image {
+		t.Fatalf("expected container recreated from a new image, got %v (image %v)", rebuilt.ID, rebuilt.Image)
+	}
+	if image, err := util.DockerClient.InspectImage(srv.Service.Image); err != nil || image.ID != rebuilt.Image {
+		t.Fatalf("expected container to run image %v, got %v (error %v)", rebuilt.Image, image, err)
+	}
+
+	containers, _ := util.DockerClient.ListContainers(docker.ListContainersOptions{All: true})
+	if len(containers) != 1 {
+		t.Fatalf("expected the old container removed, got %v", containers)
+	}
+}
+
+// buildService returns a service definition built from a Dockerfile
+// in a temporary build context.
+func buildService(t *testing.T) *def.ServiceDefinition {
+	context, err := ioutil.TempDir("", "eris-build")
+	if err != nil {
+		t.Fatalf("expected temp dir, got %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(context, "Dockerfile"), []byte("FROM quay.io/eris/base\n"), 0644); err != nil {
+		t.Fatalf("expected Dockerfile written, got %v", err)
+	}
+
+	srv := def.BlankServiceDefinition()
+	srv.Name = "oracle"
+	srv.Service.Name = "oracle"
+	srv.Service.Image = "myorg/oracle:local"
+	srv.Service.Build = &def.Build{Context: context}
+	srv.Operations.ContainerType = def.TypeService
+	srv.Operations.SrvContainerName = util.ServiceContainerName("oracle")
+	srv.Operations.Labels = util.Labels("oracle", srv.Operations)
+	return srv
+}
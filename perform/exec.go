@@ -0,0 +1,151 @@
+package perform
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/eris-ltd/eris-cli/config"
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/util"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/term"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// ExecError is returned by DockerExecAttach when the command exits with
+// a non-zero status.
+type ExecError struct {
+	Container string
+	Code      int
+}
+
+func (e ExecError) Error() string {
+	return fmt.Sprintf("Command in container %s exited with status %d", e.Container, e.Code)
+}
+
+// DockerExecAttach runs a command inside the running ops.SrvContainerName
+// container (similar to `docker exec`), so the command sees the container
+// filesystem, processes and state rather than those of a new container.
+// The command output is returned and also written to the interactive
+// writers.
+//
+//  ops.Args         - command line parameters (a shell if empty and
+//                     ops.Interactive is true)
+//  ops.Interactive  - if true, pass the standard input to the command, and
+//                     allocate a TTY following the terminal size if the
+//                     standard input is a terminal
+//
+// DockerExecAttach returns ExecError if the command exits with a non-zero
+// status, or Docker errors.
+func DockerExecAttach(srv *def.Service, ops *def.Operation) (buf *bytes.Buffer, err error) {
+	log.WithFields(log.Fields{
+		"=>":          ops.SrvContainerName,
+		"args":        ops.Args,
+		"interactive": ops.Interactive,
+	}).Info("Executing in running container")
+
+	if !ContainerRunning(ops.SrvContainerName) {
+		return nil, fmt.Errorf("Container %s is not running. Start it first or run the command without --attach", ops.SrvContainerName)
+	}
+
+	cmd := ops.Args
+	if len(cmd) == 0 {
+		if !ops.Interactive {
+			return nil, fmt.Errorf("Non-interactive exec sessions must provide arguments to execute")
+		}
+		cmd = []string{"/bin/sh"}
+	}
+	tty := ops.Interactive && term.IsTerminal(os.Stdin.Fd())
+
+	exec, err := util.DockerClient.CreateExec(docker.CreateExecOptions{
+		Container:    ops.SrvContainerName,
+		AttachStdin:  ops.Interactive,
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          tty,
+		Cmd:          cmd,
+	})
+	if err != nil {
+		return nil, util.DockerError(err)
+	}
+
+	buf = new(bytes.Buffer)
+	opts := docker.StartExecOptions{
+		Tty:          tty,
+		RawTerminal:  tty,
+		OutputStream: io.MultiWriter(buf, config.GlobalConfig.InteractiveWriter),
+		ErrorStream:  io.MultiWriter(buf, config.GlobalConfig.InteractiveErrorWriter),
+	}
+
+	if ops.Interactive {
+		// Same as in attachContainer, keep os.Stdin open after the
+		// session ends.
+		reader, writer := io.Pipe()
+		go func() {
+			io.Copy(writer, os.Stdin)
+		}()
+		opts.InputStream = reader
+	}
+
+	if tty {
+		savedState, err := term.SetRawTerminal(os.Stdin.Fd())
+		if err != nil {
+			log.Info("Cannot set the terminal into raw mode")
+		} else {
+			defer term.RestoreTerminal(os.Stdin.Fd(), savedState)
+		}
+
+		// Size the TTY once the session is up and whenever
+		// the terminal is resized.
+		success := make(chan struct{})
+		opts.Success = success
+		resized := make(chan os.Signal, 1)
+		notifyResize(resized)
+		defer signal.Stop(resized)
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-success:
+				success <- struct{}{}
+			case <-done:
+				return
+			}
+			for {
+				resizeExec(exec.ID)
+				select {
+				case <-resized:
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	if err := util.DockerClient.StartExec(exec.ID, opts); err != nil {
+		return buf, util.DockerError(err)
+	}
+
+	inspect, err := util.DockerClient.InspectExec(exec.ID)
+	if err != nil {
+		return buf, util.DockerError(err)
+	}
+	if inspect.ExitCode != 0 {
+		return buf, ExecError{Container: ops.SrvContainerName, Code: inspect.ExitCode}
+	}
+	return buf, nil
+}
+
+func resizeExec(id string) {
+	size, err := term.GetWinsize(os.Stdin.Fd())
+	if err != nil {
+		return
+	}
+	if err := util.DockerClient.ResizeExecTTY(id, int(size.Height), int(size.Width)); err != nil {
+		log.WithField("error", err).Debug("Cannot resize the exec TTY")
+	}
+}
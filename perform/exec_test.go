@@ -0,0 +1,102 @@
+package perform
+
+import (
+	"os"
+	"testing"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/tests"
+	"github.com/eris-ltd/eris-cli/util"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestExecServiceDataContainer(t *testing.T) {
+	server, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	os.Setenv("ERIS_PULL_APPROVE", "true")
+	defer os.Unsetenv("ERIS_PULL_APPROVE")
+
+	srv := startKeys(t)
+	if !util.IsService("keys", true) {
+		t.Fatalf("expected service to run")
+	}
+	if !util.IsData("keys") {
+		t.Fatalf("expected data container to exist")
+	}
+
+	// The service shares the data container volumes.
+	srv.Operations.Args = []string{"touch", "/home/eris/.eris/keys.json"}
+	if _, err := DockerExecService(srv.Service, srv.Operations); err != nil {
+		t.Fatalf("expected exec to succeed, got %v", err)
+	}
+	if _, err := server.ReadFile(srv.Operations.DataContainerName, "/home/eris/.eris/keys.json"); err != nil {
+		t.Fatalf("expected file in data container, got %v", err)
+	}
+
+	if err := DockerStop(srv.Service, srv.Operations, 10); err != nil {
+		t.Fatalf("expected service to stop, got %v", err)
+	}
+	if err := DockerRemove(srv.Service, srv.Operations, true, true, false); err != nil {
+		t.Fatalf("expected service to be removed, got %v", err)
+	}
+	if util.IsService("keys", false) || util.IsData("keys") {
+		t.Fatalf("expected service and data containers to be removed")
+	}
+}
+
+func TestExecAttach(t *testing.T) {
+	server, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	server.AddImage("quay.io/eris/keys")
+
+	srv := def.BlankServiceDefinition()
+	srv.Name = "keys"
+	srv.Service.Name = "keys"
+	srv.Service.Image = "quay.io/eris/keys"
+	srv.Operations.ContainerType = def.TypeService
+	srv.Operations.SrvContainerName = util.ServiceContainerName("keys")
+	srv.Operations.Labels = util.Labels("keys", srv.Operations)
+	if err := DockerRunService(srv.Service, srv.Operations); err != nil {
+		t.Fatalf("expected service to start, got %v", err)
+	}
+
+	srv.Operations.Args = []string{"echo", "hello"}
+	buf, err := DockerExecAttach(srv.Service, srv.Operations)
+	if err != nil {
+		t.Fatalf("expected exec to succeed, got %v", err)
+	}
+	if buf.String() != "hello\n" {
+		t.Fatalf("expected exec output %q, got %q", "hello\n", buf.String())
+	}
+
+	srv.Operations.Args = []string{"false"}
+	if _, err := DockerExecAttach(srv.Service, srv.Operations); err != (ExecError{Container: srv.Operations.SrvContainerName, Code: 1}) {
+		t.Fatalf("expected exit status 1, got %v", err)
+	}
+
+	containers, _ := util.DockerClient.ListContainers(docker.ListContainersOptions{All: true})
+	if len(containers) != 1 {
+		t.Fatalf("expected no containers created for exec, got %v", containers)
+	}
+}
+
+// startKeys runs the keys service along with its data container.
+func startKeys(t *testing.T) *def.ServiceDefinition {
+	srv := def.BlankServiceDefinition()
+	srv.Name = "keys"
+	srv.Service.Name = "keys"
+	srv.Service.Image = "quay.io/eris/keys"
+	srv.Service.AutoData = true
+	srv.Operations.ContainerType = def.TypeService
+	srv.Operations.SrvContainerName = util.ServiceContainerName("keys")
+	srv.Operations.DataContainerName = util.DataContainerName("keys")
+	srv.Operations.Labels = util.Labels("keys", srv.Operations)
+
+	if err := DockerRunService(srv.Service, srv.Operations); err != nil {
+		t.Fatalf("expected service to start, got %v", err)
+	}
+	return srv
+}
@@ -0,0 +1,194 @@
+package perform
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/util"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// DockerWaitReady blocks until the ops.SrvContainerName container passes
+// the srv.HealthCheck probe. Probes are tried in the following order:
+//
+//  srv.HealthCheck.Command  - run the command inside the container
+//                             (ready if it exits with a zero status)
+//  srv.HealthCheck.Path     - HTTP GET the path on the srv.HealthCheck.Port
+//                             port (ready on a 2xx or 3xx response)
+//  srv.HealthCheck.Port     - open a TCP connection to the port
+//
+// Without a health check, the container is considered ready as soon as
+// it is running. DockerWaitReady returns an error if the container exits
+// or isn't ready after srv.HealthCheck.Retries attempts.
+func DockerWaitReady(srv *def.Service, ops *def.Operation) error {
+	check := readinessCheck(srv.HealthCheck)
+
+	log.WithFields(log.Fields{
+		"=>":       ops.SrvContainerName,
+		"command":  check.Command,
+		"port":     check.Port,
+		"path":     check.Path,
+		"retries":  check.Retries,
+		"interval": check.Interval,
+	}).Info("Waiting for container to become ready")
+
+	var err error
+	for attempt := 1; attempt <= check.Retries; attempt++ {
+		if err = probeContainer(ops.SrvContainerName, check); err == nil {
+			log.WithField("=>", ops.SrvContainerName).Info("Container is ready")
+			return nil
+		}
+		if _, exited := err.(errContainerExited); exited {
+			return err
+		}
+
+		log.WithFields(log.Fields{
+			"=>":      ops.SrvContainerName,
+			"attempt": attempt,
+			"error":   err,
+		}).Debug("Container is not ready yet")
+
+		if attempt < check.Retries {
+			time.Sleep(time.Duration(check.Interval) * time.Second)
+		}
+	}
+
+	return fmt.Errorf("Timed out waiting for container %s to become ready after %d attempts (%ds apart): %v",
+		ops.SrvContainerName, check.Retries, check.Interval, err)
+}
+
+type errContainerExited struct {
+	name string
+	code int
+}
+
+func (e errContainerExited) Error() string {
+	return fmt.Sprintf("container %s exited with code %d before becoming ready", e.name, e.code)
+}
+
+// readinessCheck fills in the timing defaults for a health check.
+func readinessCheck(hc *def.HealthCheck) *def.HealthCheck {
+	check := def.BlankHealthCheck()
+	if hc == nil {
+		return check
+	}
+
+	check.Command = hc.Command
+	check.Port = hc.Port
+	check.Path = hc.Path
+	if hc.Interval > 0 {
+		check.Interval = hc.Interval
+	}
+	if hc.Retries > 0 {
+		check.Retries = hc.Retries
+	}
+	if hc.Timeout > 0 {
+		check.Timeout = hc.Timeout
+	}
+	return check
+}
+
+func probeContainer(name string, check *def.HealthCheck) error {
+	container, err := util.DockerClient.InspectContainer(name)
+	if err != nil {
+		return util.DockerError(err)
+	}
+	if !container.State.Running {
+		if !container.State.Restarting && !container.State.FinishedAt.IsZero() {
+			return errContainerExited{name, container.State.ExitCode}
+		}
+		return fmt.Errorf("container %s is not running", name)
+	}
+	if container.State.Restarting {
+		return fmt.Errorf("container %s is restarting", name)
+	}
+
+	timeout := time.Duration(check.Timeout) * time.Second
+
+	switch {
+	case check.Command != "":
+		return probeCommand(name, check.Command, timeout)
+	case check.Port != "" && check.Path != "":
+		return probeHTTP(name, check.Port, check.Path, timeout)
+	case check.Port != "":
+		return probeTCP(name, check.Port, timeout)
+	}
+	return nil
+}
+
+func probeCommand(name, command string, timeout time.Duration) error {
+	exec, err := util.DockerClient.CreateExec(docker.CreateExecOptions{
+		Container:    name,
+		AttachStdout: true,
+		AttachStderr: true,
+		Cmd:          []string{"/bin/sh", "-c", command},
+	})
+	if err != nil {
+		return util.DockerError(err)
+	}
+
+	buf := new(bytes.Buffer)
+	done := make(chan error, 1)
+	go func() {
+		done <- util.DockerClient.StartExec(exec.ID, docker.StartExecOptions{
+			OutputStream: buf,
+			ErrorStream:  buf,
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return util.DockerError(err)
+		}
+	case <-time.After(timeout):
+		return fmt.Errorf("health check command %q timed out after %v", command, timeout)
+	}
+
+	inspect, err := util.DockerClient.InspectExec(exec.ID)
+	if err != nil {
+		return util.DockerError(err)
+	}
+	if inspect.ExitCode != 0 {
+		return fmt.Errorf("health check command %q exited with code %d: %s", command, inspect.ExitCode, bytes.TrimSpace(buf.Bytes()))
+	}
+	return nil
+}
+
+func probeTCP(name, port string, timeout time.Duration) error {
+	addr, err := util.PublishedAddress(name, port)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func probeHTTP(name, port, path string, timeout time.Duration) error {
+	addr, err := util.PublishedAddress(name, port)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(fmt.Sprintf("http://%s%s", addr, path))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return fmt.Errorf("health check GET %s returned %s", path, resp.Status)
+	}
+	return nil
+}
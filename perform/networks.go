@@ -0,0 +1,113 @@
+package perform
+
+import (
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/util"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// configureNetworks sets the network mode of the container to be created:
+//
+//  srv.Net       - use the Docker network mode as is
+//  srv.Networks  - attach to the first user-defined network at creation
+//                  time (others are connected by createServiceContainer);
+//                  srv.Links become network-scoped links
+//
+// Containers which have none of those are attached to the default bridge
+// network and use legacy links. If aliases is true, the container answers
+// to srv.Name and srv.Aliases on its user-defined networks.
+func configureNetworks(opts *docker.CreateContainerOptions, srv *def.Service, aliases bool) {
+	switch {
+	case srv.Net != "":
+		opts.HostConfig.NetworkMode = srv.Net
+	case len(srv.Networks) != 0:
+		opts.HostConfig.NetworkMode = srv.Networks[0]
+		opts.HostConfig.Links = nil
+		opts.NetworkingConfig = &docker.NetworkingConfig{
+			EndpointsConfig: map[string]*docker.EndpointConfig{
+				srv.Networks[0]: endpointConfig(srv, aliases),
+			},
+		}
+	default:
+		opts.HostConfig.NetworkMode = "bridge"
+	}
+}
+
+func endpointConfig(srv *def.Service, aliases bool) *docker.EndpointConfig {
+	config := &docker.EndpointConfig{
+		Links: srv.Links,
+	}
+	if aliases {
+		config.Aliases = append([]string{srv.Name}, srv.Aliases...)
+	}
+	return config
+}
+
+// createServiceContainer creates the srv networks if they are missing and
+// attaches the containers srv links to to the network the new container is
+// going to use, so that links can be resolved. It then creates the container
+// and attaches it to the rest of its networks.
+func createServiceContainer(opts docker.CreateContainerOptions, srv *def.Service, aliases bool) error {
+	for _, network := range srv.Networks {
+		if err := util.EnsureNetwork(network); err != nil {
+			return err
+		}
+	}
+
+	if mode := opts.HostConfig.NetworkMode; mode == "bridge" || util.IsUserNetwork(mode) {
+		for _, link := range srv.Links {
+			if err := connectContainer(strings.Split(link, ":")[0], mode, nil); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := createContainer(opts); err != nil {
+		return err
+	}
+
+	return connectNetworks(opts.Name, srv, aliases)
+}
+
+// connectNetworks attaches an existing container to those of the
+// srv networks it is not attached to yet.
+func connectNetworks(name string, srv *def.Service, aliases bool) error {
+	if srv.Net != "" {
+		return nil
+	}
+
+	for _, network := range srv.Networks {
+		if err := util.EnsureNetwork(network); err != nil {
+			return err
+		}
+		if err := connectContainer(name, network, endpointConfig(srv, aliases)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func connectContainer(name, network string, config *docker.EndpointConfig) error {
+	container, err := util.DockerClient.InspectContainer(name)
+	if err != nil {
+		// Let Docker complain about the missing container later, if needed.
+		log.WithField("=>", name).Debug("Container not found. Not connecting to network")
+		return nil
+	}
+	if _, ok := container.NetworkSettings.Networks[network]; ok {
+		return nil
+	}
+
+	log.WithFields(log.Fields{
+		"=>":      name,
+		"network": network,
+	}).Info("Connecting container to network")
+	return util.DockerError(util.DockerClient.ConnectNetwork(network, docker.NetworkConnectionOptions{
+		Container:      name,
+		EndpointConfig: config,
+	}))
+}
@@ -14,6 +14,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"unicode"
 
 	log "github.com/Sirupsen/logrus"
@@ -109,7 +110,8 @@ func DockerRunData(ops *def.Operation, service *def.Service) (result []byte, err
 	}
 
 	log.WithField("=>", opts.Name).Info("Getting logs from container")
-	if err = logsContainer(opts.Name, true, "all"); err != nil {
+	stdout, stderr := logWriters()
+	if err = logsContainer(opts.Name, true, "all", stdout, stderr); err != nil {
 		return nil, err
 	}
 
@@ -179,6 +181,9 @@ func DockerExecData(ops *def.Operation, service *def.Service) (buf *bytes.Buffer
 // is true. DockerRunService returns Docker errors if not successful.
 //
 //  srv.AutoData          - if true, create or use existing data container
+//                          (or data volume, see srv.Storage)
+//  srv.Storage           - "volume" to keep new data in a named volume
+//                          instead of a data container
 //  srv.Restart           - container restart policy ("always", "max:<#attempts>"
 //                          or never if unspecified)
 //
@@ -194,6 +199,8 @@ func DockerExecData(ops *def.Operation, service *def.Service) (buf *bytes.Buffer
 //  ops.CapAdd            - add linux capabilities (similar to `docker run --cap-add=[]`)
 //  ops.CapDrop           - add linux capabilities (similar to `docker run --cap-drop=[]`)
 //  ops.Privileged        - if true, give extended privileges
+//  ops.Wait              - if true, wait for the container to become ready
+//                          even if srv.HealthCheck is not set (see DockerWaitReady)
 //
 func DockerRunService(srv *def.Service, ops *def.Operation) error {
 	log.WithField("=>", ops.SrvContainerName).Info("Running container")
@@ -201,6 +208,12 @@ func DockerRunService(srv *def.Service, ops *def.Operation) error {
 	running := ContainerRunning(ops.SrvContainerName)
 	if running {
 		log.WithField("=>", ops.SrvContainerName).Info("Container already started. Skipping")
+		if err := connectNetworks(ops.SrvContainerName, srv, true); err != nil {
+			return err
+		}
+		if ops.Wait {
+			return DockerWaitReady(srv, ops)
+		}
 		return nil
 	}
 
@@ -209,30 +222,41 @@ func DockerRunService(srv *def.Service, ops *def.Operation) error {
 	// Setup data container.
 	log.WithField("autodata", srv.AutoData).Info("Manage data containers?")
 	if srv.AutoData {
-		optsData, err := configureDataContainer(srv, ops, &optsServ)
-		if err != nil {
+		if err := setupData(srv, ops, &optsServ); err != nil {
 			return err
 		}
+	}
 
-		if exists := util.FindContainer(ops.DataContainerName, false); exists {
-			log.Info("Data container already exists. Not creating")
-		} else {
-			log.Info("Data container does not exist. Creating")
-			_, err := createContainer(optsData)
-			if err != nil {
+	// Containers of services built locally are created anew if the
+	// image was rebuilt since.
+	exists := ContainerExists(ops.SrvContainerName)
+	if exists && srv.Build != nil {
+		outdated, err := containerOutdated(srv, ops.SrvContainerName)
+		if err != nil {
+			return err
+		}
+		if outdated {
+			log.WithField("=>", ops.SrvContainerName).Warn("Image rebuilt. Recreating container")
+			if err := removeContainer(ops.SrvContainerName, false, true); err != nil {
 				return err
 			}
+			exists = false
 		}
 	}
 
 	// Check existence || create the container.
-	if exists := ContainerExists(ops.SrvContainerName); exists {
+	if exists {
 		log.Debug("Container already exists. Not creating")
+		if err := connectNetworks(ops.SrvContainerName, srv, true); err != nil {
+			return err
+		}
 	} else {
 		log.WithField("image", srv.Image).Debug("Container does not exist. Creating")
 
-		_, err := createContainer(optsServ)
-		if err != nil {
+		if err := DockerBuildService(srv, false); err != nil {
+			return err
+		}
+		if err := createServiceContainer(optsServ, srv, true); err != nil {
 			return err
 		}
 	}
@@ -260,6 +284,10 @@ func DockerRunService(srv *def.Service, ops *def.Operation) error {
 
 	log.WithField("=>", optsServ.Name).Info("Container started")
 
+	if (srv.HealthCheck != nil || ops.Wait) && !ops.Remove {
+		return DockerWaitReady(srv, ops)
+	}
+
 	return nil
 }
 
@@ -279,26 +307,16 @@ func DockerExecService(srv *def.Service, ops *def.Operation) (buf *bytes.Buffer,
 	log.WithField("autodata", srv.AutoData).Info("Manage data containers?")
 
 	if srv.AutoData {
-		optsData, err := configureDataContainer(srv, ops, &optsServ)
-		if err != nil {
+		if err := setupData(srv, ops, &optsServ); err != nil {
 			return nil, err
 		}
-
-		if exists := util.FindContainer(ops.DataContainerName, false); exists {
-			log.Info("Data container already exists, am not creating")
-		} else {
-			log.Info("Data container does not exist. Creating")
-
-			_, err := createContainer(optsData)
-			if err != nil {
-				return nil, err
-			}
-		}
 	}
 
 	log.WithField("image", srv.Image).Debug("Container does not exist. Creating")
-	_, err = createContainer(optsServ)
-	if err != nil {
+	if err := DockerBuildService(srv, false); err != nil {
+		return nil, err
+	}
+	if err := createServiceContainer(optsServ, srv, false); err != nil {
 		return nil, err
 	}
 
@@ -343,7 +361,8 @@ func DockerExecService(srv *def.Service, ops *def.Operation) (buf *bytes.Buffer,
 
 // DockerRebuild recreates the container based on the srv settings template.
 // If pullImage is true, it updates the Docker image before recreating
-// the container. Timeout is a number of seconds to wait before killing the
+// the container (locally built images are rebuilt instead if stale, see
+// DockerBuildService). Timeout is a number of seconds to wait before killing the
 // container process ungracefully.
 //
 //  ops.SrvContainerName  - service or a chain container name to rebuild
@@ -376,7 +395,11 @@ func DockerRebuild(srv *def.Service, ops *def.Operation, pullImage bool, timeout
 		return nil
 	}
 
-	if pullImage {
+	if srv.Build != nil {
+		if err := DockerBuildService(srv, false); err != nil {
+			return err
+		}
+	} else if pullImage {
 		log.WithField("image", srv.Image).Info("Pulling image")
 		err := DockerPull(srv, ops)
 		if err != nil {
@@ -387,8 +410,7 @@ func DockerRebuild(srv *def.Service, ops *def.Operation, pullImage bool, timeout
 	opts := configureServiceContainer(srv, ops)
 
 	log.WithField("=>", ops.SrvContainerName).Info("Recreating container")
-	_, err := createContainer(opts)
-	if err != nil {
+	if err := createServiceContainer(opts, srv, true); err != nil {
 		return err
 	}
 
@@ -434,11 +456,11 @@ func DockerPull(srv *def.Service, ops *def.Operation) error {
 	}
 
 	if log.GetLevel() > 0 {
-		if err := pullImage(srv.Image, os.Stdout); err != nil {
+		if err := util.PullImage(srv.Image, os.Stdout); err != nil {
 			return err
 		}
 	} else {
-		if err := pullImage(srv.Image, ioutil.Discard); err != nil {
+		if err := util.PullImage(srv.Image, ioutil.Discard); err != nil {
 			return err
 		}
 	}
@@ -456,13 +478,27 @@ func DockerPull(srv *def.Service, ops *def.Operation) error {
 // output. If follow is true, it behaves like `tail -f`. It returns Docker
 // errors on exit if not successful.
 func DockerLogs(srv *def.Service, ops *def.Operation, follow bool, tail string) error {
+	stdout, stderr := logWriters()
+	return DockerLogsTo(srv, ops, follow, tail, stdout, stderr)
+}
+
+// DockerLogsTo is DockerLogs writing the container output to stdout and
+// stderr rather than to the configured writers.
+func DockerLogsTo(srv *def.Service, ops *def.Operation, follow bool, tail string, stdout, stderr io.Writer) error {
 	if exists := ContainerExists(ops.SrvContainerName); exists {
 		log.WithFields(log.Fields{
 			"=>":     ops.SrvContainerName,
 			"follow": follow,
 			"tail":   tail,
 		}).Info("Getting logs")
-		if err := logsContainer(ops.SrvContainerName, follow, tail); err != nil {
+		if driver := logDriver(ops.SrvContainerName); !readableLogDrivers[driver] {
+			log.WithFields(log.Fields{
+				"=>":     ops.SrvContainerName,
+				"driver": driver,
+			}).Warn("Logs cannot be read back with this logging driver. Check the driver destination instead")
+			return nil
+		}
+		if err := logsContainer(ops.SrvContainerName, follow, tail, stdout, stderr); err != nil {
 			return err
 		}
 	} else {
@@ -633,6 +669,12 @@ func DockerRemove(srv *def.Service, ops *def.Operation, withData, volumes, force
 					return err
 				}
 			}
+			if name := ops.Labels[def.LabelShortName]; name != "" && util.IsDataVolume(name) {
+				log.WithField("=>", util.DataVolumeName(name)).Info("Removing dependent data volume")
+				if err := util.DockerClient.RemoveVolume(util.DataVolumeName(name)); err != nil {
+					return util.DockerError(err)
+				}
+			}
 		}
 	} else {
 		log.Info("Container does not exist. Cannot remove")
@@ -722,63 +764,25 @@ func ContainerRunning(name string) bool {
 	return util.FindContainer(name, true)
 }
 
-func pullImage(name string, writer io.Writer) error {
-	var tag string = "latest"
-	var reg string = ""
-
-	nameSplit := strings.Split(name, ":")
-	if len(nameSplit) == 2 {
-		tag = nameSplit[1]
-	}
-	if len(nameSplit) == 3 {
-		tag = nameSplit[2]
-	}
-	name = nameSplit[0]
-
-	repoSplit := strings.Split(nameSplit[0], "/")
-	if len(repoSplit) > 2 {
-		reg = repoSplit[0]
-	}
-
-	r, w := io.Pipe()
-	opts := docker.PullImageOptions{
-		Repository:    name,
-		Registry:      reg,
-		Tag:           tag,
-		OutputStream:  w,
-		RawJSONStream: true,
-	}
-
-	if os.Getenv("ERIS_PULL_APPROVE") == "true" {
-		opts.OutputStream = ioutil.Discard
-	}
-
-	auth := docker.AuthConfiguration{}
-
-	ch := make(chan error, 1)
-	go func() {
-		defer w.Close()
-		defer close(ch)
-
-		if err := util.DockerClient.PullImage(opts, auth); err != nil {
-			ch <- util.DockerError(err)
-		}
-	}()
-	jsonmessage.DisplayJSONMessagesStream(r, writer, os.Stdout.Fd(), term.IsTerminal(os.Stdout.Fd()), nil)
-	if err, ok := <-ch; ok {
-		return err
-	}
-
-	return nil
-}
-
 // ----------------------------------------------------------------------------
 // ---------------------    Container Core ------------------------------------
 // ----------------------------------------------------------------------------
+// pullLock serializes pull prompts and pulls of missing images when
+// containers are created concurrently (e.g. by services.StartGroup).
+var pullLock sync.Mutex
+
 func createContainer(opts docker.CreateContainerOptions) (*docker.Container, error) {
 	dockerContainer, err := util.DockerClient.CreateContainer(opts)
 	if err != nil {
 		if err == docker.ErrNoSuchImage {
+			pullLock.Lock()
+			defer pullLock.Unlock()
+
+			// The image might have been pulled while waiting for the lock.
+			if dockerContainer, err = util.DockerClient.CreateContainer(opts); err != docker.ErrNoSuchImage {
+				return dockerContainer, util.DockerError(err)
+			}
+
 			if os.Getenv("ERIS_PULL_APPROVE") != "true" {
 				log.WithField("image", opts.Config.Image).Warn("The Docker image not found locally")
 				if util.QueryYesOrNo("Would you like the marmots to pull it from the repository?") == util.Yes {
@@ -792,8 +796,8 @@ func createContainer(opts docker.CreateContainerOptions) (*docker.Container, err
 				log.Warn("The marmots are approved to pull it from the repository on your behalf")
 				log.Warn("This could take a few minutes")
 			}
-			if err := pullImage(opts.Config.Image, os.Stdout); err != nil {
-				return nil, util.DockerError(err)
+			if err := util.PullImage(opts.Config.Image, os.Stdout); err != nil {
+				return nil, err
 			}
 			dockerContainer, err = util.DockerClient.CreateContainer(opts)
 			if err != nil {
@@ -827,8 +831,10 @@ func startInteractiveContainer(opts docker.CreateContainerOptions) error {
 	}()
 
 	attached := make(chan struct{})
+	detached := make(chan struct{})
 	go func(chan struct{}) {
 		attachContainer(opts.Name, attached)
+		close(detached)
 	}(attached)
 
 	// Wait for a console prompt to appear.
@@ -851,11 +857,12 @@ func startInteractiveContainer(opts docker.CreateContainerOptions) error {
 		defer term.RestoreTerminal(os.Stdin.Fd(), savedState)
 	}
 
-	if err := waitContainer(opts.Name); err != nil {
-		return err
-	}
+	err = waitContainer(opts.Name)
 
-	return nil
+	// The container output may still be streaming after it exits.
+	<-detached
+
+	return err
 }
 
 func attachContainer(id string, attached chan struct{}) error {
@@ -896,16 +903,38 @@ func waitContainer(id string) error {
 	return err
 }
 
-func logsContainer(id string, follow bool, tail string) error {
-	var writer io.Writer
-	var eWriter io.Writer
+// readableLogDrivers are the logging drivers Docker can read logs back
+// from (an empty driver is the daemon default).
+var readableLogDrivers = map[string]bool{
+	"":          true,
+	"json-file": true,
+	"journald":  true,
+}
+
+// logDriver returns the logging driver of the container id, or an empty
+// string if it can't be determined.
+func logDriver(id string) string {
+	cont, err := util.DockerClient.InspectContainer(id)
+	if err != nil || cont.HostConfig == nil {
+		return ""
+	}
+	return cont.HostConfig.LogConfig.Type
+}
 
+// logWriters returns the configured output and error writers.
+func logWriters() (io.Writer, io.Writer) {
 	if config.GlobalConfig != nil {
-		writer = config.GlobalConfig.Writer
-		eWriter = config.GlobalConfig.ErrorWriter
-	} else {
-		writer = os.Stdout
-		eWriter = os.Stderr
+		return config.GlobalConfig.Writer, config.GlobalConfig.ErrorWriter
+	}
+	return os.Stdout, os.Stderr
+}
+
+func logsContainer(id string, follow bool, tail string, writer, eWriter io.Writer) error {
+	// The output of containers without a TTY comes multiplexed
+	// and has to be split into stdout and stderr.
+	tty := true
+	if cont, err := util.DockerClient.InspectContainer(id); err == nil && cont.Config != nil {
+		tty = cont.Config.Tty
 	}
 
 	opts := docker.LogsOptions{
@@ -919,7 +948,7 @@ func logsContainer(id string, follow bool, tail string) error {
 		Timestamps:   false,
 		Tail:         tail,
 
-		RawTerminal: true, // Usually true when the container contains a TTY.
+		RawTerminal: tty,
 	}
 
 	if err := util.DockerClient.Logs(opts); err != nil {
@@ -1001,8 +1030,10 @@ func configureInteractiveContainer(srv *def.Service, ops *def.Operation) docker.
 		}
 	}
 
-	// We expect to link to the main service container.
+	// We expect to link to the main service container (but not to
+	// answer to its aliases).
 	opts.HostConfig.Links = srv.Links
+	configureNetworks(&opts, srv, false)
 
 	// Ignore the restart policy of a container.
 	opts.HostConfig.RestartPolicy = docker.NeverRestart()
@@ -1010,6 +1041,32 @@ func configureInteractiveContainer(srv *def.Service, ops *def.Operation) docker.
 	return opts
 }
 
+// logConfig returns the logging driver settings for the service container.
+// The driver set in the service definition wins; otherwise the default from
+// eris.toml (if any) is used, with the service log_opts overriding the
+// default options. An empty driver means the Docker daemon default.
+func logConfig(srv *def.Service) docker.LogConfig {
+	if srv.LogDriver != "" {
+		return docker.LogConfig{Type: srv.LogDriver, Config: srv.LogOpts}
+	}
+
+	if config.GlobalConfig == nil || config.GlobalConfig.Config == nil || config.GlobalConfig.Config.LogDriver == "" {
+		return docker.LogConfig{Config: srv.LogOpts}
+	}
+
+	logs := docker.LogConfig{
+		Type:   config.GlobalConfig.Config.LogDriver,
+		Config: make(map[string]string),
+	}
+	for k, v := range config.GlobalConfig.Config.LogOpts {
+		logs.Config[k] = v
+	}
+	for k, v := range srv.LogOpts {
+		logs.Config[k] = v
+	}
+	return logs
+}
+
 func configureServiceContainer(srv *def.Service, ops *def.Operation) docker.CreateContainerOptions {
 
 	opts := docker.CreateContainerOptions{
@@ -1035,17 +1092,35 @@ func configureServiceContainer(srv *def.Service, ops *def.Operation) docker.Crea
 			Links:           srv.Links,
 			PublishAllPorts: ops.PublishAllPorts,
 			Privileged:      ops.Privileged,
-			ReadonlyRootfs:  false,
 			DNS:             srv.DNS,
 			DNSSearch:       srv.DNSSearch,
 			VolumesFrom:     srv.VolumesFrom,
 			CapAdd:          ops.CapAdd,
 			CapDrop:         ops.CapDrop,
 			RestartPolicy:   docker.NeverRestart(), //default. overide below
-			NetworkMode:     "bridge",
+
+			Memory:            srv.MemLimit,
+			MemorySwap:        srv.MemSwap,
+			MemoryReservation: srv.MemReservation,
+			CPUShares:         srv.CPUShares,
+			CPUQuota:          srv.CPUQuota,
+			CPUPeriod:         srv.CPUPeriod,
+			CPUSetCPUs:        srv.CPUSet,
+			PidsLimit:         srv.PidsLimit,
+			ReadonlyRootfs:    srv.ReadOnly,
+			SecurityOpt:       srv.SecurityOpt,
+			OomScoreAdj:       srv.OOMScoreAdj,
+			ShmSize:           srv.ShmSize,
 		},
 	}
 
+	// Both are checked when the definition is loaded.
+	opts.HostConfig.Ulimits, _ = util.Ulimits(srv.Ulimits)
+	opts.HostConfig.Tmpfs, _ = util.Tmpfs(srv.Tmpfs)
+	opts.HostConfig.LogConfig = logConfig(srv)
+
+	configureNetworks(&opts, srv, true)
+
 	// some fields may be set in the dockerfile and we only want to overwrite if they are present in the service def
 	if srv.EntryPoint != "" {
 		opts.Config.Entrypoint = strings.Fields(srv.EntryPoint)
@@ -1113,9 +1188,14 @@ func configureVolumesFromContainer(ops *def.Operation, service *def.Service) doc
 			NetworkDisabled: false,
 			Labels:          ops.Labels,
 		},
-		HostConfig: &docker.HostConfig{
-			VolumesFrom: []string{ops.DataContainerName},
-		},
+		HostConfig: &docker.HostConfig{},
+	}
+
+	// Mount the data volume if the data is kept in one.
+	if ops.DataVolumeName != "" {
+		opts.HostConfig.Binds = []string{dataVolumeBind(ops.DataVolumeName)}
+	} else {
+		opts.HostConfig.VolumesFrom = []string{ops.DataContainerName}
 	}
 
 	opts.Config.OpenStdin = true
@@ -1195,6 +1275,52 @@ func configureDataContainer(srv *def.Service, ops *def.Operation, mainContOpts *
 	return opts, nil
 }
 
+// setupData creates the data container or the data volume of the srv
+// service or chain, unless it exists, and mounts it in the container
+// configured by opts. The data volume is used if it exists or if srv asks
+// for one (see util.DataStorage); ops.DataVolumeName is set then.
+func setupData(srv *def.Service, ops *def.Operation, opts *docker.CreateContainerOptions) error {
+	name := ops.Labels[def.LabelShortName]
+	if name == "" {
+		name = srv.Name
+	}
+
+	storage, err := util.DataStorage(name, srv)
+	if err != nil {
+		return err
+	}
+	log.WithField("storage", storage).Info("Using data storage")
+
+	if storage == def.StorageVolume {
+		ops.DataVolumeName = util.DataVolumeName(name)
+		opts.HostConfig.Binds = append(append([]string{}, opts.HostConfig.Binds...), dataVolumeBind(ops.DataVolumeName))
+
+		if _, err := util.DockerClient.InspectVolume(ops.DataVolumeName); err == nil {
+			log.Info("Data volume already exists. Not creating")
+			return nil
+		}
+		log.Info("Data volume does not exist. Creating")
+		return createDataVolume(ops.DataVolumeName, ops.Labels, opts.Name)
+	}
+
+	if srv.Storage == def.StorageVolume {
+		log.WithField("=>", name).Warn("The data is kept in a data container. Use [eris data migrate] to move it to a volume")
+	}
+
+	optsData, err := configureDataContainer(srv, ops, opts)
+	if err != nil {
+		return err
+	}
+
+	if exists := util.FindContainer(ops.DataContainerName, false); exists {
+		log.Info("Data container already exists. Not creating")
+		return nil
+	}
+	log.Info("Data container does not exist. Creating")
+	_, err = createContainer(optsData)
+	return err
+}
+
 func checkImageExists(imageName string) (bool, error) {
 	fail := false
 
@@ -2,6 +2,7 @@ package perform
 
 import (
 	"bytes"
+	"io/ioutil"
 	"os"
 	"path"
 	"strings"
@@ -244,6 +245,275 @@ func TestRunServiceNoDataContainer(t *testing.T) {
 	}
 }
 
+func TestRunServiceWaitHealthCheck(t *testing.T) {
+	const (
+		name = "ipfs"
+	)
+
+	defer tests.RemoveAllContainers()
+
+	srv, err := loaders.LoadServiceDefinition(name, true)
+	if err != nil {
+		t.Fatalf("could not load service definition %v", err)
+	}
+
+	srv.Service.HealthCheck = &def.HealthCheck{
+		Command: "true",
+		Retries: 3,
+	}
+	if err := DockerRunService(srv.Service, srv.Operations); err != nil {
+		t.Fatalf("expected service container to become ready, got %v", err)
+	}
+
+	if !util.Running(def.TypeService, name) {
+		t.Fatalf("expecting service container running")
+	}
+}
+
+func TestRunServiceWaitHealthCheckTimeout(t *testing.T) {
+	const (
+		name = "ipfs"
+	)
+
+	defer tests.RemoveAllContainers()
+
+	srv, err := loaders.LoadServiceDefinition(name, true)
+	if err != nil {
+		t.Fatalf("could not load service definition %v", err)
+	}
+
+	srv.Service.HealthCheck = &def.HealthCheck{
+		Command: "false",
+		Retries: 2,
+	}
+	if err := DockerRunService(srv.Service, srv.Operations); err == nil {
+		t.Fatalf("expected health check to time out, got %v", err)
+	}
+}
+
+func TestRunServiceWaitNoHealthCheck(t *testing.T) {
+	const (
+		name = "ipfs"
+	)
+
+	defer tests.RemoveAllContainers()
+
+	srv, err := loaders.LoadServiceDefinition(name, true)
+	if err != nil {
+		t.Fatalf("could not load service definition %v", err)
+	}
+
+	srv.Operations.Wait = true
+	if err := DockerRunService(srv.Service, srv.Operations); err != nil {
+		t.Fatalf("expected service container to become ready, got %v", err)
+	}
+
+	if !util.Running(def.TypeService, name) {
+		t.Fatalf("expecting service container running")
+	}
+}
+
+func TestRunServiceWaitExited(t *testing.T) {
+	const (
+		name = "ipfs"
+	)
+
+	defer tests.RemoveAllContainers()
+
+	srv, err := loaders.LoadServiceDefinition(name, true)
+	if err != nil {
+		t.Fatalf("could not load service definition %v", err)
+	}
+
+	srv.Service.EntryPoint = "false"
+	srv.Service.Command = ""
+	srv.Operations.Wait = true
+	if err := DockerRunService(srv.Service, srv.Operations); err == nil {
+		t.Fatalf("expected an error for an exited container, got %v", err)
+	}
+}
+
+func TestRunServiceNetworks(t *testing.T) {
+	const (
+		name    = "ipfs"
+		network = "eris_test_network"
+	)
+
+	defer tests.RemoveAllContainers()
+	defer util.RemoveNetwork(network)
+
+	srv, err := loaders.LoadServiceDefinition(name, true)
+	if err != nil {
+		t.Fatalf("could not load service definition %v", err)
+	}
+
+	srv.Service.Networks = []string{network}
+	srv.Service.Aliases = []string{"files"}
+	if err := DockerRunService(srv.Service, srv.Operations); err != nil {
+		t.Fatalf("expected service container created, got %v", err)
+	}
+
+	container, err := util.DockerClient.InspectContainer(srv.Operations.SrvContainerName)
+	if err != nil {
+		t.Fatalf("expected to inspect the container, got %v", err)
+	}
+	if _, ok := container.NetworkSettings.Networks[network]; !ok {
+		t.Fatalf("expected container attached to %v, got %v", network, container.NetworkSettings.Networks)
+	}
+	if container.HostConfig.NetworkMode != network {
+		t.Fatalf("expected network mode %v, got %v", network, container.HostConfig.NetworkMode)
+	}
+}
+
+func TestRunServiceResourceLimits(t *testing.T) {
+	const (
+		name = "ipfs"
+	)
+
+	defer tests.RemoveAllContainers()
+
+	srv, err := loaders.LoadServiceDefinition(name, true)
+	if err != nil {
+		t.Fatalf("could not load service definition %v", err)
+	}
+
+	srv.Service.MemLimit = 512 * 1024 * 1024
+	srv.Service.MemSwap = 512 * 1024 * 1024
+	srv.Service.CPUQuota = 50000
+	srv.Service.CPUPeriod = 100000
+	srv.Service.PidsLimit = 256
+	srv.Service.Ulimits = []string{"nofile=4096:8192"}
+	srv.Service.Tmpfs = []string{"/tmp:rw,size=64m"}
+	srv.Service.OOMScoreAdj = 500
+	if err := DockerRunService(srv.Service, srv.Operations); err != nil {
+		t.Fatalf("expected service container created, got %v", err)
+	}
+
+	container, err := util.DockerClient.InspectContainer(srv.Operations.SrvContainerName)
+	if err != nil {
+		t.Fatalf("expected to inspect the container, got %v", err)
+	}
+	host := container.HostConfig
+	if host.Memory != srv.Service.MemLimit || host.MemorySwap != srv.Service.MemSwap {
+		t.Fatalf("expected memory limits %v/%v, got %v/%v", srv.Service.MemLimit, srv.Service.MemSwap, host.Memory, host.MemorySwap)
+	}
+	if host.CPUQuota != 50000 || host.CPUPeriod != 100000 {
+		t.Fatalf("expected CPU quota 50000/100000, got %v/%v", host.CPUQuota, host.CPUPeriod)
+	}
+	if host.PidsLimit != 256 || host.OomScoreAdj != 500 {
+		t.Fatalf("expected pids limit 256 and OOM score 500, got %v and %v", host.PidsLimit, host.OomScoreAdj)
+	}
+	if len(host.Ulimits) != 1 || host.Ulimits[0].Soft != 4096 || host.Ulimits[0].Hard != 8192 {
+		t.Fatalf("expected nofile ulimit 4096:8192, got %v", host.Ulimits)
+	}
+	if host.Tmpfs["/tmp"] != "rw,size=64m" {
+		t.Fatalf("expected tmpfs mount on /tmp, got %v", host.Tmpfs)
+	}
+}
+
+func TestRunServiceLogDriver(t *testing.T) {
+	const (
+		name = "ipfs"
+	)
+
+	defer tests.RemoveAllContainers()
+
+	srv, err := loaders.LoadServiceDefinition(name, true)
+	if err != nil {
+		t.Fatalf("could not load service definition %v", err)
+	}
+
+	srv.Service.LogDriver = "json-file"
+	srv.Service.LogOpts = map[string]string{"max-size": "10m", "max-file": "3"}
+	if err := DockerRunService(srv.Service, srv.Operations); err != nil {
+		t.Fatalf("expected service container created, got %v", err)
+	}
+
+	container, err := util.DockerClient.InspectContainer(srv.Operations.SrvContainerName)
+	if err != nil {
+		t.Fatalf("expected to inspect the container, got %v", err)
+	}
+	logs := container.HostConfig.LogConfig
+	if logs.Type != "json-file" || logs.Config["max-size"] != "10m" || logs.Config["max-file"] != "3" {
+		t.Fatalf("expected json-file driver with rotation options, got %v", logs)
+	}
+
+	if err := DockerLogs(srv.Service, srv.Operations, false, "all"); err != nil {
+		t.Fatalf("expected logs to be read back, got %v", err)
+	}
+}
+
+func TestLogConfigDefault(t *testing.T) {
+	saved := config.GlobalConfig.Config
+	defer func() { config.GlobalConfig.Config = saved }()
+
+	config.GlobalConfig.Config = &config.ErisConfig{
+		LogDriver: "json-file",
+		LogOpts:   map[string]string{"max-size": "10m", "max-file": "3"},
+	}
+
+	srv := def.BlankService()
+	srv.LogOpts = map[string]string{"max-file": "5"}
+	if logs := logConfig(srv); logs.Type != "json-file" || logs.Config["max-size"] != "10m" || logs.Config["max-file"] != "5" {
+		t.Fatalf("expected default driver with overridden options, got %v", logs)
+	}
+
+	srv.LogDriver = "syslog"
+	srv.LogOpts = nil
+	if logs := logConfig(srv); logs.Type != "syslog" || len(logs.Config) != 0 {
+		t.Fatalf("expected syslog driver without options, got %v", logs)
+	}
+}
+
+func TestRunServiceBuild(t *testing.T) {
+	const (
+		name  = "ipfs"
+		image = "eris/test_build:local"
+	)
+
+	defer tests.RemoveAllContainers()
+	defer DockerRemoveImage(image, true)
+
+	context, err := ioutil.TempDir("", "eris-build")
+	if err != nil {
+		t.Fatalf("expected temp dir, got %v", err)
+	}
+	defer os.RemoveAll(context)
+
+	dockerfile := "FROM quay.io/eris/base\nARG greeting\nRUN echo $greeting > /greeting\n"
+	if err := ioutil.WriteFile(path.Join(context, "Dockerfile.test"), []byte(dockerfile), 0644); err != nil {
+		t.Fatalf("expected Dockerfile written, got %v", err)
+	}
+
+	srv, err := loaders.LoadServiceDefinition(name, true)
+	if err != nil {
+		t.Fatalf("could not load service definition %v", err)
+	}
+
+	srv.Service.Image = image
+	srv.Service.Build = &def.Build{
+		Context:    context,
+		Dockerfile: "Dockerfile.test",
+		Args:       map[string]string{"greeting": "hello"},
+	}
+	if err := DockerRunService(srv.Service, srv.Operations); err != nil {
+		t.Fatalf("expected service container created, got %v", err)
+	}
+
+	built, err := util.DockerClient.InspectImage(image)
+	if err != nil {
+		t.Fatalf("expected image to be built, got %v", err)
+	}
+
+	// Nothing changed in the build context since.
+	if err := DockerBuildService(srv.Service, false); err != nil {
+		t.Fatalf("expected build to be skipped, got %v", err)
+	}
+	if image, err := util.DockerClient.InspectImage(image); err != nil || image.ID != built.ID {
+		t.Fatalf("expected image %v left alone, got %v (error %v)", built.ID, image, err)
+	}
+}
+
 func TestExecServiceSimple(t *testing.T) {
 	const (
 		name = "ipfs"
@@ -274,6 +544,46 @@ func TestExecServiceSimple(t *testing.T) {
 	}
 }
 
+func TestExecServiceAttach(t *testing.T) {
+	const (
+		name = "ipfs"
+	)
+
+	defer tests.RemoveAllContainers()
+
+	srv, err := loaders.LoadServiceDefinition(name, true)
+	if err != nil {
+		t.Fatalf("could not load service definition %v", err)
+	}
+
+	srv.Operations.Args = strings.Fields("echo hello")
+	if _, err := DockerExecAttach(srv.Service, srv.Operations); err == nil {
+		t.Fatalf("expected exec into a stopped service to fail")
+	}
+
+	if err := DockerRunService(srv.Service, srv.Operations); err != nil {
+		t.Fatalf("expected service container created, got %v", err)
+	}
+
+	buf, err := DockerExecAttach(srv.Service, srv.Operations)
+	if err != nil {
+		t.Fatalf("expected exec to succeed, got %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != "hello" {
+		t.Fatalf("expected exec output %q, got %q", "hello", buf.String())
+	}
+
+	srv.Operations.Args = []string{"sh", "-c", "exit 3"}
+	if _, err := DockerExecAttach(srv.Service, srv.Operations); err != (ExecError{Container: srv.Operations.SrvContainerName, Code: 3}) {
+		t.Fatalf("expected exit status 3, got %v", err)
+	}
+
+	// No new containers were created.
+	if containers := util.ErisContainersByType(def.TypeService, true); len(containers) != 1 {
+		t.Fatalf("expected one service container, got %v", containers)
+	}
+}
+
 func TestExecServiceBufferNotOverwritten(t *testing.T) {
 	const (
 		name = "ipfs"
@@ -0,0 +1,14 @@
+// +build !windows
+
+package perform
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyResize relays terminal window size changes to c.
+func notifyResize(c chan<- os.Signal) {
+	signal.Notify(c, syscall.SIGWINCH)
+}
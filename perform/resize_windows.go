@@ -0,0 +1,9 @@
+package perform
+
+import (
+	"os"
+)
+
+// notifyResize does nothing on Windows, which has no SIGWINCH: the exec
+// TTY is only sized when the session starts.
+func notifyResize(c chan<- os.Signal) {}
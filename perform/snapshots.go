@@ -0,0 +1,185 @@
+package perform
+
+import (
+	"io"
+	"path"
+
+	log "github.com/Sirupsen/logrus"
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/util"
+
+	dirs "github.com/eris-ltd/common/go/common"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// DataOwner returns the details of the service or chain container the data
+// belongs to, or nil if there is none. The owner is the container the data
+// was created for or, failing that, a chain or a service container with
+// the same short name.
+//
+//  ops.DataContainerName - data container
+//  ops.DataVolumeName    - data volume (takes precedence if set)
+//  ops.Labels            - data labels (use LoadDataDefinition)
+//
+func DataOwner(ops *def.Operation) *util.Details {
+	var owner string
+	if ops.DataVolumeName != "" {
+		if volume, err := util.DockerClient.InspectVolume(ops.DataVolumeName); err == nil {
+			owner = volume.Labels[def.LabelService]
+		}
+	} else if data, err := util.DockerClient.InspectContainer(ops.DataContainerName); err == nil {
+		owner = data.Config.Labels[def.LabelService]
+	}
+
+	if owner == "" || !ContainerExists(owner) {
+		owner = ""
+		for _, t := range []string{def.TypeChain, def.TypeService} {
+			if name, err := util.Lookup(t, ops.Labels[def.LabelShortName]); err == nil && ContainerExists(name) {
+				owner = name
+				break
+			}
+		}
+	}
+	if owner == "" {
+		return nil
+	}
+
+	if details := util.ContainerDetails(owner); details.Info != nil {
+		return details
+	}
+	return nil
+}
+
+// DockerSnapshotData writes a tar archive of the eris root directory of
+// a data container or a data volume to w. The archive has the directory
+// itself at the top.
+//
+//  ops.DataContainerName - data container
+//  ops.DataVolumeName    - data volume (takes precedence if set)
+//
+func DockerSnapshotData(ops *def.Operation, w io.Writer) error {
+	return DockerCopyFromData(ops, dirs.ErisContainerRoot, w)
+}
+
+// DockerCopyFromData writes a tar archive of a file or a directory in
+// a data container or a data volume to w. The archive has the file or
+// the directory itself at the top.
+//
+//  ops.DataContainerName - data container
+//  ops.DataVolumeName    - data volume (takes precedence if set)
+//
+func DockerCopyFromData(ops *def.Operation, src string, w io.Writer) error {
+	name, done, err := mountData(ops)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	log.WithFields(log.Fields{
+		"=>":   name,
+		"path": src,
+	}).Info("Copying out of data container")
+	return util.DockerError(util.DockerClient.DownloadFromContainer(name, docker.DownloadFromContainerOptions{
+		OutputStream: w,
+		Path:         src,
+	}))
+}
+
+// DockerCopyToData extracts a tar archive into the dst directory of a data
+// container or a data volume, creating the directory if needed, and hands
+// what's in the directory over to the eris user. Files of the same names
+// are overwritten, the rest of the directory is kept.
+//
+// See parameter description for DockerCopyFromData.
+func DockerCopyToData(ops *def.Operation, dst string, archive io.Reader) error {
+	if err := runDataCommand(ops, "mkdir", "--parents", dst); err != nil {
+		return err
+	}
+
+	name, done, err := mountData(ops)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	log.WithFields(log.Fields{
+		"=>":   name,
+		"path": dst,
+	}).Info("Copying into data container")
+	if err := util.DockerClient.UploadToContainer(name, docker.UploadToContainerOptions{
+		InputStream: archive,
+		Path:        dst,
+	}); err != nil {
+		return util.DockerError(err)
+	}
+
+	return runDataCommand(ops, "chown", "--recursive", "eris", dst)
+}
+
+// DockerRestoreData replaces the contents of the eris root directory of
+// a data container or a data volume with those of a tar archive written
+// by DockerSnapshotData. If the service or chain the data belongs to is
+// running (see DataOwner), it is stopped for the time of the restore,
+// waiting for timeout seconds before killing it, and then started again.
+//
+// See parameter description for DockerSnapshotData.
+func DockerRestoreData(ops *def.Operation, archive io.Reader, timeout uint) (err error) {
+	if owner := DataOwner(ops); owner != nil && owner.Info.State.Running {
+		log.WithField("=>", owner.FullName).Warn("Stopping container to restore its data")
+		if err := stopContainer(owner.FullName, timeout); err != nil {
+			return err
+		}
+
+		defer func() {
+			log.WithField("=>", owner.FullName).Warn("Starting container again")
+			if err2 := util.DockerClient.StartContainer(owner.FullName, nil); err2 != nil && err == nil {
+				err = util.DockerError(err2)
+			}
+		}()
+	}
+
+	// The eris root may be a mount point, so remove what's in it
+	// rather than the directory itself.
+	log.WithField("=>", dirs.ErisContainerRoot).Info("Emptying data directory")
+	if err := runDataCommand(ops, "find", dirs.ErisContainerRoot, "-mindepth", "1", "-delete"); err != nil {
+		return err
+	}
+
+	name, done, err := mountData(ops)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	log.WithField("=>", name).Info("Copying into data container")
+	if err := util.DockerClient.UploadToContainer(name, docker.UploadToContainerOptions{
+		InputStream: archive,
+		Path:        path.Dir(dirs.ErisContainerRoot),
+	}); err != nil {
+		return util.DockerError(err)
+	}
+
+	// Eris images run as the eris user, while uploads go in as root.
+	return runDataCommand(ops, "chown", "--recursive", "eris", dirs.ErisContainerRoot)
+}
+
+// mountData returns the name of a container the data of ops is mounted in
+// and a function to call when done with it. See DockerMountData.
+func mountData(ops *def.Operation) (string, func(), error) {
+	if ops.DataVolumeName == "" {
+		return ops.DataContainerName, func() {}, nil
+	}
+	return DockerMountData(ops)
+}
+
+// runDataCommand runs a command against the data of ops in a throwaway
+// container, which is not labelled as an eris one.
+func runDataCommand(ops *def.Operation, args ...string) error {
+	_, err := DockerRunData(&def.Operation{
+		DataContainerName: ops.DataContainerName,
+		DataVolumeName:    ops.DataVolumeName,
+		ContainerType:     def.TypeData,
+		Args:              args,
+	}, nil)
+	return err
+}
@@ -0,0 +1,75 @@
+package perform
+
+import (
+	"strings"
+
+	log "github.com/Sirupsen/logrus"
+	def "github.com/eris-ltd/eris-cli/definitions"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// DockerRecreate replaces the ops.SrvContainerName container with one
+// created from the srv settings template, the way DockerRebuild does,
+// and starts it if start is true. The data container or the data volume
+// of the service is mounted if srv.AutoData is set.
+//
+// Also see container parameters for DockerRunService.
+func DockerRecreate(srv *def.Service, ops *def.Operation, start bool) error {
+	opts := configureServiceContainer(srv, ops)
+	if srv.AutoData {
+		if err := setupData(srv, ops, &opts); err != nil {
+			return err
+		}
+	}
+
+	return recreateContainer(opts, srv, start)
+}
+
+// DockerRollback replaces a container with one of the same name, image,
+// and configuration, as recorded by inspecting the container earlier.
+// The new container is attached to the srv networks and started if start
+// is true.
+//
+//  srv.Networks - networks to attach the container to
+//  srv.Name     - name the container answers to on the networks
+//  srv.Aliases  - other names the container answers to on the networks
+//
+func DockerRollback(container *docker.Container, srv *def.Service, start bool) error {
+	config := *container.Config
+	// The hostname defaults to the container ID, which changes.
+	config.Hostname = srv.HostName
+
+	hostConfig := *container.HostConfig
+	opts := docker.CreateContainerOptions{
+		Name:       strings.TrimPrefix(container.Name, "/"),
+		Config:     &config,
+		HostConfig: &hostConfig,
+	}
+	configureNetworks(&opts, srv, true)
+
+	return recreateContainer(opts, srv, start)
+}
+
+func recreateContainer(opts docker.CreateContainerOptions, srv *def.Service, start bool) error {
+	if ContainerExists(opts.Name) {
+		log.WithField("=>", opts.Name).Info("Removing container")
+		if err := removeContainer(opts.Name, false, true); err != nil {
+			return err
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"=>":    opts.Name,
+		"image": opts.Config.Image,
+	}).Info("Recreating container")
+	if err := createServiceContainer(opts, srv, true); err != nil {
+		return err
+	}
+
+	if !start {
+		return nil
+	}
+	log.WithField("=>", opts.Name).Info("Starting container")
+	return startContainer(opts)
+}
@@ -0,0 +1,218 @@
+package perform
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	log "github.com/Sirupsen/logrus"
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/util"
+	ver "github.com/eris-ltd/eris-cli/version"
+
+	dirs "github.com/eris-ltd/common/go/common"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+var (
+	ErrVolumeExists = errors.New("volume exists")
+)
+
+// DockerCreateDataVolume creates a named volume to keep data in instead of
+// a data container. It returns ErrVolumeExists if such a volume exists or
+// other Docker errors.
+//
+//  ops.DataVolumeName  - data volume name to be created
+//  ops.Labels          - volume labels (use LoadDataDefinition)
+//
+func DockerCreateDataVolume(ops *def.Operation) error {
+	log.WithField("=>", ops.DataVolumeName).Info("Creating data volume")
+
+	if _, err := util.DockerClient.InspectVolume(ops.DataVolumeName); err == nil {
+		log.Info("Data volume exists. Not creating")
+		return ErrVolumeExists
+	}
+
+	if err := createDataVolume(ops.DataVolumeName, ops.Labels, ""); err != nil {
+		return err
+	}
+
+	log.WithField("=>", ops.DataVolumeName).Info("Data volume created")
+
+	return nil
+}
+
+// DockerInspectVolume prints the ops.DataVolumeName volume details.
+// See util.PrintVolumeInspectionReport for the field values.
+func DockerInspectVolume(ops *def.Operation, field string) error {
+	log.WithField("=>", ops.DataVolumeName).Info("Inspecting")
+
+	volume, err := util.DockerClient.InspectVolume(ops.DataVolumeName)
+	if err != nil {
+		return util.DockerError(err)
+	}
+	return util.PrintVolumeInspectionReport(volume, field)
+}
+
+// DockerRemoveDataVolume removes the ops.DataVolumeName volume. It returns
+// Docker errors, e.g. if the volume is still used by a container.
+func DockerRemoveDataVolume(ops *def.Operation) error {
+	log.WithField("=>", ops.DataVolumeName).Info("Removing data volume")
+
+	return util.DockerError(util.DockerClient.RemoveVolume(ops.DataVolumeName))
+}
+
+// DockerMountData creates a container, which is never started, with the
+// ops.DataVolumeName volume mounted, so that files can be copied in and
+// out of the volume with the Docker archive calls. It returns the
+// container name and a function to remove the container when done.
+func DockerMountData(ops *def.Operation) (string, func(), error) {
+	opts := configureDataVolumeContainer(ops.DataVolumeName, path.Join(ver.ERIS_REG_DEF, ver.ERIS_IMG_DATA))
+
+	log.WithFields(log.Fields{
+		"=>":     opts.Name,
+		"volume": ops.DataVolumeName,
+	}).Info("Mounting data volume")
+	if _, err := createContainer(opts); err != nil {
+		return "", nil, err
+	}
+
+	return opts.Name, func() {
+		if err := removeContainer(opts.Name, false, true); err != nil {
+			log.WithField("=>", opts.Name).Errorf("Error removing container: %v", err)
+		}
+	}, nil
+}
+
+// DockerMigrateData moves the contents of a data container into a new
+// data volume and removes the data container. The service or the chain
+// container the data container belongs to has to be stopped; it is
+// removed, to be recreated with the volume mounted on the next start.
+//
+//  ops.DataContainerName - data container to migrate
+//  ops.DataVolumeName    - data volume to create
+//
+func DockerMigrateData(ops *def.Operation) error {
+	log.WithFields(log.Fields{
+		"from": ops.DataContainerName,
+		"to":   ops.DataVolumeName,
+	}).Info("Migrating data")
+
+	data, err := util.DockerClient.InspectContainer(ops.DataContainerName)
+	if err != nil {
+		return util.DockerError(err)
+	}
+	if _, err := util.DockerClient.InspectVolume(ops.DataVolumeName); err == nil {
+		return ErrVolumeExists
+	}
+
+	owner := data.Config.Labels[def.LabelService]
+	if owner != "" && ContainerRunning(owner) {
+		return fmt.Errorf("The %s container is using the data. Stop it before migrating", owner)
+	}
+
+	if err := createDataVolume(ops.DataVolumeName, data.Config.Labels, ""); err != nil {
+		return err
+	}
+	if err := copyToVolume(data, ops.DataVolumeName); err != nil {
+		log.WithField("=>", ops.DataVolumeName).Info("Removing data volume")
+		if err2 := util.DockerClient.RemoveVolume(ops.DataVolumeName); err2 != nil {
+			return fmt.Errorf("Error removing data volume after migrating (%v): %v", err, util.DockerError(err2))
+		}
+		return err
+	}
+
+	if owner != "" && ContainerExists(owner) {
+		log.WithField("=>", owner).Warn("Removing container to recreate it with the data volume on the next start")
+		if err := removeContainer(owner, false, false); err != nil {
+			return err
+		}
+	}
+
+	log.WithField("=>", ops.DataContainerName).Info("Removing data container")
+	if err := removeContainer(ops.DataContainerName, true, false); err != nil {
+		return err
+	}
+
+	log.WithField("=>", ops.DataVolumeName).Info("Data migrated")
+
+	return nil
+}
+
+// copyToVolume copies the eris root of the data container into the
+// named volume, streaming a tar archive from one to the other.
+func copyToVolume(data *docker.Container, volume string) error {
+	opts := configureDataVolumeContainer(volume, data.Config.Image)
+	if _, err := createContainer(opts); err != nil {
+		return err
+	}
+	defer func() {
+		if err := removeContainer(opts.Name, false, true); err != nil {
+			log.WithField("=>", opts.Name).Errorf("Error removing container: %v", err)
+		}
+	}()
+
+	reader, writer := io.Pipe()
+	defer reader.Close()
+
+	go func() {
+		log.WithField("=>", data.Name).Info("Copying out of data container")
+		writer.CloseWithError(util.DockerClient.DownloadFromContainer(data.ID, docker.DownloadFromContainerOptions{
+			OutputStream: writer,
+			Path:         dirs.ErisContainerRoot,
+		}))
+	}()
+
+	// The archive has the eris root directory itself at the top.
+	log.WithField("=>", volume).Info("Copying into data volume")
+	if err := util.DockerClient.UploadToContainer(opts.Name, docker.UploadToContainerOptions{
+		InputStream: reader,
+		Path:        path.Dir(dirs.ErisContainerRoot),
+	}); err != nil {
+		return util.DockerError(err)
+	}
+
+	return nil
+}
+
+// createDataVolume creates the named data volume with the labels. If
+// service is not empty, the volume is labelled as the service's one.
+func createDataVolume(name string, labels map[string]string, service string) error {
+	// Manipulate labels locally.
+	volumeLabels := make(map[string]string)
+	for k, v := range labels {
+		volumeLabels[k] = v
+	}
+	volumeLabels = util.SetLabel(volumeLabels, def.LabelType, def.TypeData)
+	if service != "" {
+		volumeLabels = util.SetLabel(volumeLabels, def.LabelService, service)
+	}
+
+	_, err := util.DockerClient.CreateVolume(docker.CreateVolumeOptions{
+		Name:   name,
+		Labels: volumeLabels,
+	})
+	return util.DockerError(err)
+}
+
+// configureDataVolumeContainer returns the settings of a throwaway
+// container with the named volume mounted at the eris root.
+func configureDataVolumeContainer(volume, image string) docker.CreateContainerOptions {
+	return docker.CreateContainerOptions{
+		Name: util.UniqueName("volume"),
+		Config: &docker.Config{
+			Image:           image,
+			NetworkDisabled: true,
+			Entrypoint:      []string{"true"},
+			Cmd:             []string{},
+		},
+		HostConfig: &docker.HostConfig{
+			Binds: []string{dataVolumeBind(volume)},
+		},
+	}
+}
+
+func dataVolumeBind(name string) string {
+	return name + ":" + dirs.ErisContainerRoot
+}
@@ -7,7 +7,6 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/eris-ltd/eris-cli/chains"
 	"github.com/eris-ltd/eris-cli/config"
@@ -72,16 +71,14 @@ func BootServicesAndChain(do *definitions.Do, pkg *definitions.Package) error {
 
 	// assemble the services
 	for _, s := range do.ServicesSlice {
-		t, err := services.BuildServicesGroup(s, srvs...)
-		if err != nil {
+		if srvs, err = services.BuildServicesGroup(s, srvs...); err != nil {
 			return err
 		}
-		srvs = append(srvs, t...)
 	}
 
 	// boot the services
 	if len(srvs) >= 1 {
-		if err := services.StartGroup(srvs); err != nil {
+		if err := services.StartGroup(srvs, int(do.Parallel)); err != nil {
 			return err
 		}
 	}
@@ -233,7 +230,11 @@ func bootChain(name string, do *definitions.Do) error {
 	do.Chain.ChainType = "service" // setting this for tear down purposes
 	startChain := definitions.NowDo()
 	startChain.Name = name
-	startChain.Operations = do.Operations
+	// wait for the chain (or chain service) to become ready before
+	// handing it over to the package; don't leak that into do
+	ops := *do.Operations
+	ops.Wait = true
+	startChain.Operations = &ops
 	f, err := os.Stat(filepath.Join(common.ChainsPath, startChain.Name))
 	switch {
 	case util.IsChain(name, true):
@@ -252,7 +253,7 @@ func bootChain(name string, do *definitions.Do) error {
 	case util.IsService(name, false):
 		log.WithField("name", name).Info("Chain exists as a service")
 		startService := definitions.NowDo()
-		startService.Operations = do.Operations
+		startService.Operations = startChain.Operations
 		startService.Operations.Args = []string{name}
 		err = services.StartService(startService)
 		if err != nil {
@@ -265,8 +266,6 @@ func bootChain(name string, do *definitions.Do) error {
 
 	do.Chain.Name = name // setting this for tear down purposes
 
-	// let the chain boot properly
-	time.Sleep(5 * time.Second)
 	return nil
 }
 
@@ -274,8 +273,11 @@ func bootThrowAwayChain(name string, do *definitions.Do) error {
 	do.Chain.ChainType = "throwaway"
 
 	tmp := do.Name
+	wait := do.Operations.Wait
 	do.Name = name
+	do.Operations.Wait = true // let the chain boot properly
 	err := chains.ThrowAwayChain(do)
+	do.Operations.Wait = wait
 	if err != nil {
 		do.Name = tmp
 		return err
@@ -284,9 +286,6 @@ func bootThrowAwayChain(name string, do *definitions.Do) error {
 	do.Chain.Name = do.Name // setting this for tear down purposes
 	log.WithField("=>", do.Name).Debug("Throwaway chain booted")
 
-	// let the chain boot properly
-	time.Sleep(5 * time.Second)
-
 	do.Name = tmp
 	return nil
 }
@@ -298,6 +297,7 @@ func linkAppToChain(do *definitions.Do, pkg *definitions.Package) {
 		newLink = util.ServiceContainerName(pkg.ChainName) + ":" + "chain"
 	} else {
 		newLink = util.ChainContainerName(pkg.ChainName) + ":" + "chain"
+		util.JoinNetwork(do.Service, util.ChainNetworkName(pkg.ChainName))
 	}
 	newLink2 := util.ServiceContainerName("keys") + ":" + "keys"
 	do.Service.Links = append(do.Service.Links, newLink)
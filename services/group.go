@@ -0,0 +1,447 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/loaders"
+	"github.com/eris-ltd/eris-cli/perform"
+	"github.com/eris-ltd/eris-cli/util"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// groupKey identifies a node of the dependency graph. Chains and services
+// live in different namespaces, so a chain and a service may share a name.
+func groupKey(t, name string) string {
+	return t + ":" + name
+}
+
+func nodeKey(srv *definitions.ServiceDefinition) string {
+	if srv.Operations != nil && srv.Operations.ContainerType == definitions.TypeChain {
+		return groupKey(definitions.TypeChain, srv.Name)
+	}
+	return groupKey(definitions.TypeService, srv.Name)
+}
+
+// dependencyKeys returns the keys of the nodes srv has to wait for.
+func dependencyKeys(srv *definitions.ServiceDefinition) (keys []string) {
+	if srv.Dependencies == nil {
+		return nil
+	}
+	for _, name := range srv.Dependencies.Services {
+		keys = append(keys, groupKey(definitions.TypeService, name))
+	}
+	for _, name := range srv.Dependencies.Chains {
+		keys = append(keys, groupKey(definitions.TypeChain, name))
+	}
+	return keys
+}
+
+// addToGroup loads the service or chain with its (transitive) dependencies
+// and appends those not yet in the group. Already known nodes are never
+// loaded twice, which also stops the recursion on dependency cycles (those
+// are reported by sortGroup).
+func addToGroup(group []*definitions.ServiceDefinition, known map[string]bool, t, name string) ([]*definitions.ServiceDefinition, error) {
+	key := groupKey(t, name)
+	if known[key] {
+		return group, nil
+	}
+	known[key] = true
+
+	var (
+		srv *definitions.ServiceDefinition
+		err error
+	)
+	if t == definitions.TypeChain {
+		srv, err = loaders.ChainsAsAService(name, false)
+	} else {
+		srv, err = loaders.LoadServiceDefinition(name, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return addDependenciesToGroup(append(group, srv), known, srv)
+}
+
+func addDependenciesToGroup(group []*definitions.ServiceDefinition, known map[string]bool, srv *definitions.ServiceDefinition) ([]*definitions.ServiceDefinition, error) {
+	if srv.Dependencies == nil {
+		return group, nil
+	}
+
+	var err error
+	for _, name := range srv.Dependencies.Services {
+		log.WithField("=>", name).Debug("Found service dependency")
+		if group, err = addToGroup(group, known, definitions.TypeService, name); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range srv.Dependencies.Chains {
+		log.WithField("=>", name).Debug("Found chain dependency")
+		if group, err = addToGroup(group, known, definitions.TypeChain, name); err != nil {
+			return nil, err
+		}
+	}
+	return group, nil
+}
+
+// knownNodes returns the set of keys of the group nodes.
+func knownNodes(group []*definitions.ServiceDefinition) map[string]bool {
+	known := make(map[string]bool)
+	for _, srv := range group {
+		known[nodeKey(srv)] = true
+	}
+	return known
+}
+
+// sortGroup orders the group so that every service or chain comes after
+// everything it depends on. Dependencies outside of the group are ignored.
+// sortGroup returns an error with the offending path if the dependencies
+// form a cycle.
+func sortGroup(group []*definitions.ServiceDefinition) ([]*definitions.ServiceDefinition, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	nodes := make(map[string]*definitions.ServiceDefinition)
+	for _, srv := range group {
+		nodes[nodeKey(srv)] = srv
+	}
+
+	var (
+		sorted []*definitions.ServiceDefinition
+		path   []string
+		state  = make(map[string]int)
+		visit  func(key string) error
+	)
+	visit = func(key string) error {
+		switch state[key] {
+		case visited:
+			return nil
+		case visiting:
+			for i := range path {
+				if path[i] == key {
+					return fmt.Errorf("Dependency cycle detected: %s", strings.Join(append(path[i:], key), " -> "))
+				}
+			}
+		}
+
+		state[key] = visiting
+		path = append(path, key)
+		for _, dep := range dependencyKeys(nodes[key]) {
+			if _, ok := nodes[dep]; !ok {
+				continue
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[key] = visited
+
+		sorted = append(sorted, nodes[key])
+		return nil
+	}
+
+	for _, srv := range group {
+		if err := visit(nodeKey(srv)); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// shareNetworks attaches the dependencies of every service or chain of the
+// sorted group to the networks it is attached to, so that they can reach
+// each other.
+func shareNetworks(sorted []*definitions.ServiceDefinition) {
+	nodes := make(map[string]*definitions.ServiceDefinition)
+	for _, srv := range sorted {
+		nodes[nodeKey(srv)] = srv
+	}
+
+	// Walking backwards, networks are passed on to indirect dependencies too.
+	for i := len(sorted) - 1; i >= 0; i-- {
+		srv := sorted[i]
+		for _, key := range dependencyKeys(srv) {
+			if dep, ok := nodes[key]; ok {
+				for _, network := range srv.Service.Networks {
+					util.JoinNetwork(dep.Service, network)
+				}
+			}
+		}
+	}
+}
+
+// groupStart tracks the start of a single group node.
+type groupStart struct {
+	done chan struct{}
+	err  error
+}
+
+// StartGroup starts a group of chains or services. A container is started
+// as soon as everything it depends on within the group is started (and
+// ready, if it has a health check), running at most parallel starts at a
+// time. Containers whose dependencies failed to start are not started.
+func StartGroup(group []*definitions.ServiceDefinition, parallel int) error {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	group, err := sortGroup(group)
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"services#": len(group),
+		"parallel":  parallel,
+	}).Debug("Starting services group")
+
+	shareNetworks(group)
+
+	starts := make(map[string]*groupStart)
+	for _, srv := range group {
+		starts[nodeKey(srv)] = &groupStart{done: make(chan struct{})}
+	}
+
+	slots := make(chan struct{}, parallel)
+	for _, srv := range group {
+		go func(srv *definitions.ServiceDefinition, start *groupStart) {
+			defer close(start.done)
+
+			for _, dep := range dependencyKeys(srv) {
+				if depStart, ok := starts[dep]; ok {
+					<-depStart.done
+					if depStart.err != nil {
+						start.err = fmt.Errorf("Not starting %s: dependency %s failed to start", srv.Name, dep)
+						return
+					}
+				}
+			}
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			log.WithField("=>", srv.Name).Debug("Performing container start")
+			if err := perform.DockerRunService(srv.Service, srv.Operations); err != nil {
+				start.err = fmt.Errorf("Error starting service %s: %v", srv.Name, err)
+			}
+		}(srv, starts[nodeKey(srv)])
+	}
+
+	// The group is sorted, so the first error found is a failure
+	// of its own rather than one of a failed dependency.
+	for _, srv := range group {
+		start := starts[nodeKey(srv)]
+		<-start.done
+		if start.err != nil && err == nil {
+			err = start.err
+		}
+	}
+	return err
+}
+
+// StopGroup stops the services and chains given by name after the services
+// and chains which depend on them (dependents are stopped first). If
+// do.Cascade is set, the dependencies of the stopped containers which
+// nothing else running uses are stopped as well.
+//
+//  do.Timeout  - time to wait for each container to stop
+//  do.Force    - kill the containers without waiting
+//  do.Rm       - remove the named (and cascaded) containers after stopping
+//  do.RmD      - remove their data containers too
+//  do.Volumes  - remove their volumes too
+//
+func StopGroup(do *definitions.Do, services, chains []string) error {
+	// if force flag given, this will override any timeout flag
+	if do.Force {
+		do.Timeout = 0
+	}
+
+	var (
+		group   []*definitions.ServiceDefinition
+		err     error
+		known   = make(map[string]bool)
+		targets = make(map[string]bool)
+		running = make(map[string]bool)
+	)
+	for _, name := range services {
+		targets[groupKey(definitions.TypeService, name)] = true
+		if group, err = addToGroup(group, known, definitions.TypeService, name); err != nil {
+			return err
+		}
+	}
+	for _, name := range chains {
+		targets[groupKey(definitions.TypeChain, name)] = true
+		if group, err = addToGroup(group, known, definitions.TypeChain, name); err != nil {
+			return err
+		}
+	}
+
+	// Everything which runs might depend on the targets.
+	var (
+		runningServices []*util.Details
+		chainContainers = make(map[string]string)
+	)
+	for _, t := range []string{definitions.TypeService, definitions.TypeChain} {
+		for _, details := range util.ErisContainersByType(t, true) {
+			running[groupKey(t, details.ShortName)] = true
+			if t == definitions.TypeService {
+				runningServices = append(runningServices, details)
+			} else {
+				chainContainers[details.FullName] = details.ShortName
+			}
+			if g, err := addToGroup(group, known, t, details.ShortName); err != nil {
+				log.WithField("=>", details.ShortName).Debugf("Cannot load definition: %v", err)
+			} else {
+				group = g
+			}
+		}
+	}
+
+	// The checked out chain needn't be the one a `$chain` service was
+	// started with, so that is read from the running containers.
+	connected := make(map[string]string)
+	for _, details := range runningServices {
+		connected[details.ShortName] = connectedChain(details, chainContainers)
+	}
+
+	// Chains connected to services with the `chain` field.
+	for i := 0; i < len(group); i++ {
+		srv := group[i]
+		if srv.Chain == "" {
+			continue
+		}
+		chainFlag := ""
+		if strings.HasPrefix(srv.Chain, "$chain") {
+			if chainFlag = connected[srv.Name]; chainFlag == "" {
+				log.WithField("=>", srv.Name).Debug("Service is not connected to a running chain")
+				continue
+			}
+		}
+		s, err := ConnectChainToService(chainFlag, srv.Chain, srv)
+		if err != nil {
+			log.WithField("=>", srv.Name).Debugf("Cannot connect chain: %v", err)
+			continue
+		}
+		if srv.Dependencies == nil {
+			srv.Dependencies = &definitions.Dependencies{}
+		}
+		srv.Dependencies.Chains = append(srv.Dependencies.Chains, s.Name)
+		if key := nodeKey(s); !known[key] {
+			known[key] = true
+			group = append(group, s)
+		}
+	}
+
+	sorted, err := sortGroup(group)
+	if err != nil {
+		return err
+	}
+
+	// Dependencies come first in sorted, so a single pass is enough to
+	// find all dependents of the targets.
+	stop := make(map[string]bool)
+	users := make(map[string][]string)
+	for _, srv := range sorted {
+		key := nodeKey(srv)
+		for _, dep := range dependencyKeys(srv) {
+			users[dep] = append(users[dep], key)
+			if stop[dep] {
+				stop[key] = true
+			}
+		}
+		if targets[key] {
+			stop[key] = true
+		}
+	}
+
+	remove := make(map[string]bool)
+	for key := range targets {
+		remove[key] = true
+	}
+
+	if do.Cascade {
+		// Walking backwards, all users of a dependency are seen before it.
+		needed := make(map[string]bool)
+		for i := len(sorted) - 1; i >= 0; i-- {
+			key := nodeKey(sorted[i])
+			if !stop[key] {
+				if !needed[key] || inUse(key, users, running, stop) {
+					continue
+				}
+				stop[key], remove[key] = true, true
+			}
+			for _, dep := range dependencyKeys(sorted[i]) {
+				needed[dep] = true
+			}
+		}
+	}
+
+	for i := len(sorted) - 1; i >= 0; i-- {
+		srv := sorted[i]
+		key := nodeKey(srv)
+		if !stop[key] {
+			continue
+		}
+
+		if running[key] {
+			log.WithField("=>", srv.Name).Info("Stopping")
+			if err := perform.DockerStop(srv.Service, srv.Operations, do.Timeout); err != nil {
+				return err
+			}
+		} else {
+			log.WithField("=>", srv.Name).Info("Not currently running. Skipping")
+		}
+
+		if do.Rm && remove[key] {
+			if err := perform.DockerRemove(srv.Service, srv.Operations, do.RmD, do.Volumes, do.Force); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// inUse returns true if a running container which isn't going to be stopped
+// depends on key.
+func inUse(key string, users map[string][]string, running, stop map[string]bool) bool {
+	for _, user := range users[key] {
+		if running[user] && !stop[user] {
+			return true
+		}
+	}
+	return false
+}
+
+// connectedChain returns the short name of the running chain the service
+// container is linked to or has joined the network of, or "". chains maps
+// the full names of the running chain containers to their short names.
+func connectedChain(details *util.Details, chains map[string]string) string {
+	if details.Info == nil {
+		return ""
+	}
+	if details.Info.HostConfig != nil {
+		for _, link := range details.Info.HostConfig.Links {
+			// Links are inspected as /CONTAINER:/SERVICE/ALIAS.
+			name := strings.TrimPrefix(strings.SplitN(link, ":", 2)[0], "/")
+			if chain, ok := chains[name]; ok {
+				return chain
+			}
+		}
+	}
+	if details.Info.NetworkSettings == nil {
+		return ""
+	}
+	for _, chain := range chains {
+		if _, ok := details.Info.NetworkSettings.Networks[util.ChainNetworkName(chain)]; ok {
+			return chain
+		}
+	}
+	return ""
+}
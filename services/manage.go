@@ -214,6 +214,14 @@ func UpdateService(do *definitions.Do) error {
 	}
 	service.Service.Environment = append(service.Service.Environment, do.Env...)
 	service.Service.Links = append(service.Service.Links, do.Links...)
+	if do.Rebuild {
+		if service.Service.Build == nil {
+			return fmt.Errorf("The %s service has no build section to rebuild its image from", do.Name)
+		}
+		if err := perform.DockerBuildService(service.Service, true); err != nil {
+			return err
+		}
+	}
 	err = perform.DockerRebuild(service.Service, service.Operations, do.Pull, do.Timeout)
 	if err != nil {
 		return err
@@ -19,11 +19,9 @@ func StartService(do *definitions.Do) (err error) {
 	do.Operations.Args = append(do.Operations.Args, do.ServicesSlice...)
 	log.WithField("args", do.Operations.Args).Info("Building services group")
 	for _, srv := range do.Operations.Args {
-		s, e := BuildServicesGroup(srv)
-		if e != nil {
-			return e
+		if services, err = BuildServicesGroup(srv, services...); err != nil {
+			return err
 		}
-		services = append(services, s...)
 	}
 
 	// [csk]: controls for ops reconciliation, overwrite will, e.g., merge the maps and stuff
@@ -66,45 +64,17 @@ func StartService(do *definitions.Do) (err error) {
 	topService.Service.Links = append(topService.Service.Links, do.Links...)
 	services[len(services)-1] = topService
 
-	return StartGroup(services)
+	return StartGroup(services, int(do.Parallel))
 }
 
 func KillService(do *definitions.Do) (err error) {
-	var services []*definitions.ServiceDefinition
-
-	log.WithField("args", do.Operations.Args).Info("Building services group")
-	for _, servName := range do.Operations.Args {
-		s, e := BuildServicesGroup(servName)
-		if e != nil {
-			return e
-		}
-		services = append(services, s...)
-	}
-
-	// if force flag given, this will override any timeout flag
-	if do.Force {
-		do.Timeout = 0
-	}
-
-	for _, service := range services {
-		if util.IsService(service.Service.Name, true) {
-			log.WithField("=>", service.Service.Name).Debug("Stopping service")
-			if err := perform.DockerStop(service.Service, service.Operations, do.Timeout); err != nil {
-				return err
-			}
-
-		} else {
-			log.WithField("=>", service.Service.Name).Info("Service not currently running. Skipping")
-		}
-
-		if do.Rm {
-			if err := perform.DockerRemove(service.Service, service.Operations, do.RmD, do.Volumes, do.Force); err != nil {
-				return err
-			}
-		}
+	var chains []string
+	if do.ChainName != "" {
+		chains = append(chains, do.ChainName)
 	}
 
-	return nil
+	log.WithField("args", do.Operations.Args).Info("Building services group")
+	return StopGroup(do, do.Operations.Args, chains)
 }
 
 func ExecService(do *definitions.Do) (buf *bytes.Buffer, err error) {
@@ -115,6 +85,10 @@ func ExecService(do *definitions.Do) (buf *bytes.Buffer, err error) {
 
 	util.Merge(service.Operations, do.Operations)
 
+	if service.Operations.Attach {
+		return perform.DockerExecAttach(service.Service, service.Operations)
+	}
+
 	// Get the main service container name, check if it's running.
 	main := util.ServiceContainerName(do.Name)
 	if util.IsService(do.Name, true) {
@@ -140,73 +114,66 @@ func ExecService(do *definitions.Do) (buf *bytes.Buffer, err error) {
 
 // ExecHandler implemements ExecService for use within
 // the cli for under the hood functionality
-// (wrapping) calls to respective containers
+// (wrapping) calls to respective containers. If the service
+// is running, the command is run inside its container rather
+// than in a new one.
 func ExecHandler(srvName string, args []string) (buf *bytes.Buffer, err error) {
 	do := definitions.NowDo()
 	do.Name = srvName
+	do.Operations.Attach = util.IsService(srvName, true)
 	do.Operations.Interactive = false
 	do.Operations.Args = args
 	do.Operations.PublishAllPorts = true
 	return ExecService(do)
 }
 
-// TODO: test this recursion and service deps generally
+// BuildServicesGroup loads the srvName service along with the services and
+// chains it depends on and adds those to services. Each service or chain
+// is added only once; the returned group is ordered so that dependencies
+// come first. BuildServicesGroup returns an error with the offending path
+// if the dependencies form a cycle.
 func BuildServicesGroup(srvName string, services ...*definitions.ServiceDefinition) ([]*definitions.ServiceDefinition, error) {
 	log.WithFields(log.Fields{
 		"=>":        srvName,
 		"services#": len(services),
 	}).Debug("Building services group for")
-	srv, err := loaders.LoadServiceDefinition(srvName, false)
+
+	services, err := addToGroup(services, knownNodes(services), definitions.TypeService, srvName)
 	if err != nil {
 		return nil, err
 	}
-	if srv.Dependencies != nil {
-		for _, sName := range srv.Dependencies.Services {
-			log.WithField("=>", sName).Debug("Found service dependency")
-			s, e := BuildServicesGroup(sName)
-			if e != nil {
-				return nil, e
-			}
-			services = append(services, s...)
-		}
-	}
-	services = append(services, srv)
-	return services, nil
-}
-
-// start a group of chains or services. catch errors on a channel so we can stop as soon as something goes wrong
-func StartGroup(group []*definitions.ServiceDefinition) error {
-	log.WithField("services#", len(group)).Debug("Starting services group")
-	for _, srv := range group {
-		log.WithField("=>", srv.Name).Debug("Performing container start")
-		if err := perform.DockerRunService(srv.Service, srv.Operations); err != nil {
-			return fmt.Errorf("Error starting service %s: %v", srv.Name, err)
-		}
-	}
-	return nil
+	return sortGroup(services)
 }
 
 // BuildChainGroup adds the chain specified in each service definition to the service group.
 // If chainName is not empty, it will overwrite chains specified in the defs.
 // Service defs which don't specify a chain or $chain won't connect to a chain.
-// NOTE: chains have to be started before services that depend on them.
+// The chain is recorded as a dependency of the service, so that it is started first.
 func BuildChainGroup(chainName string, services []*definitions.ServiceDefinition) (servicesAndChains []*definitions.ServiceDefinition, err error) {
-	var chains = make(map[string]*definitions.ServiceDefinition)
+	known := knownNodes(services)
+	servicesAndChains = services
 	for _, srv := range services {
 		if srv.Chain != "" {
 			s, err := ConnectChainToService(chainName, srv.Chain, srv)
 			if err != nil {
 				return nil, err
 			}
-			if _, ok := chains[s.Name]; !ok {
-				chains[s.Name] = s
+
+			if srv.Dependencies == nil {
+				srv.Dependencies = &definitions.Dependencies{}
+			}
+			srv.Dependencies.Chains = append(srv.Dependencies.Chains, s.Name)
+
+			if key := nodeKey(s); !known[key] {
+				known[key] = true
+				servicesAndChains, err = addDependenciesToGroup(append(servicesAndChains, s), known, s)
+				if err != nil {
+					return nil, err
+				}
 			}
 		}
 	}
-	for _, sd := range chains {
-		servicesAndChains = append(servicesAndChains, sd)
-	}
-	return append(servicesAndChains, services...), nil
+	return sortGroup(servicesAndChains)
 }
 
 func ConnectChainToService(chainFlag, chainNameAndOpts string, srv *definitions.ServiceDefinition) (*definitions.ServiceDefinition, error) {
@@ -18,6 +18,7 @@ import (
 
 	log "github.com/Sirupsen/logrus"
 	logger "github.com/eris-ltd/common/go/log"
+	docker "github.com/fsouza/go-dockerclient"
 )
 
 const servName = "ipfs"
@@ -441,7 +442,15 @@ func TestStartKillServiceWithDependencies(t *testing.T) {
 		t.Fatalf("expecting keys data container exists")
 	}
 
-	kill(t, "do_not_use", true)
+	do = def.NowDo()
+	do.Operations.Args = []string{"do_not_use"}
+	do.Force = true
+	do.Rm = true
+	do.RmD = true
+	do.Cascade = true
+	if err := KillService(do); err != nil {
+		t.Fatalf("expected service to be stopped, got %v", err)
+	}
 
 	if util.Running(def.TypeService, servName) {
 		t.Fatalf("expecting test service not running")
@@ -458,6 +467,133 @@ func TestStartKillServiceWithDependencies(t *testing.T) {
 
 }
 
+func TestKillServiceDependencyNoCascade(t *testing.T) {
+	defer tests.RemoveAllContainers()
+
+	start(t, "do_not_use", false)
+
+	kill(t, "do_not_use", true)
+
+	if util.Exists(def.TypeService, "do_not_use") {
+		t.Fatalf("expecting do_not_use service not existing")
+	}
+	if !util.Running(def.TypeService, "keys") {
+		t.Fatalf("expecting keys service still running")
+	}
+}
+
+func TestKillServiceStopsDependents(t *testing.T) {
+	defer tests.RemoveAllContainers()
+
+	start(t, "do_not_use", false)
+
+	kill(t, "keys", false)
+
+	if util.Running(def.TypeService, "do_not_use") {
+		t.Fatalf("expecting dependent do_not_use service stopped")
+	}
+	if util.Running(def.TypeService, "keys") {
+		t.Fatalf("expecting keys service stopped")
+	}
+	if !util.Exists(def.TypeService, "do_not_use") {
+		t.Fatalf("expecting dependent do_not_use service not removed")
+	}
+}
+
+func TestStartServiceParallel(t *testing.T) {
+	defer tests.RemoveAllContainers()
+
+	do := def.NowDo()
+	do.Operations.Args = []string{"do_not_use", "keys"}
+	do.Parallel = 4
+	if err := StartService(do); err != nil {
+		t.Fatalf("expected services to start, got %v", err)
+	}
+
+	if !util.Running(def.TypeService, "do_not_use") {
+		t.Fatalf("expecting do_not_use service running")
+	}
+	if !util.Running(def.TypeService, "keys") {
+		t.Fatalf("expecting keys service running")
+	}
+
+	kill(t, "do_not_use", true)
+}
+
+func TestSortGroup(t *testing.T) {
+	group := []*def.ServiceDefinition{
+		groupNode("a", def.TypeService, []string{"b", "c"}, nil),
+		groupNode("b", def.TypeService, []string{"c"}, []string{"x"}),
+		groupNode("c", def.TypeService, nil, nil),
+		groupNode("x", def.TypeChain, []string{"c"}, nil),
+		groupNode("c", def.TypeService, nil, nil),
+	}
+
+	sorted, err := sortGroup(group)
+	if err != nil {
+		t.Fatalf("expected group to sort, got %v", err)
+	}
+
+	var names []string
+	for _, srv := range sorted {
+		names = append(names, srv.Name)
+	}
+	if strings.Join(names, ",") != "c,x,b,a" {
+		t.Fatalf("expected group sorted as c,x,b,a, got %v", names)
+	}
+}
+
+func TestSortGroupCycle(t *testing.T) {
+	group := []*def.ServiceDefinition{
+		groupNode("a", def.TypeService, []string{"b"}, nil),
+		groupNode("b", def.TypeService, []string{"c"}, nil),
+		groupNode("c", def.TypeService, []string{"a"}, nil),
+	}
+
+	_, err := sortGroup(group)
+	if err == nil {
+		t.Fatalf("expected a dependency cycle error, got nil")
+	}
+	if !strings.Contains(err.Error(), "service:a -> service:b -> service:c -> service:a") {
+		t.Fatalf("expected the cycle path in the error, got %v", err)
+	}
+}
+
+func TestConnectedChain(t *testing.T) {
+	chains := map[string]string{
+		"staging-1f2e": "staging",
+		"other-3c4d":   "other",
+	}
+	for _, c := range []struct {
+		links    []string
+		networks []string
+		chain    string
+	}{
+		{[]string{"/staging-1f2e:/app-5a6b/chain"}, nil, "staging"},
+		{nil, []string{"bridge", util.ChainNetworkName("other")}, "other"},
+		{[]string{"/keys-7e8f:/app-5a6b/keys"}, []string{"bridge"}, ""},
+	} {
+		details := &util.Details{Info: &docker.Container{
+			HostConfig:      &docker.HostConfig{Links: c.links},
+			NetworkSettings: &docker.NetworkSettings{Networks: make(map[string]docker.ContainerNetwork)},
+		}}
+		for _, network := range c.networks {
+			details.Info.NetworkSettings.Networks[network] = docker.ContainerNetwork{}
+		}
+		if chain := connectedChain(details, chains); chain != c.chain {
+			t.Fatalf("expected links %v and networks %v to connect to %q, got %q", c.links, c.networks, c.chain, chain)
+		}
+	}
+}
+
+func groupNode(name, typ string, services, chains []string) *def.ServiceDefinition {
+	srv := def.BlankServiceDefinition()
+	srv.Name = name
+	srv.Operations.ContainerType = typ
+	srv.Dependencies = &def.Dependencies{Services: services, Chains: chains}
+	return srv
+}
+
 func start(t *testing.T, serviceName string, publishAll bool) {
 	do := def.NowDo()
 	do.Operations.Args = []string{serviceName}
@@ -0,0 +1,390 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/util"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/term"
+	"github.com/docker/go-units"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+const (
+	tableHeader = "NAME\tTYPE\tCPU %\tMEM USAGE / LIMIT\tMEM %\tNET I/O\tBLOCK I/O"
+
+	// Clear the screen and move the cursor home between refreshes.
+	clearScreen = "\033[2J\033[H"
+)
+
+// RefreshInterval is how often the streamed statistics are redisplayed.
+var RefreshInterval = time.Second
+
+// Usage is the resource usage of a service or a chain: that of its
+// container added up with that of its data container. Data containers
+// only hold volumes and are usually never started; they are listed
+// along, but add up nothing unless they are running.
+type Usage struct {
+	Type       string   `json:"type"`
+	ShortName  string   `json:"name"`
+	Containers []string `json:"containers"`
+
+	CPUPercent    float64 `json:"cpu_percent"`
+	MemoryUsage   uint64  `json:"memory_usage"`
+	MemoryLimit   uint64  `json:"memory_limit"`
+	MemoryPercent float64 `json:"memory_percent"`
+	NetworkRx     uint64  `json:"network_rx"`
+	NetworkTx     uint64  `json:"network_tx"`
+	BlockRead     uint64  `json:"block_read"`
+	BlockWrite    uint64  `json:"block_write"`
+}
+
+// group is a service or a chain and the containers it is made of.
+type group struct {
+	Type       string
+	ShortName  string
+	Containers []string
+	Stopped    []string // containers not running, hence not sampled
+}
+
+// Display writes the resource usage of the running eris containers of
+// the t type (def.TypeService, def.TypeChain, or "all") to w, either as
+// a table or as JSON documents if format is "json". The names, if given,
+// narrow the output to services or chains with these short names. With
+// stream the statistics are redisplayed every RefreshInterval for as long
+// as Docker sends them (usually until interrupted); otherwise they are
+// displayed once.
+func Display(w io.Writer, t string, names []string, format string, stream bool) error {
+	log.WithFields(log.Fields{
+		"type":   t,
+		"names":  names,
+		"format": format,
+		"stream": stream,
+	}).Debug("Displaying container statistics")
+
+	groups, err := groups(t, names)
+	if err != nil {
+		return err
+	}
+
+	s := newSampler(groups, stream)
+	if !stream {
+		s.wait()
+		return write(w, s.usage(), format, false)
+	}
+
+	clear := format != "json" && w == os.Stdout && term.IsTerminal(os.Stdout.Fd())
+
+	ticker := time.NewTicker(RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := write(w, s.usage(), format, clear); err != nil {
+				return err
+			}
+		case <-s.done:
+			return s.err
+		}
+	}
+}
+
+// Collect samples the resource usage of the running eris containers once.
+// The parameters are the same as in Display.
+func Collect(t string, names []string) ([]*Usage, error) {
+	groups, err := groups(t, names)
+	if err != nil {
+		return nil, err
+	}
+
+	s := newSampler(groups, false)
+	s.wait()
+	return s.usage(), s.err
+}
+
+// groups finds the running service and chain containers to display, each
+// with its data container, running or not.
+func groups(t string, names []string) ([]*group, error) {
+	var types []string
+	switch t {
+	case def.TypeService, def.TypeChain:
+		types = []string{t}
+	case "all":
+		types = []string{def.TypeService, def.TypeChain}
+	default:
+		return nil, fmt.Errorf("Don't know the type %q to display statistics for", t)
+	}
+
+	var groups []*group
+	for _, typ := range types {
+		for _, details := range util.ErisContainersByType(typ, true) {
+			if len(names) != 0 && !contains(names, details.ShortName) {
+				continue
+			}
+			groups = append(groups, &group{
+				Type:       details.Type,
+				ShortName:  details.ShortName,
+				Containers: []string{details.FullName},
+			})
+		}
+	}
+
+	for _, name := range names {
+		found := false
+		for _, g := range groups {
+			if g.ShortName == name {
+				found = true
+			}
+		}
+		if !found {
+			if t == "all" {
+				return nil, fmt.Errorf("No running service or chain named %s", name)
+			}
+			return nil, fmt.Errorf("No running %s named %s", t, name)
+		}
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("There are no running services or chains")
+	}
+
+	var running []string
+	for _, data := range util.ErisContainersByType(def.TypeData, true) {
+		running = append(running, data.FullName)
+	}
+	for _, data := range util.ErisContainersByType(def.TypeData, false) {
+		for _, g := range groups {
+			if g.ShortName != data.ShortName {
+				continue
+			}
+			g.Containers = append(g.Containers, data.FullName)
+			if !contains(running, data.FullName) {
+				g.Stopped = append(g.Stopped, data.FullName)
+			}
+		}
+	}
+
+	sort.Sort(byName(groups))
+	return groups, nil
+}
+
+// sampler keeps the latest statistics for every container of the groups.
+type sampler struct {
+	groups []*group
+
+	mu     sync.Mutex
+	latest map[string]*docker.Stats // by container name
+	err    error
+
+	done chan struct{} // closed when all containers stop sending statistics
+}
+
+func newSampler(groups []*group, stream bool) *sampler {
+	s := &sampler{
+		groups: groups,
+		latest: make(map[string]*docker.Stats),
+		done:   make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	for _, g := range groups {
+		for _, name := range g.Containers {
+			if contains(g.Stopped, name) {
+				continue
+			}
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+				s.sample(name, stream)
+			}(name)
+		}
+	}
+	go func() {
+		wg.Wait()
+		close(s.done)
+	}()
+
+	return s
+}
+
+func (s *sampler) sample(name string, stream bool) {
+	ch := make(chan *docker.Stats)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- util.DockerClient.Stats(docker.StatsOptions{
+			ID:     name,
+			Stats:  ch,
+			Stream: stream,
+		})
+	}()
+
+	for stats := range ch {
+		s.mu.Lock()
+		s.latest[name] = stats
+		s.mu.Unlock()
+	}
+
+	if err := <-errs; err != nil {
+		log.WithFields(log.Fields{
+			"=>":    name,
+			"error": err,
+		}).Debug("Cannot read container statistics")
+
+		s.mu.Lock()
+		if s.err == nil {
+			s.err = util.DockerError(err)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *sampler) wait() {
+	<-s.done
+}
+
+// usage adds up the latest statistics of every group's containers.
+func (s *sampler) usage() []*Usage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var list []*Usage
+	for _, g := range s.groups {
+		u := &Usage{
+			Type:       g.Type,
+			ShortName:  g.ShortName,
+			Containers: g.Containers,
+		}
+		for i, name := range g.Containers {
+			stats, ok := s.latest[name]
+			if !ok {
+				continue
+			}
+			add(u, stats)
+
+			// The memory limit is that of the service or chain
+			// container itself.
+			if i == 0 {
+				u.MemoryLimit = stats.MemoryStats.Limit
+			}
+		}
+		if u.MemoryLimit != 0 {
+			u.MemoryPercent = float64(u.MemoryUsage) / float64(u.MemoryLimit) * 100
+		}
+		list = append(list, u)
+	}
+	return list
+}
+
+// add adds the container statistics to the usage.
+func add(u *Usage, stats *docker.Stats) {
+	u.CPUPercent += cpuPercent(stats)
+	u.MemoryUsage += stats.MemoryStats.Usage
+
+	if len(stats.Networks) == 0 {
+		u.NetworkRx += stats.Network.RxBytes
+		u.NetworkTx += stats.Network.TxBytes
+	}
+	for _, network := range stats.Networks {
+		u.NetworkRx += network.RxBytes
+		u.NetworkTx += network.TxBytes
+	}
+
+	for _, entry := range stats.BlkioStats.IOServiceBytesRecursive {
+		switch strings.ToLower(entry.Op) {
+		case "read":
+			u.BlockRead += entry.Value
+		case "write":
+			u.BlockWrite += entry.Value
+		}
+	}
+}
+
+// cpuPercent computes the CPU usage the way `docker stats` does: the
+// container share of the system CPU time elapsed since the previous
+// reading, scaled by the number of CPUs.
+func cpuPercent(stats *docker.Stats) float64 {
+	var (
+		cpuDelta    = float64(stats.CPUStats.CPUUsage.TotalUsage) - float64(stats.PreCPUStats.CPUUsage.TotalUsage)
+		systemDelta = float64(stats.CPUStats.SystemCPUUsage) - float64(stats.PreCPUStats.SystemCPUUsage)
+	)
+	if cpuDelta <= 0 || systemDelta <= 0 {
+		return 0
+	}
+
+	cpus := len(stats.CPUStats.CPUUsage.PercpuUsage)
+	if cpus == 0 {
+		cpus = 1
+	}
+	return cpuDelta / systemDelta * float64(cpus) * 100
+}
+
+func write(w io.Writer, list []*Usage, format string, clear bool) error {
+	if format == "json" {
+		if list == nil {
+			list = []*Usage{}
+		}
+		b, err := json.Marshal(list)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(b))
+		return nil
+	}
+
+	buf := new(bytes.Buffer)
+	if clear {
+		buf.WriteString(clearScreen)
+	}
+
+	// 6 - minwidth, 1 - tabwidth (tab characters width), 5 - padding, ' ' - padchar, 0 - flags.
+	tw := tabwriter.NewWriter(buf, 6, 1, 5, ' ', 0)
+	fmt.Fprintln(tw, tableHeader)
+	for _, u := range list {
+		fmt.Fprintf(tw, "%s\t%s\t%.2f%%\t%s / %s\t%.2f%%\t%s / %s\t%s / %s\n",
+			u.ShortName,
+			u.Type,
+			u.CPUPercent,
+			units.BytesSize(float64(u.MemoryUsage)),
+			units.BytesSize(float64(u.MemoryLimit)),
+			u.MemoryPercent,
+			units.HumanSize(float64(u.NetworkRx)),
+			units.HumanSize(float64(u.NetworkTx)),
+			units.HumanSize(float64(u.BlockRead)),
+			units.HumanSize(float64(u.BlockWrite)),
+		)
+	}
+	tw.Flush()
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+type byName []*group
+
+func (s byName) Len() int      { return len(s) }
+func (s byName) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byName) Less(i, j int) bool {
+	if s[i].ShortName == s[j].ShortName {
+		return s[i].Type < s[j].Type
+	}
+	return s[i].ShortName < s[j].ShortName
+}
@@ -0,0 +1,116 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/perform"
+	"github.com/eris-ltd/eris-cli/tests"
+	"github.com/eris-ltd/eris-cli/util"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestStats(t *testing.T) {
+	server, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	server.AddImage("quay.io/eris/keys")
+	server.Usage = func(container *docker.Container) *docker.Stats {
+		stats := &docker.Stats{}
+		stats.MemoryStats.Usage = 100
+		stats.MemoryStats.Limit = 1000
+		stats.Networks = map[string]docker.NetworkStats{"bridge": {RxBytes: 10, TxBytes: 20}}
+		stats.CPUStats.CPUUsage.TotalUsage = 200
+		stats.CPUStats.CPUUsage.PercpuUsage = []uint64{100, 100}
+		stats.CPUStats.SystemCPUUsage = 2000
+		stats.PreCPUStats.CPUUsage.TotalUsage = 100
+		stats.PreCPUStats.SystemCPUUsage = 1000
+		return stats
+	}
+
+	srv := def.BlankServiceDefinition()
+	srv.Name = "keys"
+	srv.Service.Name = "keys"
+	srv.Service.Image = "quay.io/eris/keys"
+	srv.Operations.ContainerType = def.TypeService
+	srv.Operations.SrvContainerName = util.ServiceContainerName("keys")
+	srv.Operations.Labels = util.Labels("keys", srv.Operations)
+	if err := perform.DockerRunService(srv.Service, srv.Operations); err != nil {
+		t.Fatalf("expected service to start, got %v", err)
+	}
+
+	if _, err := Collect(def.TypeChain, []string{"keys"}); err == nil {
+		t.Fatalf("expected no running chain error, got nil")
+	}
+
+	usage, err := Collect("all", nil)
+	if err != nil {
+		t.Fatalf("expected stats, got %v", err)
+	}
+	if len(usage) != 1 || usage[0].ShortName != "keys" || usage[0].MemoryUsage != 100 || usage[0].CPUPercent != 20 {
+		t.Fatalf("expected keys service usage, got %v", usage)
+	}
+
+	// The data container, never started, is listed along with the service
+	// but adds up nothing.
+	data := def.BlankOperation()
+	data.ContainerType = def.TypeData
+	if _, err := util.DockerClient.CreateContainer(docker.CreateContainerOptions{
+		Name: util.DataContainerName("keys"),
+		Config: &docker.Config{
+			Image:  "quay.io/eris/keys",
+			Labels: util.Labels("keys", data),
+		},
+	}); err != nil {
+		t.Fatalf("expected data container to be created, got %v", err)
+	}
+	out := new(bytes.Buffer)
+	if err := Display(out, def.TypeService, []string{"keys"}, "json", false); err != nil {
+		t.Fatalf("expected stats to be displayed, got %v", err)
+	}
+	var displayed []*Usage
+	if err := json.Unmarshal(out.Bytes(), &displayed); err != nil {
+		t.Fatalf("expected JSON output, got %v (%q)", err, out.String())
+	}
+	if len(displayed) != 1 || len(displayed[0].Containers) != 2 {
+		t.Fatalf("expected service and data containers, got %v", displayed)
+	}
+	if u := displayed[0]; u.MemoryUsage != 100 || u.MemoryLimit != 1000 || u.MemoryPercent != 10 || u.NetworkRx != 10 || u.NetworkTx != 20 {
+		t.Fatalf("expected service usage only, got %+v", u)
+	}
+
+	// Once running, it is added up with the service.
+	if err := util.DockerClient.StartContainer(util.DataContainerName("keys"), nil); err != nil {
+		t.Fatalf("expected data container to start, got %v", err)
+	}
+	usage, err = Collect(def.TypeService, []string{"keys"})
+	if err != nil {
+		t.Fatalf("expected stats, got %v", err)
+	}
+	if u := usage[0]; u.MemoryUsage != 200 || u.MemoryLimit != 1000 || u.MemoryPercent != 20 || u.NetworkRx != 20 || u.NetworkTx != 40 {
+		t.Fatalf("expected usage added up, got %+v", u)
+	}
+
+	// Streaming ends when the containers stop.
+	RefreshInterval = 10 * time.Millisecond
+	defer func() { RefreshInterval = time.Second }()
+	out.Reset()
+	names := []string{util.DataContainerName("keys"), util.ServiceContainerName("keys")}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		for _, name := range names {
+			util.DockerClient.StopContainer(name, 0)
+		}
+	}()
+	if err := Display(out, "all", nil, "", true); err != nil {
+		t.Fatalf("expected stats to be streamed, got %v", err)
+	}
+	if !strings.Contains(out.String(), "MEM USAGE / LIMIT") || !strings.Contains(out.String(), "keys") {
+		t.Fatalf("expected stats table, got %q", out.String())
+	}
+}
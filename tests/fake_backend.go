@@ -0,0 +1,1651 @@
+package tests
+
+import (
+	"archive/tar"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eris-ltd/eris-cli/util"
+
+	"github.com/eris-ltd/common/go/common"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// FakeBackend is an in-memory util.ContainerBackend implementation. It keeps
+// track of containers, images, networks, volumes, exec instances and
+// container files, and emits Docker events, so that the eris tool can be
+// exercised without a Docker daemon:
+//
+//	tests.UseFakeBackend()
+//
+// Containers don't run their images. When a container starts, the fake
+// runs its command if it is one of the few shell commands the fake knows
+// (see runCommand); containers created to run a command in the foreground
+// (attached or with stdin open) exit right after that, with a zero status
+// for unknown commands. Other containers keep running until stopped. Exec
+// instances are run the same way.
+//
+// Every image declares the ImageVolumes volumes; containers share volumes
+// via HostConfig.VolumesFrom. Bind mounts are backed by in-memory volumes
+// shared by all containers binding the same host path.
+type FakeBackend struct {
+	// Run, if set, replaces the command emulation when a container starts.
+	// If it returns exited=true, the container exits immediately with the
+	// exit code. Run is called with the backend locked and must not call
+	// it back.
+	Run func(container *docker.Container) (code int, exited bool)
+
+	// Exec, if set, replaces the command emulation for exec instances.
+	// Its return value is the exec instance exit code.
+	Exec func(container *docker.Container, cmd []string, stdout, stderr io.Writer) int
+
+	// Pull, if set, is called before an image is pulled; an error it
+	// returns fails the pull (e.g. to simulate an unreachable registry).
+	Pull func(opts docker.PullImageOptions, auth docker.AuthConfiguration) error
+
+	// Build, if set, is called before an image is built; an error it
+	// returns fails the build in the output stream, the way a failing
+	// Dockerfile instruction does.
+	Build func(opts docker.BuildImageOptions) error
+
+	// Usage, if set, returns the stats reported for a running container
+	// (zero usage otherwise). Usage is called with the backend locked and
+	// must not call it back.
+	Usage func(container *docker.Container) *docker.Stats
+
+	// ImageVolumes are the volumes declared by every image, the eris
+	// container root by default.
+	ImageVolumes []string
+
+	mu         sync.Mutex
+	containers map[string]*fakeContainer // by ID
+	images     map[string]*docker.Image  // by ID
+	tags       map[string]string         // image reference -> ID
+	networks   map[string]*docker.Network
+	binds      map[string]*fakeVolume // by host path
+	volumes    map[string]*fakeVolume // named volumes, by name
+	execs      map[string]*fakeExec
+	listeners  []chan<- *docker.APIEvents
+	pending    []*docker.APIEvents
+	history    []*docker.APIEvents // every event emitted, in order
+	lastIP     int
+	lastSeq    int
+}
+
+type fakeContainer struct {
+	*docker.Container
+
+	rootfs   *fakeVolume
+	mounts   map[string]*fakeVolume // by mount point
+	logs     []logEntry
+	attached []*attachment
+	done     chan struct{} // closed when the container stops running
+	seq      int           // creation order
+}
+
+type logEntry struct {
+	stderr bool
+	data   []byte
+}
+
+type attachment struct {
+	stdout, stderr io.Writer
+}
+
+type fakeExec struct {
+	docker.ExecInspect
+
+	cmd []string
+}
+
+var _ util.ContainerBackend = (*FakeBackend)(nil)
+
+// statsInterval is how often streamed container stats are sent (Docker
+// sends them every second).
+const statsInterval = 100 * time.Millisecond
+
+// NewFakeBackend returns an empty FakeBackend with the Docker built-in
+// networks (bridge, host, none) created.
+func NewFakeBackend() *FakeBackend {
+	f := &FakeBackend{
+		ImageVolumes: []string{common.ErisContainerRoot},
+
+		containers: make(map[string]*fakeContainer),
+		images:     make(map[string]*docker.Image),
+		tags:       make(map[string]string),
+		networks:   make(map[string]*docker.Network),
+		binds:      make(map[string]*fakeVolume),
+		volumes:    make(map[string]*fakeVolume),
+		execs:      make(map[string]*fakeExec),
+	}
+	for _, name := range []string{"bridge", "host", "none"} {
+		f.networks[name] = &docker.Network{
+			Name:       name,
+			ID:         randomID(),
+			Scope:      "local",
+			Driver:     name,
+			Containers: make(map[string]docker.Endpoint),
+		}
+	}
+	return f
+}
+
+// UseFakeBackend replaces util.DockerClient with a new FakeBackend and
+// returns it.
+func UseFakeBackend() *FakeBackend {
+	f := NewFakeBackend()
+	util.DockerClient = f
+	return f
+}
+
+// AddImage registers an image as if it has been pulled.
+func (f *FakeBackend) AddImage(name string) {
+	f.mu.Lock()
+	defer f.unlockAndEmit()
+
+	f.addImage(name)
+}
+
+// WriteLog appends output to the container logs.
+func (f *FakeBackend) WriteLog(id, output string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container(id)
+	if err != nil {
+		return err
+	}
+	io.WriteString(c.output(false), output)
+	return nil
+}
+
+// ExitContainer makes a running container exit with the exit code.
+func (f *FakeBackend) ExitContainer(id string, code int) error {
+	f.mu.Lock()
+	defer f.unlockAndEmit()
+
+	c, err := f.container(id)
+	if err != nil {
+		return err
+	}
+	if !c.State.Running {
+		return &docker.ContainerNotRunning{ID: id}
+	}
+	f.stop(c, code, "die")
+	return nil
+}
+
+// ReadFile returns the contents of a file in the container.
+func (f *FakeBackend) ReadFile(id, file string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container(id)
+	if err != nil {
+		return nil, err
+	}
+	content, ok := c.readFile(file)
+	if !ok {
+		return nil, fmt.Errorf("no such file in container %s: %s", id, file)
+	}
+	return content, nil
+}
+
+// ----------------------------------------------------------------------------
+// Containers
+
+func (f *FakeBackend) CreateContainer(opts docker.CreateContainerOptions) (*docker.Container, error) {
+	f.mu.Lock()
+	defer f.unlockAndEmit()
+
+	if opts.Config == nil {
+		return nil, apiError(http.StatusBadRequest, "config cannot be empty in order to create a container")
+	}
+
+	name := strings.TrimPrefix(opts.Name, "/")
+	if name == "" {
+		name = "fake_" + randomID()[:12]
+	}
+	if _, err := f.container(name); err == nil {
+		return nil, docker.ErrContainerAlreadyExists
+	}
+
+	image, err := f.image(opts.Config.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	hostConfig := opts.HostConfig
+	if hostConfig == nil {
+		hostConfig = &docker.HostConfig{}
+	}
+	mode := hostConfig.NetworkMode
+	if mode == "" || mode == "default" {
+		mode = "bridge"
+	}
+	if strings.HasPrefix(mode, "container:") {
+		if _, err := f.container(strings.TrimPrefix(mode, "container:")); err != nil {
+			return nil, err
+		}
+	} else if _, ok := f.network(mode); !ok {
+		return nil, apiError(http.StatusNotFound, fmt.Sprintf("network %s not found", mode))
+	}
+
+	config := *opts.Config
+	c := &fakeContainer{
+		Container: &docker.Container{
+			ID:         randomID(),
+			Name:       "/" + name,
+			Created:    time.Now(),
+			Config:     &config,
+			Image:      image.ID,
+			HostConfig: hostConfig,
+			NetworkSettings: &docker.NetworkSettings{
+				Networks: make(map[string]docker.ContainerNetwork),
+			},
+		},
+		rootfs: newVolume(""),
+		mounts: make(map[string]*fakeVolume),
+		done:   make(chan struct{}),
+	}
+	f.lastSeq++
+	c.seq = f.lastSeq
+	if len(config.Entrypoint) != 0 {
+		c.Path, c.Args = config.Entrypoint[0], append(config.Entrypoint[1:], config.Cmd...)
+	} else if len(config.Cmd) != 0 {
+		c.Path, c.Args = config.Cmd[0], config.Cmd[1:]
+	}
+
+	// Volumes: declared ones first, then those of the volumes-from
+	// containers and bind mounts, overriding the former.
+	for _, volume := range f.ImageVolumes {
+		c.mounts[path.Clean(volume)] = newVolume("")
+	}
+	for volume := range config.Volumes {
+		c.mounts[path.Clean(volume)] = newVolume("")
+	}
+	for _, from := range hostConfig.VolumesFrom {
+		source, err := f.container(strings.Split(from, ":")[0])
+		if err != nil {
+			return nil, err
+		}
+		for dest, volume := range source.mounts {
+			c.mounts[dest] = volume
+		}
+	}
+	for _, bind := range hostConfig.Binds {
+		parts := strings.Split(bind, ":")
+		if len(parts) < 2 {
+			continue
+		}
+		// Sources which aren't paths are named volumes,
+		// created on first use.
+		if !strings.HasPrefix(parts[0], "/") {
+			c.mounts[path.Clean(parts[1])] = f.volume(parts[0], nil)
+			continue
+		}
+		if _, ok := f.binds[parts[0]]; !ok {
+			f.binds[parts[0]] = newVolume(parts[0])
+		}
+		c.mounts[path.Clean(parts[1])] = f.binds[parts[0]]
+	}
+	c.Mounts = c.mountList()
+
+	if network, ok := f.network(mode); ok && mode != "host" && mode != "none" {
+		f.connect(c, network)
+	}
+
+	f.containers[c.ID] = c
+	f.event("create", c)
+	return clone(c.Container), nil
+}
+
+func (f *FakeBackend) StartContainer(id string, hostConfig *docker.HostConfig) error {
+	f.mu.Lock()
+	defer f.unlockAndEmit()
+
+	c, err := f.container(id)
+	if err != nil {
+		return err
+	}
+	if c.State.Running {
+		return &docker.ContainerAlreadyRunning{ID: id}
+	}
+	if hostConfig != nil {
+		c.HostConfig = hostConfig
+	}
+
+	select {
+	case <-c.done:
+		c.done = make(chan struct{})
+	default:
+	}
+	c.State = docker.State{
+		Running:   true,
+		Pid:       1000 + c.seq,
+		StartedAt: time.Now(),
+	}
+	c.NetworkSettings.Ports = make(map[docker.Port][]docker.PortBinding)
+	for port := range c.Config.ExposedPorts {
+		c.NetworkSettings.Ports[port] = nil
+	}
+	for port, bindings := range c.HostConfig.PortBindings {
+		c.NetworkSettings.Ports[port] = bindings
+	}
+	f.event("start", c)
+
+	if f.Run != nil {
+		if code, exited := f.Run(clone(c.Container)); exited {
+			f.stop(c, code, "die")
+		}
+		return nil
+	}
+
+	cmd := append(append([]string{}, c.Config.Entrypoint...), c.Config.Cmd...)
+	code, known := c.runCommand(cmd, c.output(false), c.output(true))
+	if known || c.Config.AttachStdout || c.Config.OpenStdin {
+		f.stop(c, code, "die")
+	}
+	return nil
+}
+
+func (f *FakeBackend) StopContainer(id string, timeout uint) error {
+	f.mu.Lock()
+	defer f.unlockAndEmit()
+
+	c, err := f.container(id)
+	if err != nil {
+		return err
+	}
+	if !c.State.Running {
+		return &docker.ContainerNotRunning{ID: id}
+	}
+	f.stop(c, 0, "die", "stop")
+	return nil
+}
+
+func (f *FakeBackend) KillContainer(opts docker.KillContainerOptions) error {
+	f.mu.Lock()
+	defer f.unlockAndEmit()
+
+	c, err := f.container(opts.ID)
+	if err != nil {
+		return err
+	}
+	if !c.State.Running {
+		return apiError(http.StatusInternalServerError, "Container "+opts.ID+" is not running")
+	}
+	f.stop(c, 137, "kill", "die")
+	return nil
+}
+
+func (f *FakeBackend) RemoveContainer(opts docker.RemoveContainerOptions) error {
+	f.mu.Lock()
+	defer f.unlockAndEmit()
+
+	c, err := f.container(opts.ID)
+	if err != nil {
+		return err
+	}
+	if c.State.Running {
+		if !opts.Force {
+			return apiError(http.StatusConflict, "You cannot remove a running container "+c.ID+". Stop the container before attempting removal or use -f")
+		}
+		f.stop(c, 137, "kill", "die")
+	}
+
+	for _, network := range f.networks {
+		delete(network.Containers, c.ID)
+	}
+	delete(f.containers, c.ID)
+	f.event("destroy", c)
+	return nil
+}
+
+func (f *FakeBackend) InspectContainer(id string) (*docker.Container, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container(id)
+	if err != nil {
+		return nil, err
+	}
+	return clone(c.Container), nil
+}
+
+// ListContainers supports the All option and the "name", "label" and
+// "status" filters.
+func (f *FakeBackend) ListContainers(opts docker.ListContainersOptions) ([]docker.APIContainers, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var list []docker.APIContainers
+	for _, c := range f.sortedContainers() {
+		if !opts.All && !c.State.Running {
+			continue
+		}
+		if !matchFilters(c, opts.Filters) {
+			continue
+		}
+
+		status := fmt.Sprintf("Exited (%d)", c.State.ExitCode)
+		if c.State.Running {
+			status = "Up"
+		} else if c.State.StartedAt.IsZero() {
+			status = "Created"
+		}
+		list = append(list, docker.APIContainers{
+			ID:      c.ID,
+			Image:   c.Config.Image,
+			Command: strings.Join(append([]string{c.Path}, c.Args...), " "),
+			Created: c.Created.Unix(),
+			Status:  status,
+			Ports:   c.NetworkSettings.PortMappingAPI(),
+			Names:   []string{c.Name},
+			Labels:  c.Config.Labels,
+		})
+		if opts.Limit > 0 && len(list) == opts.Limit {
+			break
+		}
+	}
+	return list, nil
+}
+
+func (f *FakeBackend) WaitContainer(id string) (int, error) {
+	f.mu.Lock()
+	c, err := f.container(id)
+	if err != nil {
+		f.mu.Unlock()
+		return 0, err
+	}
+	done := c.done
+	running := c.State.Running
+	f.mu.Unlock()
+
+	if running {
+		<-done
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return c.State.ExitCode, nil
+}
+
+// AttachToContainer writes the container logs (if opts.Logs is set) and,
+// if opts.Stream is set, the container output until it stops running.
+func (f *FakeBackend) AttachToContainer(opts docker.AttachToContainerOptions) error {
+	f.mu.Lock()
+	c, err := f.container(opts.Container)
+	if err != nil {
+		f.mu.Unlock()
+		return err
+	}
+	stdout, stderr := opts.OutputStream, opts.ErrorStream
+	if !opts.Stdout || stdout == nil {
+		stdout = ioutil.Discard
+	}
+	if !opts.Stderr || stderr == nil {
+		stderr = ioutil.Discard
+	}
+	if opts.Logs {
+		c.writeLogs(stdout, stderr, -1)
+	}
+	done := c.done
+	a := &attachment{stdout, stderr}
+	if opts.Stream {
+		c.attached = append(c.attached, a)
+	}
+	f.mu.Unlock()
+
+	if opts.Success != nil {
+		opts.Success <- struct{}{}
+		<-opts.Success
+	}
+	if !opts.Stream {
+		return nil
+	}
+
+	<-done
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range c.attached {
+		if c.attached[i] == a {
+			c.attached = append(c.attached[:i], c.attached[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Logs writes the container logs. opts.Tail limits the output to the last
+// lines given and opts.Follow blocks until the container stops running.
+func (f *FakeBackend) Logs(opts docker.LogsOptions) error {
+	f.mu.Lock()
+	c, err := f.container(opts.Container)
+	if err != nil {
+		f.mu.Unlock()
+		return err
+	}
+	stdout, stderr := opts.OutputStream, opts.ErrorStream
+	if !opts.Stdout || stdout == nil {
+		stdout = ioutil.Discard
+	}
+	if !opts.Stderr || stderr == nil {
+		stderr = ioutil.Discard
+	}
+	tail := -1
+	if n, err := strconv.Atoi(opts.Tail); err == nil {
+		tail = n
+	}
+	c.writeLogs(stdout, stderr, tail)
+	done := c.done
+	running := c.State.Running
+	f.mu.Unlock()
+
+	if opts.Follow && running {
+		<-done
+	}
+	return nil
+}
+
+// Stats sends the container resource usage to opts.Stats, once, or
+// every statsInterval until opts.Done is closed or the container stops
+// running if opts.Stream is true. opts.Stats is closed on return.
+func (f *FakeBackend) Stats(opts docker.StatsOptions) error {
+	defer close(opts.Stats)
+
+	for {
+		f.mu.Lock()
+		c, err := f.container(opts.ID)
+		if err != nil {
+			f.mu.Unlock()
+			return err
+		}
+		stats := &docker.Stats{}
+		if c.State.Running && f.Usage != nil {
+			if s := f.Usage(clone(c.Container)); s != nil {
+				stats = s
+			}
+		}
+		if stats.Read.IsZero() {
+			stats.Read = time.Now()
+		}
+		done := c.done
+		running := c.State.Running
+		f.mu.Unlock()
+
+		select {
+		case opts.Stats <- stats:
+		case <-opts.Done:
+			return nil
+		}
+		if !opts.Stream || !running {
+			return nil
+		}
+
+		select {
+		case <-time.After(statsInterval):
+		case <-done:
+			return nil
+		case <-opts.Done:
+			return nil
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Exec
+
+func (f *FakeBackend) CreateExec(opts docker.CreateExecOptions) (*docker.Exec, error) {
+	f.mu.Lock()
+	defer f.unlockAndEmit()
+
+	c, err := f.container(opts.Container)
+	if err != nil {
+		return nil, err
+	}
+	if !c.State.Running {
+		return nil, apiError(http.StatusConflict, "Container "+opts.Container+" is not running")
+	}
+
+	exec := &fakeExec{
+		ExecInspect: docker.ExecInspect{
+			ID:         randomID(),
+			OpenStdin:  opts.AttachStdin,
+			OpenStdout: opts.AttachStdout,
+			OpenStderr: opts.AttachStderr,
+			ProcessConfig: docker.ExecProcessConfig{
+				User: opts.User,
+				Tty:  opts.Tty,
+			},
+			Container: *c.Container,
+		},
+		cmd: opts.Cmd,
+	}
+	if len(opts.Cmd) != 0 {
+		exec.ProcessConfig.EntryPoint, exec.ProcessConfig.Arguments = opts.Cmd[0], opts.Cmd[1:]
+	}
+	f.execs[exec.ID] = exec
+	c.ExecIDs = append(c.ExecIDs, exec.ID)
+	f.event("exec_create", c)
+	return &docker.Exec{ID: exec.ID}, nil
+}
+
+func (f *FakeBackend) StartExec(id string, opts docker.StartExecOptions) error {
+	f.mu.Lock()
+	exec, ok := f.execs[id]
+	if !ok {
+		f.mu.Unlock()
+		return &docker.NoSuchExec{ID: id}
+	}
+	c, err := f.container(exec.Container.ID)
+	if err != nil {
+		f.mu.Unlock()
+		return err
+	}
+	exec.Running = true
+	f.event("exec_start", c)
+	f.unlockAndEmit()
+
+	if opts.Success != nil {
+		opts.Success <- struct{}{}
+		<-opts.Success
+	}
+
+	stdout, stderr := opts.OutputStream, opts.ErrorStream
+	if stdout == nil {
+		stdout = ioutil.Discard
+	}
+	if stderr == nil {
+		stderr = ioutil.Discard
+	}
+
+	var code int
+	if f.Exec != nil {
+		f.mu.Lock()
+		container := clone(c.Container)
+		f.mu.Unlock()
+
+		code = f.Exec(container, exec.cmd, stdout, stderr)
+	} else {
+		f.mu.Lock()
+		code, _ = c.runCommand(exec.cmd, stdout, stderr)
+		f.mu.Unlock()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	exec.Running = false
+	exec.ExitCode = code
+	return nil
+}
+
+func (f *FakeBackend) InspectExec(id string) (*docker.ExecInspect, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	exec, ok := f.execs[id]
+	if !ok {
+		return nil, &docker.NoSuchExec{ID: id}
+	}
+	inspect := exec.ExecInspect
+	return &inspect, nil
+}
+
+func (f *FakeBackend) ResizeExecTTY(id string, height, width int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.execs[id]; !ok {
+		return &docker.NoSuchExec{ID: id}
+	}
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// Files
+
+// UploadToContainer extracts the opts.InputStream tar archive into
+// the opts.Path directory of the container.
+func (f *FakeBackend) UploadToContainer(id string, opts docker.UploadToContainerOptions) error {
+	var entries []fileEntry
+	archive := tar.NewReader(opts.InputStream)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return apiError(http.StatusBadRequest, err.Error())
+		}
+
+		entry := fileEntry{name: path.Join("/", opts.Path, header.Name)}
+		if !header.FileInfo().IsDir() {
+			content, err := ioutil.ReadAll(archive)
+			if err != nil {
+				return apiError(http.StatusBadRequest, err.Error())
+			}
+			entry.content = append([]byte{}, content...)
+		}
+		entries = append(entries, entry)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, err := f.container(id)
+	if err != nil {
+		return err
+	}
+	if !c.isDir(opts.Path) {
+		return apiError(http.StatusNotFound, fmt.Sprintf("Could not find the file %s in container %s", opts.Path, id))
+	}
+	for _, entry := range entries {
+		if entry.content == nil {
+			c.mkdirAll(entry.name)
+		} else {
+			c.writeFile(entry.name, entry.content)
+		}
+	}
+	return nil
+}
+
+// DownloadFromContainer writes the opts.Path file or directory of
+// the container as a tar archive to opts.OutputStream.
+func (f *FakeBackend) DownloadFromContainer(id string, opts docker.DownloadFromContainerOptions) error {
+	f.mu.Lock()
+	c, err := f.container(id)
+	if err != nil {
+		f.mu.Unlock()
+		return err
+	}
+
+	root := path.Clean(path.Join("/", opts.Path))
+	if !c.exists(root) {
+		f.mu.Unlock()
+		return apiError(http.StatusNotFound, fmt.Sprintf("Could not find the file %s in container %s", opts.Path, id))
+	}
+	entries := c.walk(root)
+	f.mu.Unlock()
+
+	archive := tar.NewWriter(opts.OutputStream)
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:    path.Join(path.Base(root), strings.TrimPrefix(entry.name, root)),
+			Mode:    0644,
+			ModTime: time.Now(),
+		}
+		if entry.content == nil {
+			header.Typeflag = tar.TypeDir
+			header.Mode = 0755
+			header.Name += "/"
+		} else {
+			header.Typeflag = tar.TypeReg
+			header.Size = int64(len(entry.content))
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := archive.Write(entry.content); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
+
+// ----------------------------------------------------------------------------
+// Images
+
+func (f *FakeBackend) PullImage(opts docker.PullImageOptions, auth docker.AuthConfiguration) error {
+	if opts.Repository == "" {
+		return docker.ErrNoSuchImage
+	}
+
+	if f.Pull != nil {
+		if err := f.Pull(opts, auth); err != nil {
+			return err
+		}
+	}
+
+	name := opts.Repository
+	if opts.Tag != "" {
+		name += ":" + opts.Tag
+	}
+
+	f.mu.Lock()
+	name = f.addImage(name)
+	f.unlockAndEmit()
+
+	if opts.OutputStream != nil {
+		if opts.RawJSONStream {
+			fmt.Fprintf(opts.OutputStream, `{"status":"Status: Downloaded newer image for %s"}`+"\n", name)
+		} else {
+			fmt.Fprintf(opts.OutputStream, "Status: Downloaded newer image for %s\n", name)
+		}
+	}
+	return nil
+}
+
+func (f *FakeBackend) TagImage(name string, opts docker.TagImageOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	image, err := f.image(name)
+	if err != nil {
+		return err
+	}
+	ref := opts.Repo
+	if opts.Tag != "" {
+		ref += ":" + opts.Tag
+	}
+	f.tags[imageRef(ref)] = image.ID
+	return nil
+}
+
+func (f *FakeBackend) BuildImage(opts docker.BuildImageOptions) error {
+	if opts.InputStream != nil {
+		io.Copy(ioutil.Discard, opts.InputStream)
+	}
+
+	if f.Build != nil {
+		if err := f.Build(opts); err != nil {
+			// Raw streams carry the error to the reader.
+			if !opts.RawJSONStream || opts.OutputStream == nil {
+				return err
+			}
+			return json.NewEncoder(opts.OutputStream).Encode(map[string]interface{}{
+				"error":       err.Error(),
+				"errorDetail": map[string]string{"message": err.Error()},
+			})
+		}
+	}
+
+	f.mu.Lock()
+	name := f.addImage(opts.Name)
+	f.unlockAndEmit()
+
+	if opts.OutputStream != nil && !opts.SuppressOutput {
+		output := fmt.Sprintf("Successfully built %s\n", name)
+		if opts.RawJSONStream {
+			return json.NewEncoder(opts.OutputStream).Encode(map[string]string{"stream": output})
+		}
+		fmt.Fprint(opts.OutputStream, output)
+	}
+	return nil
+}
+
+func (f *FakeBackend) ListImages(opts docker.ListImagesOptions) ([]docker.APIImages, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var list []docker.APIImages
+	for _, image := range f.images {
+		list = append(list, docker.APIImages{
+			ID:       image.ID,
+			RepoTags: f.imageTags(image.ID),
+			Created:  image.Created.Unix(),
+		})
+	}
+	sort.Sort(imagesByID(list))
+	return list, nil
+}
+
+func (f *FakeBackend) InspectImage(name string) (*docker.Image, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	image, err := f.image(name)
+	if err != nil {
+		return nil, err
+	}
+	inspect := *image
+	return &inspect, nil
+}
+
+func (f *FakeBackend) RemoveImage(name string) error {
+	return f.RemoveImageExtended(name, docker.RemoveImageOptions{})
+}
+
+func (f *FakeBackend) RemoveImageExtended(name string, opts docker.RemoveImageOptions) error {
+	f.mu.Lock()
+	defer f.unlockAndEmit()
+
+	image, err := f.image(name)
+	if err != nil {
+		return err
+	}
+	if !opts.Force {
+		for _, c := range f.containers {
+			if c.Image == image.ID {
+				return apiError(http.StatusConflict, fmt.Sprintf("conflict: unable to remove repository reference %q (must force) - container %s is using its referenced image %s", name, c.ID[:12], image.ID[:12]))
+			}
+		}
+	}
+
+	// Removing by tag only untags, unless it's the last tag.
+	if ref := imageRef(name); f.tags[ref] == image.ID && len(f.imageTags(image.ID)) > 1 {
+		delete(f.tags, ref)
+		return nil
+	}
+	for ref, id := range f.tags {
+		if id == image.ID {
+			delete(f.tags, ref)
+		}
+	}
+	delete(f.images, image.ID)
+	f.pending = append(f.pending, &docker.APIEvents{Status: "delete", ID: image.ID, Time: time.Now().Unix()})
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// Networks
+
+func (f *FakeBackend) CreateNetwork(opts docker.CreateNetworkOptions) (*docker.Network, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.network(opts.Name); ok {
+		if opts.CheckDuplicate {
+			return nil, docker.ErrNetworkAlreadyExists
+		}
+	}
+
+	driver := opts.Driver
+	if driver == "" {
+		driver = "bridge"
+	}
+	network := &docker.Network{
+		Name:       opts.Name,
+		ID:         randomID(),
+		Scope:      "local",
+		Driver:     driver,
+		IPAM:       opts.IPAM,
+		Containers: make(map[string]docker.Endpoint),
+	}
+	f.networks[network.ID] = network
+
+	created := *network
+	created.Containers = nil
+	return &created, nil
+}
+
+func (f *FakeBackend) NetworkInfo(id string) (*docker.Network, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	network, ok := f.network(id)
+	if !ok {
+		return nil, &docker.NoSuchNetwork{ID: id}
+	}
+	return cloneNetwork(network), nil
+}
+
+func (f *FakeBackend) ListNetworks() ([]docker.Network, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var list []docker.Network
+	for _, network := range f.networks {
+		list = append(list, *cloneNetwork(network))
+	}
+	sort.Sort(networksByName(list))
+	return list, nil
+}
+
+func (f *FakeBackend) RemoveNetwork(id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	network, ok := f.network(id)
+	if !ok {
+		return &docker.NoSuchNetwork{ID: id}
+	}
+	switch network.Name {
+	case "bridge", "host", "none":
+		return apiError(http.StatusForbidden, network.Name+" is a pre-defined network and cannot be removed")
+	}
+	if len(network.Containers) != 0 {
+		return apiError(http.StatusForbidden, "network "+network.Name+" has active endpoints")
+	}
+	delete(f.networks, network.ID)
+	return nil
+}
+
+func (f *FakeBackend) ConnectNetwork(id string, opts docker.NetworkConnectionOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	network, ok := f.network(id)
+	c, err := f.container(opts.Container)
+	if !ok || err != nil {
+		return &docker.NoSuchNetworkOrContainer{NetworkID: id, ContainerID: opts.Container}
+	}
+	if _, ok := c.NetworkSettings.Networks[network.Name]; ok {
+		return apiError(http.StatusForbidden, fmt.Sprintf("container %s is already attached to network %s", opts.Container, network.Name))
+	}
+	f.connect(c, network)
+	return nil
+}
+
+func (f *FakeBackend) DisconnectNetwork(id string, opts docker.NetworkConnectionOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	network, ok := f.network(id)
+	c, err := f.container(opts.Container)
+	if !ok || err != nil {
+		return &docker.NoSuchNetworkOrContainer{NetworkID: id, ContainerID: opts.Container}
+	}
+	if _, ok := c.NetworkSettings.Networks[network.Name]; !ok {
+		return apiError(http.StatusInternalServerError, fmt.Sprintf("container %s is not connected to the network %s", opts.Container, network.Name))
+	}
+	delete(c.NetworkSettings.Networks, network.Name)
+	delete(network.Containers, c.ID)
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// Volumes
+
+func (f *FakeBackend) CreateVolume(opts docker.CreateVolumeOptions) (*docker.Volume, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if opts.Driver != "" && opts.Driver != "local" {
+		return nil, apiError(http.StatusInternalServerError, "Error looking up volume plugin "+opts.Driver+": plugin not found")
+	}
+	name := opts.Name
+	if name == "" {
+		name = randomID()
+	}
+
+	// Like Docker, return the existing volume if there is one.
+	return volumeInfo(f.volume(name, opts.Labels)), nil
+}
+
+func (f *FakeBackend) InspectVolume(name string) (*docker.Volume, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	volume, ok := f.volumes[name]
+	if !ok {
+		return nil, docker.ErrNoSuchVolume
+	}
+	return volumeInfo(volume), nil
+}
+
+func (f *FakeBackend) ListVolumes(opts docker.ListVolumesOptions) ([]docker.Volume, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var list []docker.Volume
+	for _, volume := range f.volumes {
+		if !matchLabels(volume.labels, opts.Filters["label"]) {
+			continue
+		}
+		list = append(list, *volumeInfo(volume))
+	}
+	sort.Sort(volumesByName(list))
+	return list, nil
+}
+
+func (f *FakeBackend) RemoveVolume(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	volume, ok := f.volumes[name]
+	if !ok {
+		return docker.ErrNoSuchVolume
+	}
+	for _, c := range f.containers {
+		for _, mounted := range c.mounts {
+			if mounted == volume {
+				return docker.ErrVolumeInUse
+			}
+		}
+	}
+	delete(f.volumes, name)
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+// Events and daemon
+
+func (f *FakeBackend) AddEventListener(listener chan<- *docker.APIEvents) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, l := range f.listeners {
+		if l == listener {
+			return docker.ErrListenerAlreadyExists
+		}
+	}
+	f.listeners = append(f.listeners, listener)
+	return nil
+}
+
+// Events sends the past events from opts.Since on and then the live
+// ones matching opts.Filters to opts.Events, until opts.Until passes or
+// opts.Done is signaled. opts.Events is closed on return.
+func (f *FakeBackend) Events(opts docker.EventsOptions) error {
+	defer close(opts.Events)
+
+	since, err := eventTime(opts.Since)
+	if err != nil {
+		return err
+	}
+	until, err := eventTime(opts.Until)
+	if err != nil {
+		return err
+	}
+
+	// Subscribe before copying the history, so no event is missed.
+	live := make(chan *docker.APIEvents, 64)
+	f.mu.Lock()
+	history := append([]*docker.APIEvents(nil), f.history...)
+	f.listeners = append(f.listeners, live)
+	f.mu.Unlock()
+	defer func() {
+		f.mu.Lock()
+		f.removeListener(live)
+		f.mu.Unlock()
+	}()
+
+	send := func(event *docker.APIEvents) bool {
+		if event.TimeNano < since || !matchEvent(event, opts.Filters) {
+			return true
+		}
+		select {
+		case opts.Events <- event:
+			return true
+		case <-opts.Done:
+			return false
+		}
+	}
+
+	for _, event := range history {
+		if until != 0 && event.TimeNano > until {
+			return nil
+		}
+		if !send(event) {
+			return nil
+		}
+	}
+
+	var deadline <-chan time.Time
+	if until != 0 {
+		timer := time.NewTimer(time.Duration(until - time.Now().UnixNano()))
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	for {
+		select {
+		case event := <-live:
+			if until != 0 && event.TimeNano > until {
+				return nil
+			}
+			if !send(event) {
+				return nil
+			}
+		case <-deadline:
+			return nil
+		case <-opts.Done:
+			return nil
+		}
+	}
+}
+
+func (f *FakeBackend) RemoveEventListener(listener chan *docker.APIEvents) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.removeListener(listener)
+	return nil
+}
+
+func (f *FakeBackend) Version() (*docker.Env, error) {
+	return &docker.Env{
+		"Version=1.10.3",
+		"ApiVersion=1.22",
+		"GoVersion=go1.5.3",
+		"Os=linux",
+		"Arch=amd64",
+	}, nil
+}
+
+// ----------------------------------------------------------------------------
+// Helpers; all expect f.mu to be held.
+
+// container looks a container up by ID, ID prefix, or name.
+func (f *FakeBackend) container(id string) (*fakeContainer, error) {
+	if c, ok := f.containers[id]; ok {
+		return c, nil
+	}
+	name := "/" + strings.TrimPrefix(id, "/")
+	var found *fakeContainer
+	for _, c := range f.containers {
+		if c.Name == name {
+			return c, nil
+		}
+		if id != "" && strings.HasPrefix(c.ID, id) {
+			found = c
+		}
+	}
+	if found != nil {
+		return found, nil
+	}
+	return nil, &docker.NoSuchContainer{ID: id}
+}
+
+func (f *FakeBackend) sortedContainers() []*fakeContainer {
+	var list []*fakeContainer
+	for _, c := range f.containers {
+		list = append(list, c)
+	}
+	sort.Sort(containersByAge(list))
+	return list
+}
+
+// network looks a network up by ID or name.
+func (f *FakeBackend) network(id string) (*docker.Network, bool) {
+	if network, ok := f.networks[id]; ok {
+		return network, true
+	}
+	for _, network := range f.networks {
+		if network.Name == id {
+			return network, true
+		}
+	}
+	return nil, false
+}
+
+// volume returns the named volume, creating it with the labels if it
+// doesn't exist.
+func (f *FakeBackend) volume(name string, labels map[string]string) *fakeVolume {
+	if volume, ok := f.volumes[name]; ok {
+		return volume
+	}
+	volume := newVolume("")
+	volume.name = name
+	volume.labels = make(map[string]string)
+	for k, v := range labels {
+		volume.labels[k] = v
+	}
+	f.volumes[name] = volume
+	return volume
+}
+
+func (f *FakeBackend) connect(c *fakeContainer, network *docker.Network) {
+	f.lastIP++
+	ip := fmt.Sprintf("172.17.%d.%d", f.lastIP/254, f.lastIP%254+1)
+	endpoint := randomID()
+
+	c.NetworkSettings.Networks[network.Name] = docker.ContainerNetwork{
+		IPAddress:   ip,
+		IPPrefixLen: 16,
+		EndpointID:  endpoint,
+	}
+	if c.NetworkSettings.IPAddress == "" {
+		c.NetworkSettings.IPAddress = ip
+		c.NetworkSettings.IPPrefixLen = 16
+	}
+	network.Containers[c.ID] = docker.Endpoint{
+		Name:        strings.TrimPrefix(c.Name, "/"),
+		ID:          endpoint,
+		IPv4Address: ip + "/16",
+	}
+}
+
+func (f *FakeBackend) image(name string) (*docker.Image, error) {
+	if id, ok := f.tags[imageRef(name)]; ok {
+		return f.images[id], nil
+	}
+	for id, image := range f.images {
+		if strings.HasPrefix(id, name) || strings.HasPrefix(id, "sha256:"+name) {
+			return image, nil
+		}
+	}
+	return nil, docker.ErrNoSuchImage
+}
+
+func (f *FakeBackend) imageTags(id string) []string {
+	var tags []string
+	for ref, imageID := range f.tags {
+		if imageID == id {
+			tags = append(tags, ref)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// addImage registers a new image under the name (replacing the
+// previous image with that name) and returns the full image reference.
+func (f *FakeBackend) addImage(name string) string {
+	ref := imageRef(name)
+	image := &docker.Image{
+		ID:      "sha256:" + randomID(),
+		Created: time.Now(),
+		Config:  &docker.Config{},
+	}
+	f.images[image.ID] = image
+	f.tags[ref] = image.ID
+	now := time.Now()
+	f.pending = append(f.pending, &docker.APIEvents{
+		Action:   "pull",
+		Type:     "image",
+		Actor:    docker.APIActor{ID: ref, Attributes: map[string]string{"name": ref}},
+		Status:   "pull",
+		ID:       ref,
+		Time:     now.Unix(),
+		TimeNano: now.UnixNano(),
+	})
+	return ref
+}
+
+func (f *FakeBackend) stop(c *fakeContainer, code int, events ...string) {
+	c.State.Running = false
+	c.State.Pid = 0
+	c.State.ExitCode = code
+	c.State.FinishedAt = time.Now()
+	close(c.done)
+	for _, status := range events {
+		f.event(status, c)
+	}
+}
+
+func (f *FakeBackend) event(status string, c *fakeContainer) {
+	// Like Docker, send the container name, image and labels along.
+	attributes := map[string]string{
+		"name":  strings.TrimPrefix(c.Name, "/"),
+		"image": c.Config.Image,
+	}
+	for key, value := range c.Config.Labels {
+		attributes[key] = value
+	}
+	if status == "die" {
+		attributes["exitCode"] = strconv.Itoa(c.State.ExitCode)
+	}
+
+	now := time.Now()
+	f.pending = append(f.pending, &docker.APIEvents{
+		Action:   status,
+		Type:     "container",
+		Actor:    docker.APIActor{ID: c.ID, Attributes: attributes},
+		Status:   status,
+		ID:       c.ID,
+		From:     c.Config.Image,
+		Time:     now.Unix(),
+		TimeNano: now.UnixNano(),
+	})
+}
+
+func (f *FakeBackend) removeListener(listener chan *docker.APIEvents) {
+	for i, l := range f.listeners {
+		if l == listener {
+			f.listeners = append(f.listeners[:i], f.listeners[i+1:]...)
+			return
+		}
+	}
+}
+
+// unlockAndEmit releases f.mu and delivers the pending events to the
+// listeners. Events are sent after unlocking, so that listeners may call
+// back into the backend.
+func (f *FakeBackend) unlockAndEmit() {
+	events, listeners := f.pending, append([]chan<- *docker.APIEvents(nil), f.listeners...)
+	f.history = append(f.history, f.pending...)
+	f.pending = nil
+	f.mu.Unlock()
+
+	for _, event := range events {
+		for _, listener := range listeners {
+			listener <- event
+		}
+	}
+}
+
+// output returns a writer appending to the container logs and copying
+// to the attached clients.
+func (c *fakeContainer) output(stderr bool) io.Writer {
+	return &containerOutput{c, stderr}
+}
+
+type containerOutput struct {
+	c      *fakeContainer
+	stderr bool
+}
+
+func (o *containerOutput) Write(p []byte) (int, error) {
+	o.c.logs = append(o.c.logs, logEntry{o.stderr, append([]byte{}, p...)})
+	for _, a := range o.c.attached {
+		if o.stderr {
+			a.stderr.Write(p)
+		} else {
+			a.stdout.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// writeLogs writes the last tail lines of the logs (all if tail < 0).
+func (c *fakeContainer) writeLogs(stdout, stderr io.Writer, tail int) {
+	entries := c.logs
+	if tail >= 0 {
+		lines := 0
+		for i := len(entries) - 1; i >= 0; i-- {
+			lines += strings.Count(string(entries[i].data), "\n")
+			if lines > tail {
+				entries = entries[i+1:]
+				break
+			}
+		}
+	}
+	for _, entry := range entries {
+		if entry.stderr {
+			stderr.Write(entry.data)
+		} else {
+			stdout.Write(entry.data)
+		}
+	}
+}
+
+func (c *fakeContainer) mountList() []docker.Mount {
+	var mounts []docker.Mount
+	for dest, volume := range c.mounts {
+		mount := docker.Mount{
+			Name:        volume.name,
+			Source:      volume.source,
+			Destination: dest,
+			Driver:      "local",
+			RW:          true,
+		}
+		if volume.source == "" {
+			mount.Source = "/var/lib/docker/volumes/" + volume.name + "/_data"
+		} else {
+			mount.Name = ""
+			mount.Driver = ""
+		}
+		mounts = append(mounts, mount)
+	}
+	sort.Sort(mountsByDestination(mounts))
+	return mounts
+}
+
+func matchFilters(c *fakeContainer, filters map[string][]string) bool {
+	for _, name := range filters["name"] {
+		if !strings.Contains(c.Name, name) {
+			return false
+		}
+	}
+	if !matchLabels(c.Config.Labels, filters["label"]) {
+		return false
+	}
+	for _, status := range filters["status"] {
+		switch {
+		case status == "running" && c.State.Running:
+		case status == "exited" && !c.State.Running && !c.State.FinishedAt.IsZero():
+		case status == "created" && c.State.StartedAt.IsZero():
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// matchLabels returns true if the labels have every "key" or "key=value"
+// label of the filter.
+func matchLabels(labels map[string]string, filter []string) bool {
+	for _, label := range filter {
+		parts := strings.SplitN(label, "=", 2)
+		value, ok := labels[parts[0]]
+		if !ok || (len(parts) == 2 && value != parts[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchEvent applies the Docker events filters: the event matches if it
+// matches any of the values given for every filter.
+func matchEvent(event *docker.APIEvents, filters map[string][]string) bool {
+	for filter, values := range filters {
+		matched := false
+		for _, value := range values {
+			switch filter {
+			case "type":
+				matched = event.Type == value
+			case "event":
+				matched = event.Action == value
+			case "container", "image":
+				matched = event.Actor.ID == value || event.Actor.Attributes["name"] == value
+			case "label":
+				parts := strings.SplitN(value, "=", 2)
+				label, ok := event.Actor.Attributes[parts[0]]
+				matched = ok && (len(parts) == 1 || label == parts[1])
+			}
+			if matched {
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// eventTime converts the events since or until parameter (Unix seconds,
+// possibly with a fractional part) to nanoseconds; 0 if it's empty.
+func eventTime(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	parts := strings.SplitN(value, ".", 2)
+	seconds, err := strconv.ParseInt(parts[0], 10, 64)
+	nanoseconds := int64(0)
+	if err == nil && len(parts) == 2 {
+		nanoseconds, err = strconv.ParseInt((parts[1] + "000000000")[:9], 10, 64)
+	}
+	if err != nil {
+		return 0, apiError(http.StatusBadRequest, fmt.Sprintf("invalid timestamp %q", value))
+	}
+	return seconds*int64(time.Second) + nanoseconds, nil
+}
+
+// imageRef adds the default "latest" tag to an image name without a tag.
+func imageRef(name string) string {
+	if i := strings.LastIndex(name, ":"); i < 0 || strings.Contains(name[i:], "/") {
+		return name + ":latest"
+	}
+	return name
+}
+
+func clone(c *docker.Container) *docker.Container {
+	container := *c
+	settings := *c.NetworkSettings
+	settings.Networks = make(map[string]docker.ContainerNetwork)
+	for name, network := range c.NetworkSettings.Networks {
+		settings.Networks[name] = network
+	}
+	settings.Ports = make(map[docker.Port][]docker.PortBinding)
+	for port, bindings := range c.NetworkSettings.Ports {
+		settings.Ports[port] = bindings
+	}
+	container.NetworkSettings = &settings
+	return &container
+}
+
+func cloneNetwork(n *docker.Network) *docker.Network {
+	network := *n
+	network.Containers = make(map[string]docker.Endpoint)
+	for id, endpoint := range n.Containers {
+		network.Containers[id] = endpoint
+	}
+	return &network
+}
+
+func volumeInfo(volume *fakeVolume) *docker.Volume {
+	info := &docker.Volume{
+		Name:       volume.name,
+		Driver:     "local",
+		Mountpoint: "/var/lib/docker/volumes/" + volume.name + "/_data",
+		Labels:     make(map[string]string),
+	}
+	for k, v := range volume.labels {
+		info.Labels[k] = v
+	}
+	return info
+}
+
+func apiError(status int, message string) error {
+	return &docker.Error{Status: status, Message: message}
+}
+
+func randomID() string {
+	id := make([]byte, 32)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+type containersByAge []*fakeContainer
+
+func (s containersByAge) Len() int           { return len(s) }
+func (s containersByAge) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s containersByAge) Less(i, j int) bool { return s[i].seq > s[j].seq }
+
+type imagesByID []docker.APIImages
+
+func (s imagesByID) Len() int           { return len(s) }
+func (s imagesByID) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s imagesByID) Less(i, j int) bool { return s[i].ID < s[j].ID }
+
+type networksByName []docker.Network
+
+func (s networksByName) Len() int           { return len(s) }
+func (s networksByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s networksByName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+
+type volumesByName []docker.Volume
+
+func (s volumesByName) Len() int           { return len(s) }
+func (s volumesByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s volumesByName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+
+type mountsByDestination []docker.Mount
+
+func (s mountsByDestination) Len() int           { return len(s) }
+func (s mountsByDestination) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s mountsByDestination) Less(i, j int) bool { return s[i].Destination < s[j].Destination }
@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestFakeBackendLifecycle(t *testing.T) {
+	f := NewFakeBackend()
+
+	events := make(chan *docker.APIEvents, 10)
+	f.AddEventListener(events)
+
+	opts := docker.CreateContainerOptions{
+		Name:   "fake",
+		Config: &docker.Config{Image: "quay.io/eris/base"},
+	}
+	if _, err := f.CreateContainer(opts); err != docker.ErrNoSuchImage {
+		t.Fatalf("expected no such image error, got %v", err)
+	}
+
+	f.AddImage("quay.io/eris/base")
+	if _, err := f.CreateContainer(opts); err != nil {
+		t.Fatalf("expected container to be created, got %v", err)
+	}
+	if _, err := f.CreateContainer(opts); err != docker.ErrContainerAlreadyExists {
+		t.Fatalf("expected container already exists error, got %v", err)
+	}
+
+	if err := f.StartContainer("fake", nil); err != nil {
+		t.Fatalf("expected container to start, got %v", err)
+	}
+	if list, _ := f.ListContainers(docker.ListContainersOptions{}); len(list) != 1 || list[0].Names[0] != "/fake" {
+		t.Fatalf("expected one running container, got %v", list)
+	}
+
+	if err := f.RemoveContainer(docker.RemoveContainerOptions{ID: "fake"}); err == nil {
+		t.Fatalf("expected running container removal to fail, got %v", err)
+	}
+	if err := f.ExitContainer("fake", 3); err != nil {
+		t.Fatalf("expected container to exit, got %v", err)
+	}
+	if code, err := f.WaitContainer("fake"); code != 3 || err != nil {
+		t.Fatalf("expected exit code 3, got %v (error %v)", code, err)
+	}
+	if list, _ := f.ListContainers(docker.ListContainersOptions{}); len(list) != 0 {
+		t.Fatalf("expected no running containers, got %v", list)
+	}
+	if err := f.RemoveContainer(docker.RemoveContainerOptions{ID: "fake"}); err != nil {
+		t.Fatalf("expected container to be removed, got %v", err)
+	}
+	if _, err := f.InspectContainer("fake"); err == nil {
+		t.Fatalf("expected container to be gone, got %v", err)
+	}
+
+	var statuses []string
+	for len(events) > 0 {
+		statuses = append(statuses, (<-events).Status)
+	}
+	if expected := []string{"pull", "create", "start", "die", "destroy"}; !equal(statuses, expected) {
+		t.Fatalf("expected events %v, got %v", expected, statuses)
+	}
+}
+
+func TestFakeBackendFiles(t *testing.T) {
+	f := NewFakeBackend()
+	f.AddImage("quay.io/eris/data")
+	if _, err := f.CreateContainer(docker.CreateContainerOptions{
+		Name:   "data",
+		Config: &docker.Config{Image: "quay.io/eris/data"},
+	}); err != nil {
+		t.Fatalf("expected container to be created, got %v", err)
+	}
+
+	in := new(bytes.Buffer)
+	archive := tar.NewWriter(in)
+	archive.WriteHeader(&tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0755})
+	archive.WriteHeader(&tar.Header{Name: "dir/file", Typeflag: tar.TypeReg, Mode: 0644, Size: 5})
+	archive.Write([]byte("hello"))
+	archive.Close()
+
+	if err := f.UploadToContainer("data", docker.UploadToContainerOptions{InputStream: in, Path: "/home/eris"}); err != nil {
+		t.Fatalf("expected upload to succeed, got %v", err)
+	}
+
+	out := new(bytes.Buffer)
+	if err := f.DownloadFromContainer("data", docker.DownloadFromContainerOptions{OutputStream: out, Path: "/home/eris/dir"}); err != nil {
+		t.Fatalf("expected download to succeed, got %v", err)
+	}
+
+	reader := tar.NewReader(out)
+	var names []string
+	for {
+		header, err := reader.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, header.Name)
+		if header.Name == "dir/file" {
+			if content, _ := ioutil.ReadAll(reader); string(content) != "hello" {
+				t.Fatalf("expected file contents to match, got %q", content)
+			}
+		}
+	}
+	if expected := []string{"dir/", "dir/file"}; !equal(names, expected) {
+		t.Fatalf("expected archive entries %v, got %v", expected, names)
+	}
+
+	if err := f.DownloadFromContainer("data", docker.DownloadFromContainerOptions{OutputStream: out, Path: "/missing"}); err == nil {
+		t.Fatalf("expected missing path download to fail, got %v", err)
+	}
+}
+
+func TestFakeBackendNetworks(t *testing.T) {
+	f := NewFakeBackend()
+	f.AddImage("quay.io/eris/base")
+
+	if _, err := f.CreateNetwork(docker.CreateNetworkOptions{Name: "net", CheckDuplicate: true}); err != nil {
+		t.Fatalf("expected network to be created, got %v", err)
+	}
+	if _, err := f.CreateNetwork(docker.CreateNetworkOptions{Name: "net", CheckDuplicate: true}); err != docker.ErrNetworkAlreadyExists {
+		t.Fatalf("expected network already exists error, got %v", err)
+	}
+
+	if _, err := f.CreateContainer(docker.CreateContainerOptions{
+		Name:       "fake",
+		Config:     &docker.Config{Image: "quay.io/eris/base"},
+		HostConfig: &docker.HostConfig{NetworkMode: "net"},
+	}); err != nil {
+		t.Fatalf("expected container to be created, got %v", err)
+	}
+	if err := f.ConnectNetwork("bridge", docker.NetworkConnectionOptions{Container: "fake"}); err != nil {
+		t.Fatalf("expected container to be connected, got %v", err)
+	}
+
+	container, _ := f.InspectContainer("fake")
+	if len(container.NetworkSettings.Networks) != 2 {
+		t.Fatalf("expected container on two networks, got %v", container.NetworkSettings.Networks)
+	}
+	if err := f.RemoveNetwork("net"); err == nil {
+		t.Fatalf("expected network in use removal to fail, got %v", err)
+	}
+
+	f.RemoveContainer(docker.RemoveContainerOptions{ID: "fake"})
+	if err := f.RemoveNetwork("net"); err != nil {
+		t.Fatalf("expected network to be removed, got %v", err)
+	}
+	if _, err := f.NetworkInfo("net"); err == nil {
+		t.Fatalf("expected network to be gone, got %v", err)
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
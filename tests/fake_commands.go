@@ -0,0 +1,459 @@
+package tests
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// fakeVolume is an in-memory directory tree. Files are keyed by their
+// path relative to the volume root; directories have nil contents.
+type fakeVolume struct {
+	name   string
+	source string            // host path for bind mounts
+	labels map[string]string // named volumes only
+	files  map[string][]byte
+}
+
+type fileEntry struct {
+	name    string // absolute path in the container
+	content []byte // nil for directories
+}
+
+func newVolume(source string) *fakeVolume {
+	return &fakeVolume{
+		name:   randomID(),
+		source: source,
+		files:  make(map[string][]byte),
+	}
+}
+
+// resolve returns the volume the path is stored in (the one mounted
+// closest to it, or the container root) and the path relative to it.
+func (c *fakeContainer) resolve(file string) (*fakeVolume, string) {
+	file = path.Clean(path.Join("/", file))
+
+	volume, root := c.rootfs, "/"
+	for dest, v := range c.mounts {
+		if (file == dest || strings.HasPrefix(file, dest+"/")) && len(dest) > len(root) {
+			volume, root = v, dest
+		}
+	}
+	return volume, strings.TrimPrefix(strings.TrimPrefix(file, root), "/")
+}
+
+func (c *fakeContainer) isDir(file string) bool {
+	file = path.Clean(path.Join("/", file))
+	if file == "/" {
+		return true
+	}
+	for dest := range c.mounts {
+		if dest == file || strings.HasPrefix(dest, file+"/") {
+			return true
+		}
+	}
+
+	volume, rel := c.resolve(file)
+	content, ok := volume.files[rel]
+	return ok && content == nil
+}
+
+func (c *fakeContainer) exists(file string) bool {
+	_, ok := c.readFile(file)
+	return ok || c.isDir(file)
+}
+
+func (c *fakeContainer) readFile(file string) ([]byte, bool) {
+	volume, rel := c.resolve(file)
+	content, ok := volume.files[rel]
+	return content, ok && content != nil
+}
+
+func (c *fakeContainer) writeFile(file string, content []byte) {
+	c.mkdirAll(path.Dir(path.Join("/", file)))
+	if content == nil {
+		content = []byte{}
+	}
+	volume, rel := c.resolve(file)
+	volume.files[rel] = content
+}
+
+func (c *fakeContainer) mkdirAll(dir string) {
+	current := "/"
+	for _, part := range strings.Split(path.Clean(path.Join("/", dir)), "/") {
+		current = path.Join(current, part)
+		if !c.isDir(current) {
+			volume, rel := c.resolve(current)
+			volume.files[rel] = nil
+		}
+	}
+}
+
+// remove deletes a file, or a directory with everything below it.
+// Mount points are emptied rather than removed.
+func (c *fakeContainer) remove(file string) {
+	for _, entry := range c.walk(file) {
+		volume, rel := c.resolve(entry.name)
+		delete(volume.files, rel)
+	}
+}
+
+// walk returns the file or directory and, for a directory, everything
+// below it, sorted by path.
+func (c *fakeContainer) walk(root string) []fileEntry {
+	root = path.Clean(path.Join("/", root))
+
+	all := make(map[string][]byte)
+	add := func(name string, content []byte) {
+		if name != root && !strings.HasPrefix(name, strings.TrimSuffix(root, "/")+"/") {
+			return
+		}
+		all[name] = content
+	}
+	// Files shadowed by a mount are skipped.
+	for rel, content := range c.rootfs.files {
+		if volume, _ := c.resolve("/" + rel); volume == c.rootfs {
+			add("/"+rel, content)
+		}
+	}
+	for dest, volume := range c.mounts {
+		for dir := dest; dir != "/"; dir = path.Dir(dir) {
+			add(dir, nil)
+		}
+		for rel, content := range volume.files {
+			name := path.Join(dest, rel)
+			if v, _ := c.resolve(name); v == volume {
+				add(name, content)
+			}
+		}
+	}
+	if root == "/" {
+		all["/"] = nil
+	}
+
+	var names []string
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var entries []fileEntry
+	for _, name := range names {
+		entries = append(entries, fileEntry{name, all[name]})
+	}
+	return entries
+}
+
+// command is a shell command emulated by the fake backend. Paths are
+// relative to the container working directory.
+type command func(c *fakeContainer, args []string, stdout, stderr io.Writer) int
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"true":  func(*fakeContainer, []string, io.Writer, io.Writer) int { return 0 },
+		"false": func(*fakeContainer, []string, io.Writer, io.Writer) int { return 1 },
+		"chown": func(*fakeContainer, []string, io.Writer, io.Writer) int { return 0 },
+		"chmod": func(*fakeContainer, []string, io.Writer, io.Writer) int { return 0 },
+		"echo":  echoCommand,
+		"cat":   catCommand,
+		"ls":    lsCommand,
+		"test":  testCommand,
+		"[":     testCommand,
+		"mkdir": mkdirCommand,
+		"rm":    rmCommand,
+		"mv":    mvCommand,
+		"touch": touchCommand,
+		"find":  findCommand,
+	}
+}
+
+// runCommand runs the command if the fake knows it. A shell command
+// (sh -c or bash -c) is known if it's a chain of known commands joined
+// with &&.
+func (c *fakeContainer) runCommand(cmd []string, stdout, stderr io.Writer) (code int, known bool) {
+	if len(cmd) == 0 {
+		return 0, false
+	}
+
+	var chain [][]string
+	switch path.Base(cmd[0]) {
+	case "sh", "bash":
+		if len(cmd) != 3 || cmd[1] != "-c" {
+			return 0, false
+		}
+		for _, part := range strings.Split(cmd[2], "&&") {
+			args := strings.Fields(strings.NewReplacer(`"`, "", "'", "").Replace(part))
+			if len(args) == 0 {
+				return 0, false
+			}
+			chain = append(chain, args)
+		}
+	default:
+		chain = [][]string{cmd}
+	}
+
+	for _, args := range chain {
+		if _, ok := commands[path.Base(args[0])]; !ok {
+			return 0, false
+		}
+	}
+	for _, args := range chain {
+		if code := commands[path.Base(args[0])](c, args[1:], stdout, stderr); code != 0 {
+			return code, true
+		}
+	}
+	return 0, true
+}
+
+// abs makes the path absolute relative to the working directory.
+func (c *fakeContainer) abs(file string) string {
+	if path.IsAbs(file) {
+		return path.Clean(file)
+	}
+	return path.Join("/", c.Config.WorkingDir, file)
+}
+
+// flags splits command arguments into flag letters (long flags are
+// listed by name) and operands.
+func flags(args []string) (map[string]bool, []string) {
+	set := make(map[string]bool)
+	var operands []string
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--"):
+			set[arg[2:]] = true
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			for _, letter := range arg[1:] {
+				set[string(letter)] = true
+			}
+		default:
+			operands = append(operands, arg)
+		}
+	}
+	return set, operands
+}
+
+func echoCommand(c *fakeContainer, args []string, stdout, stderr io.Writer) int {
+	newline := "\n"
+	if len(args) != 0 && args[0] == "-n" {
+		args, newline = args[1:], ""
+	}
+	io.WriteString(stdout, strings.Join(args, " ")+newline)
+	return 0
+}
+
+func catCommand(c *fakeContainer, args []string, stdout, stderr io.Writer) int {
+	code := 0
+	for _, file := range args {
+		content, ok := c.readFile(c.abs(file))
+		if !ok {
+			fmt.Fprintf(stderr, "cat: %s: No such file or directory\n", file)
+			code = 1
+			continue
+		}
+		stdout.Write(content)
+	}
+	return code
+}
+
+func lsCommand(c *fakeContainer, args []string, stdout, stderr io.Writer) int {
+	_, operands := flags(args)
+	if len(operands) == 0 {
+		operands = []string{"."}
+	}
+
+	code := 0
+	for _, file := range operands {
+		dir := c.abs(file)
+		switch {
+		case c.isDir(dir):
+			for _, entry := range c.walk(dir) {
+				if entry.name != dir && path.Dir(entry.name) == dir {
+					fmt.Fprintln(stdout, path.Base(entry.name))
+				}
+			}
+		case c.exists(dir):
+			fmt.Fprintln(stdout, file)
+		default:
+			fmt.Fprintf(stderr, "ls: cannot access %s: No such file or directory\n", file)
+			code = 2
+		}
+	}
+	return code
+}
+
+func testCommand(c *fakeContainer, args []string, stdout, stderr io.Writer) int {
+	if len(args) != 0 && args[len(args)-1] == "]" {
+		args = args[:len(args)-1]
+	}
+	if len(args) != 2 {
+		return 2
+	}
+
+	var ok bool
+	file := c.abs(args[1])
+	switch args[0] {
+	case "-d":
+		ok = c.isDir(file)
+	case "-f":
+		_, ok = c.readFile(file)
+	case "-e":
+		ok = c.exists(file)
+	default:
+		return 2
+	}
+	if !ok {
+		return 1
+	}
+	return 0
+}
+
+func mkdirCommand(c *fakeContainer, args []string, stdout, stderr io.Writer) int {
+	set, operands := flags(args)
+	parents := set["p"] || set["parents"]
+
+	code := 0
+	for _, file := range operands {
+		dir := c.abs(file)
+		switch {
+		case parents:
+			c.mkdirAll(dir)
+		case c.exists(dir):
+			fmt.Fprintf(stderr, "mkdir: cannot create directory '%s': File exists\n", file)
+			code = 1
+		case !c.isDir(path.Dir(dir)):
+			fmt.Fprintf(stderr, "mkdir: cannot create directory '%s': No such file or directory\n", file)
+			code = 1
+		default:
+			c.mkdirAll(dir)
+		}
+	}
+	return code
+}
+
+func rmCommand(c *fakeContainer, args []string, stdout, stderr io.Writer) int {
+	set, operands := flags(args)
+	recursive := set["r"] || set["R"] || set["recursive"]
+	force := set["f"] || set["force"]
+
+	code := 0
+	for _, file := range operands {
+		name := c.abs(file)
+		switch {
+		case !c.exists(name):
+			if !force {
+				fmt.Fprintf(stderr, "rm: cannot remove '%s': No such file or directory\n", file)
+				code = 1
+			}
+		case c.isDir(name) && !recursive:
+			fmt.Fprintf(stderr, "rm: cannot remove '%s': Is a directory\n", file)
+			code = 1
+		default:
+			c.remove(name)
+		}
+	}
+	return code
+}
+
+// findCommand knows the starting points, -mindepth and -delete; without
+// -delete the files found are printed.
+func findCommand(c *fakeContainer, args []string, stdout, stderr io.Writer) int {
+	var (
+		roots    []string
+		mindepth int
+		del      bool
+	)
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-mindepth":
+			if i+1 < len(args) {
+				mindepth, _ = strconv.Atoi(args[i+1])
+				i++
+			}
+		case "-delete":
+			del = true
+		default:
+			roots = append(roots, args[i])
+		}
+	}
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+
+	code := 0
+	for _, root := range roots {
+		dir := c.abs(root)
+		if !c.exists(dir) {
+			fmt.Fprintf(stderr, "find: '%s': No such file or directory\n", root)
+			code = 1
+			continue
+		}
+		for _, entry := range c.walk(dir) {
+			depth := 0
+			if rel := strings.Trim(strings.TrimPrefix(entry.name, dir), "/"); rel != "" {
+				depth = strings.Count(rel, "/") + 1
+			}
+			if depth < mindepth {
+				continue
+			}
+			if del {
+				c.remove(entry.name)
+			} else {
+				fmt.Fprintln(stdout, entry.name)
+			}
+		}
+	}
+	return code
+}
+
+func mvCommand(c *fakeContainer, args []string, stdout, stderr io.Writer) int {
+	_, operands := flags(args)
+	if len(operands) != 2 {
+		fmt.Fprintln(stderr, "mv: missing file operand")
+		return 1
+	}
+
+	from, to := c.abs(operands[0]), c.abs(operands[1])
+	if !c.exists(from) {
+		fmt.Fprintf(stderr, "mv: cannot stat '%s': No such file or directory\n", operands[0])
+		return 1
+	}
+	if c.isDir(to) {
+		to = path.Join(to, path.Base(from))
+	}
+
+	entries := c.walk(from)
+	c.remove(from)
+	for _, entry := range entries {
+		name := path.Join(to, strings.TrimPrefix(entry.name, from))
+		if entry.content == nil {
+			c.mkdirAll(name)
+		} else {
+			c.writeFile(name, entry.content)
+		}
+	}
+	return 0
+}
+
+func touchCommand(c *fakeContainer, args []string, stdout, stderr io.Writer) int {
+	_, operands := flags(args)
+
+	code := 0
+	for _, file := range operands {
+		name := c.abs(file)
+		switch {
+		case c.exists(name):
+		case !c.isDir(path.Dir(name)):
+			fmt.Fprintf(stderr, "touch: cannot touch '%s': No such file or directory\n", file)
+			code = 1
+		default:
+			c.writeFile(name, nil)
+		}
+	}
+	return code
+}
@@ -1,13 +1,23 @@
 package tests
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
+
+	docker "github.com/fsouza/go-dockerclient"
 )
 
 // This is a fake server implementation to test Eris command line tools
@@ -51,7 +61,7 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w.Header()[k] = v
 	}
 	w.WriteHeader(s.response.Code)
-	fmt.Fprintf(w, s.response.Body)
+	fmt.Fprint(w, s.response.Body)
 }
 
 // NewServer creates a new fake server that serves requests at addr base URL.
@@ -81,14 +91,20 @@ func NewServer(addr ...interface{}) *Server {
 		},
 	}
 
+	s.server = newHTTPServer(s, addr...)
+	return s
+}
+
+// newHTTPServer starts an httptest.Server serving handler at addr
+// (see NewServer) or at a random port if addr is omitted.
+func newHTTPServer(handler http.Handler, addr ...interface{}) *httptest.Server {
 	// NewServer().
 	if len(addr) == 0 {
-		s.server = httptest.NewServer(s)
-		return s
+		return httptest.NewServer(handler)
 	}
 
 	// NewServer(addr).
-	s.server = httptest.NewUnstartedServer(s)
+	server := httptest.NewUnstartedServer(handler)
 
 	address, ok := addr[0].(string)
 	if !ok {
@@ -107,10 +123,10 @@ func NewServer(addr ...interface{}) *Server {
 			panic(err)
 		}
 	}
-	s.server.Listener = listener
-	s.server.Start()
+	server.Listener = listener
+	server.Start()
 
-	return s
+	return server
 }
 
 // Method returns the last HTTP method used to call the server.
@@ -175,3 +191,686 @@ func (s *Server) setBody(body string) {
 
 	s.body = body
 }
+
+// DockerServer is a fake Docker Engine API server backed by a FakeBackend.
+// It serves the Docker Remote API calls the eris tool makes, so the tool
+// can be pointed at it through DOCKER_HOST:
+//
+//   server := tests.NewDockerServer()
+//   defer server.Close()
+//
+//   os.Setenv("DOCKER_HOST", server.URL())
+//   util.DockerConnect(false, "eris")
+//
+// The backend state can be inspected and manipulated through the embedded
+// FakeBackend.
+type DockerServer struct {
+	*FakeBackend
+
+	server *httptest.Server
+}
+
+// NewDockerServer starts a fake Docker Engine API server with an empty
+// FakeBackend. The optional addr parameter is the same as in NewServer.
+// NewDockerServer panics on error.
+func NewDockerServer(addr ...interface{}) *DockerServer {
+	s := &DockerServer{FakeBackend: NewFakeBackend()}
+	s.server = newHTTPServer(s, addr...)
+	return s
+}
+
+// URL returns the server address in the DOCKER_HOST format.
+func (s *DockerServer) URL() string {
+	return "tcp://" + s.server.Listener.Addr().String()
+}
+
+// Close stops the server.
+func (s *DockerServer) Close() {
+	s.server.CloseClientConnections()
+	s.server.Close()
+}
+
+var apiVersionPrefix = regexp.MustCompile(`^/v[0-9.]+/`)
+
+// ServeHTTP is an http.Handler interface implementation. It routes
+// Docker Remote API calls to the backend.
+func (s *DockerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := apiVersionPrefix.ReplaceAllString(r.URL.Path, "/")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch {
+	case path == "/_ping":
+		fmt.Fprint(w, "OK")
+	case path == "/version":
+		env, _ := s.Version()
+		writeJSON(w, http.StatusOK, env.Map())
+	case path == "/events":
+		s.events(w, r)
+	case path == "/build":
+		s.buildImage(w, r)
+	case parts[0] == "containers":
+		s.containers(w, r, parts[1:])
+	case parts[0] == "exec" && len(parts) == 3:
+		s.exec(w, r, parts[1], parts[2])
+	case parts[0] == "images":
+		s.images(w, r, strings.Join(parts[1:], "/"))
+	case parts[0] == "networks":
+		s.networks(w, r, parts[1:])
+	case parts[0] == "volumes":
+		s.volumes(w, r, parts[1:])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *DockerServer) containers(w http.ResponseWriter, r *http.Request, parts []string) {
+	query := r.URL.Query()
+
+	switch {
+	case len(parts) == 1 && parts[0] == "json":
+		opts := docker.ListContainersOptions{All: query.Get("all") == "1"}
+		opts.Limit, _ = strconv.Atoi(query.Get("limit"))
+		if filters := query.Get("filters"); filters != "" {
+			json.Unmarshal([]byte(filters), &opts.Filters)
+		}
+		list, err := s.ListContainers(opts)
+		if list == nil {
+			list = []docker.APIContainers{}
+		}
+		writeResult(w, list, err)
+
+	case len(parts) == 1 && parts[0] == "create":
+		var config struct {
+			*docker.Config
+			HostConfig       *docker.HostConfig
+			NetworkingConfig *docker.NetworkingConfig
+		}
+		if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+			writeError(w, apiError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		c, err := s.CreateContainer(docker.CreateContainerOptions{
+			Name:             query.Get("name"),
+			Config:           config.Config,
+			HostConfig:       config.HostConfig,
+			NetworkingConfig: config.NetworkingConfig,
+		})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, map[string]string{"Id": c.ID})
+
+	case len(parts) == 1 && r.Method == "DELETE":
+		writeResult(w, nil, s.RemoveContainer(docker.RemoveContainerOptions{
+			ID:            parts[0],
+			RemoveVolumes: query.Get("v") == "1",
+			Force:         query.Get("force") == "1",
+		}))
+
+	case len(parts) == 2:
+		s.container(w, r, parts[0], parts[1])
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *DockerServer) container(w http.ResponseWriter, r *http.Request, id, action string) {
+	query := r.URL.Query()
+
+	switch action {
+	case "json":
+		c, err := s.InspectContainer(id)
+		writeResult(w, c, err)
+
+	case "start":
+		var hostConfig *docker.HostConfig
+		json.NewDecoder(r.Body).Decode(&hostConfig)
+		writeResult(w, nil, s.StartContainer(id, hostConfig))
+
+	case "stop":
+		timeout, _ := strconv.Atoi(query.Get("t"))
+		writeResult(w, nil, s.StopContainer(id, uint(timeout)))
+
+	case "kill":
+		writeResult(w, nil, s.KillContainer(docker.KillContainerOptions{ID: id}))
+
+	case "wait":
+		code, err := s.WaitContainer(id)
+		writeResult(w, map[string]int{"StatusCode": code}, err)
+
+	case "logs":
+		c, err := s.InspectContainer(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		out := newOutputStream(w, c.Config.Tty)
+		opts := docker.LogsOptions{
+			Container:    id,
+			OutputStream: out.stdout(),
+			ErrorStream:  out.stderr(),
+			Follow:       query.Get("follow") == "1",
+			Stdout:       query.Get("stdout") == "1",
+			Stderr:       query.Get("stderr") == "1",
+			Tail:         query.Get("tail"),
+		}
+		w.Header().Set("Content-Type", "application/vnd.docker.raw-stream")
+		w.WriteHeader(http.StatusOK)
+		out.start()
+		s.Logs(opts)
+
+	case "attach":
+		c, err := s.InspectContainer(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		conn, out, err := hijack(w, c.Config.Tty)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		defer conn.Close()
+
+		success := make(chan struct{})
+		errs := make(chan error, 1)
+		go func() {
+			errs <- s.AttachToContainer(docker.AttachToContainerOptions{
+				Container:    id,
+				OutputStream: out.stdout(),
+				ErrorStream:  out.stderr(),
+				Logs:         query.Get("logs") == "1",
+				Stream:       query.Get("stream") == "1",
+				Stdout:       query.Get("stdout") == "1",
+				Stderr:       query.Get("stderr") == "1",
+				Success:      success,
+			})
+		}()
+		select {
+		case <-success:
+			out.start()
+			success <- struct{}{}
+			<-errs
+		case <-errs:
+		}
+
+	case "stats":
+		s.stats(w, r, id)
+
+	case "exec":
+		var opts docker.CreateExecOptions
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			writeError(w, apiError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		opts.Container = id
+		exec, err := s.CreateExec(opts)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, exec)
+
+	case "archive":
+		switch r.Method {
+		case "PUT":
+			writeResult(w, nil, s.UploadToContainer(id, docker.UploadToContainerOptions{
+				InputStream: r.Body,
+				Path:        query.Get("path"),
+			}))
+		case "GET":
+			// Check the path first, the headers can't be changed once
+			// the archive is being written.
+			buf := new(bytes.Buffer)
+			if err := s.DownloadFromContainer(id, docker.DownloadFromContainerOptions{
+				OutputStream: buf,
+				Path:         query.Get("path"),
+			}); err != nil {
+				writeError(w, err)
+				return
+			}
+			w.Header().Set("Content-Type", "application/x-tar")
+			io.Copy(w, buf)
+		default:
+			http.NotFound(w, r)
+		}
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *DockerServer) exec(w http.ResponseWriter, r *http.Request, id, action string) {
+	switch action {
+	case "json":
+		exec, err := s.InspectExec(id)
+		writeResult(w, exec, err)
+
+	case "resize":
+		height, _ := strconv.Atoi(r.URL.Query().Get("h"))
+		width, _ := strconv.Atoi(r.URL.Query().Get("w"))
+		writeResult(w, nil, s.ResizeExecTTY(id, height, width))
+
+	case "start":
+		var opts docker.StartExecOptions
+		json.NewDecoder(r.Body).Decode(&opts)
+
+		inspect, err := s.InspectExec(id)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if opts.Detach {
+			go s.StartExec(id, docker.StartExecOptions{})
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		conn, out, err := hijack(w, inspect.ProcessConfig.Tty)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		defer conn.Close()
+
+		success := make(chan struct{})
+		errs := make(chan error, 1)
+		go func() {
+			errs <- s.StartExec(id, docker.StartExecOptions{
+				OutputStream: out.stdout(),
+				ErrorStream:  out.stderr(),
+				Success:      success,
+			})
+		}()
+		select {
+		case <-success:
+			out.start()
+			success <- struct{}{}
+			<-errs
+		case <-errs:
+		}
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *DockerServer) images(w http.ResponseWriter, r *http.Request, name string) {
+	query := r.URL.Query()
+
+	switch {
+	case name == "json":
+		list, err := s.ListImages(docker.ListImagesOptions{})
+		if list == nil {
+			list = []docker.APIImages{}
+		}
+		writeResult(w, list, err)
+
+	case name == "create":
+		var auth docker.AuthConfiguration
+		if header, err := base64.URLEncoding.DecodeString(r.Header.Get("X-Registry-Auth")); err == nil {
+			json.Unmarshal(header, &auth)
+		}
+
+		out := new(bytes.Buffer)
+		if err := s.PullImage(docker.PullImageOptions{
+			Repository:    query.Get("fromImage"),
+			Tag:           query.Get("tag"),
+			OutputStream:  out,
+			RawJSONStream: true,
+		}, auth); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.Copy(w, out)
+
+	case strings.HasSuffix(name, "/json"):
+		image, err := s.InspectImage(strings.TrimSuffix(name, "/json"))
+		writeResult(w, image, err)
+
+	case strings.HasSuffix(name, "/tag") && r.Method == "POST":
+		if err := s.TagImage(strings.TrimSuffix(name, "/tag"), docker.TagImageOptions{
+			Repo:  query.Get("repo"),
+			Tag:   query.Get("tag"),
+			Force: query.Get("force") == "1",
+		}); err != nil {
+			writeError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+
+	case r.Method == "DELETE":
+		if err := s.RemoveImageExtended(name, docker.RemoveImageOptions{Force: query.Get("force") == "1"}); err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, []map[string]string{{"Untagged": name}})
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *DockerServer) buildImage(w http.ResponseWriter, r *http.Request) {
+	out := new(bytes.Buffer)
+	if err := s.BuildImage(docker.BuildImageOptions{
+		Name:           r.URL.Query().Get("t"),
+		SuppressOutput: r.URL.Query().Get("q") == "1",
+		InputStream:    r.Body,
+		OutputStream:   out,
+		RawJSONStream:  true,
+	}); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(out.Bytes())
+}
+
+func (s *DockerServer) networks(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 0:
+		list, err := s.ListNetworks()
+		if list == nil {
+			list = []docker.Network{}
+		}
+		writeResult(w, list, err)
+
+	case len(parts) == 1 && parts[0] == "create":
+		var opts docker.CreateNetworkOptions
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			writeError(w, apiError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		network, err := s.CreateNetwork(opts)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, map[string]string{"Id": network.ID})
+
+	case len(parts) == 1 && r.Method == "DELETE":
+		writeResult(w, nil, s.RemoveNetwork(parts[0]))
+
+	case len(parts) == 1:
+		network, err := s.NetworkInfo(parts[0])
+		writeResult(w, network, err)
+
+	case len(parts) == 2 && (parts[1] == "connect" || parts[1] == "disconnect"):
+		var opts docker.NetworkConnectionOptions
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			writeError(w, apiError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		if parts[1] == "connect" {
+			writeResult(w, nil, s.ConnectNetwork(parts[0], opts))
+		} else {
+			writeResult(w, nil, s.DisconnectNetwork(parts[0], opts))
+		}
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *DockerServer) volumes(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case len(parts) == 0:
+		var opts docker.ListVolumesOptions
+		if filters := r.URL.Query().Get("filters"); filters != "" {
+			json.Unmarshal([]byte(filters), &opts.Filters)
+		}
+		list, err := s.ListVolumes(opts)
+		if list == nil {
+			list = []docker.Volume{}
+		}
+		writeResult(w, map[string]interface{}{"Volumes": list}, err)
+
+	case len(parts) == 1 && parts[0] == "create":
+		var opts docker.CreateVolumeOptions
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			writeError(w, apiError(http.StatusBadRequest, err.Error()))
+			return
+		}
+		volume, err := s.CreateVolume(opts)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, volume)
+
+	case len(parts) == 1 && r.Method == "DELETE":
+		writeResult(w, nil, s.RemoveVolume(parts[0]))
+
+	case len(parts) == 1:
+		volume, err := s.InspectVolume(parts[0])
+		writeResult(w, volume, err)
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// stats streams the container stats until the backend stops sending
+// them or the client goes away.
+func (s *DockerServer) stats(w http.ResponseWriter, r *http.Request, id string) {
+	stats := make(chan *docker.Stats)
+	done := make(chan bool)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- s.Stats(docker.StatsOptions{
+			ID:     id,
+			Stats:  stats,
+			Stream: r.URL.Query().Get("stream") != "false",
+			Done:   done,
+		})
+	}()
+
+	first, ok := <-stats
+	if !ok {
+		writeError(w, <-errs)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	for stat := first; ; {
+		encoder.Encode(stat)
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+
+		select {
+		case stat, ok = <-stats:
+			if !ok {
+				return
+			}
+		case <-r.Context().Done():
+			close(done)
+			for range stats {
+			}
+			return
+		}
+	}
+}
+
+// events streams the backend events matching the since, until and
+// filters parameters until the backend stops sending them or the client
+// goes away.
+func (s *DockerServer) events(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := docker.EventsOptions{
+		Since: query.Get("since"),
+		Until: query.Get("until"),
+	}
+	if filters := query.Get("filters"); filters != "" {
+		if err := json.Unmarshal([]byte(filters), &opts.Filters); err != nil {
+			writeError(w, apiError(http.StatusBadRequest, err.Error()))
+			return
+		}
+	}
+
+	// Check the parameters before committing to a response.
+	if _, err := eventTime(opts.Since); err != nil {
+		writeError(w, err)
+		return
+	}
+	if _, err := eventTime(opts.Until); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	events := make(chan *docker.APIEvents)
+	done := make(chan bool)
+	opts.Events, opts.Done = events, done
+	go s.Events(opts)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	encoder := json.NewEncoder(w)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			encoder.Encode(event)
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
+			}
+		case <-r.Context().Done():
+			close(done)
+			for range events {
+			}
+			return
+		}
+	}
+}
+
+// outputStream writes container output the way Docker does: as is for
+// containers with a terminal, multiplexed into stdout and stderr frames
+// otherwise. The output is held back until start is called.
+type outputStream struct {
+	mu      sync.Mutex
+	w       io.Writer
+	raw     bool
+	started bool
+	pending bytes.Buffer
+}
+
+func newOutputStream(w io.Writer, raw bool) *outputStream {
+	return &outputStream{w: w, raw: raw}
+}
+
+func (o *outputStream) stdout() io.Writer { return &outputFrames{o, 1} }
+func (o *outputStream) stderr() io.Writer { return &outputFrames{o, 2} }
+
+func (o *outputStream) start() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.started = true
+	o.write(o.pending.Bytes())
+}
+
+// write expects o.mu to be held.
+func (o *outputStream) write(p []byte) {
+	o.w.Write(p)
+	if flusher, ok := o.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+type outputFrames struct {
+	o      *outputStream
+	stream byte
+}
+
+func (f *outputFrames) Write(p []byte) (int, error) {
+	f.o.mu.Lock()
+	defer f.o.mu.Unlock()
+
+	frame := p
+	if !f.o.raw {
+		header := []byte{f.stream, 0, 0, 0, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(header[4:], uint32(len(p)))
+		frame = append(header, p...)
+	}
+	if !f.o.started {
+		f.o.pending.Write(frame)
+	} else {
+		f.o.write(frame)
+	}
+	return len(p), nil
+}
+
+// hijack takes over the client connection and sends the response
+// headers Docker sends before streaming a container or exec output.
+func hijack(w http.ResponseWriter, raw bool) (net.Conn, *outputStream, error) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("connection cannot be hijacked")
+	}
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Ignore the standard input.
+	go io.Copy(ioutil.Discard, buf)
+
+	fmt.Fprint(conn, "HTTP/1.1 200 OK\r\nContent-Type: application/vnd.docker.raw-stream\r\n\r\n")
+	return conn, newOutputStream(conn, raw), nil
+}
+
+func writeResult(w http.ResponseWriter, result interface{}, err error) {
+	switch {
+	case err != nil:
+		writeError(w, err)
+	case result == nil:
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, result interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+// writeError sends the error with the status code go-dockerclient
+// translates back into the same error.
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch e := err.(type) {
+	case *docker.Error:
+		status = e.Status
+	case *docker.NoSuchContainer, *docker.NoSuchExec, *docker.NoSuchNetwork, *docker.NoSuchNetworkOrContainer:
+		status = http.StatusNotFound
+	case *docker.ContainerAlreadyRunning, *docker.ContainerNotRunning:
+		status = http.StatusNotModified
+	}
+	switch err {
+	case docker.ErrNoSuchImage, docker.ErrNoSuchVolume:
+		status = http.StatusNotFound
+	case docker.ErrContainerAlreadyExists, docker.ErrNetworkAlreadyExists, docker.ErrVolumeInUse:
+		status = http.StatusConflict
+	}
+
+	w.WriteHeader(status)
+	if status != http.StatusNotModified {
+		fmt.Fprint(w, err.Error())
+	}
+}
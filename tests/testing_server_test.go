@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/eris-ltd/eris-cli/util"
+
+	log "github.com/Sirupsen/logrus"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestMain(m *testing.M) {
+	log.SetLevel(log.ErrorLevel)
+
+	os.Exit(m.Run())
+}
+
+func TestDockerServerRun(t *testing.T) {
+	server, disconnect := ConnectDockerServer(t)
+	defer disconnect()
+
+	server.AddImage("quay.io/eris/base")
+
+	if _, err := util.DockerClient.CreateContainer(docker.CreateContainerOptions{
+		Name: "echo",
+		Config: &docker.Config{
+			Image:        "quay.io/eris/base",
+			Cmd:          []string{"echo", "hello"},
+			AttachStdout: true,
+		},
+	}); err != nil {
+		t.Fatalf("expected container to be created, got %v", err)
+	}
+
+	out := new(bytes.Buffer)
+	success := make(chan struct{})
+	attached := make(chan error)
+	go func() {
+		attached <- util.DockerClient.AttachToContainer(docker.AttachToContainerOptions{
+			Container:    "echo",
+			OutputStream: out,
+			Stream:       true,
+			Stdout:       true,
+			Success:      success,
+		})
+	}()
+	success <- <-success
+
+	if err := util.DockerClient.StartContainer("echo", nil); err != nil {
+		t.Fatalf("expected container to start, got %v", err)
+	}
+	if code, err := util.DockerClient.WaitContainer("echo"); code != 0 || err != nil {
+		t.Fatalf("expected exit code 0, got %v (error %v)", code, err)
+	}
+	if err := <-attached; err != nil {
+		t.Fatalf("expected attach to succeed, got %v", err)
+	}
+	if out.String() != "hello\n" {
+		t.Fatalf("expected attached output %q, got %q", "hello\n", out.String())
+	}
+
+	if err := util.DockerClient.StartContainer("echo", nil); err != nil {
+		t.Fatalf("expected container to restart, got %v", err)
+	}
+	util.DockerClient.WaitContainer("echo")
+
+	logs := new(bytes.Buffer)
+	if err := util.DockerClient.Logs(docker.LogsOptions{
+		Container:    "echo",
+		OutputStream: logs,
+		Stdout:       true,
+		Tail:         "1",
+	}); err != nil {
+		t.Fatalf("expected logs to succeed, got %v", err)
+	}
+	if logs.String() != "hello\n" {
+		t.Fatalf("expected logs %q, got %q", "hello\n", logs.String())
+	}
+
+	if err := util.DockerClient.StartContainer("missing", nil); !isNoSuchContainer(err) {
+		t.Fatalf("expected no such container error, got %v", err)
+	}
+	if err := util.DockerClient.RemoveContainer(docker.RemoveContainerOptions{ID: "echo"}); err != nil {
+		t.Fatalf("expected container to be removed, got %v", err)
+	}
+}
+
+func TestDockerServerEvents(t *testing.T) {
+	server, disconnect := ConnectDockerServer(t)
+	defer disconnect()
+
+	events := make(chan *docker.APIEvents, 10)
+	if err := util.DockerClient.AddEventListener(events); err != nil {
+		t.Fatalf("expected to listen to events, got %v", err)
+	}
+	defer util.DockerClient.RemoveEventListener(events)
+
+	// The client connects to the event stream in the background, so
+	// keep generating events until one arrives.
+	timeout := time.After(5 * time.Second)
+	for {
+		server.AddImage("quay.io/eris/base")
+
+		select {
+		case event := <-events:
+			if event.Status != "pull" || event.ID != "quay.io/eris/base:latest" {
+				t.Fatalf("expected pull event, got %v", event)
+			}
+			return
+		case <-time.After(100 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("expected an event, got none")
+		}
+	}
+}
+
+func isNoSuchContainer(err error) bool {
+	_, ok := err.(*docker.NoSuchContainer)
+	return ok
+}
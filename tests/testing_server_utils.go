@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eris-ltd/eris-cli/config"
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/util"
+
+	"github.com/eris-ltd/common/go/common"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// ConnectDockerServer starts a fake Docker API server and points
+// util.DockerClient at it. The returned function closes the server and
+// puts the previous util.DockerClient back.
+func ConnectDockerServer(t *testing.T) (*DockerServer, func()) {
+	server := NewDockerServer()
+	client := util.DockerClient
+
+	if config.GlobalConfig == nil {
+		config.GlobalConfig, _ = config.SetGlobalObject(ioutil.Discard, ioutil.Discard)
+	}
+	for _, env := range []string{"DOCKER_HOST", "DOCKER_CERT_PATH", "DOCKER_TLS_VERIFY"} {
+		value := os.Getenv(env)
+		os.Unsetenv(env)
+		defer os.Setenv(env, value)
+	}
+	os.Setenv("DOCKER_HOST", server.URL())
+	util.DockerConnect(false, "eris")
+
+	done := func() {
+		server.Close()
+		util.DockerClient = client
+	}
+	if _, err := util.DockerClient.Version(); err != nil {
+		done()
+		t.Fatalf("expected to connect to the server, got %v", err)
+	}
+	return server, done
+}
+
+// WithErisRoot points the Eris root, chains, and services directories at
+// a temporary directory and approves image pulls. The returned function
+// puts everything back.
+func WithErisRoot(t *testing.T) (string, func()) {
+	root, err := ioutil.TempDir("", "eris-root")
+	if err != nil {
+		t.Fatalf("expected temp dir, got %v", err)
+	}
+
+	erisRoot, chainsPath, servicesPath := common.ErisRoot, common.ChainsPath, common.ServicesPath
+	common.ErisRoot = root
+	common.ChainsPath = filepath.Join(root, "chains")
+	common.ServicesPath = filepath.Join(root, "services")
+	os.Setenv("ERIS_PULL_APPROVE", "true")
+
+	return root, func() {
+		os.Unsetenv("ERIS_PULL_APPROVE")
+		common.ErisRoot, common.ChainsPath, common.ServicesPath = erisRoot, chainsPath, servicesPath
+		os.RemoveAll(root)
+	}
+}
+
+// WriteFiles writes files, keyed by their paths relative to root.
+func WriteFiles(t *testing.T, root string, files map[string]string) {
+	for file, content := range files {
+		file = filepath.Join(root, file)
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("expected directory for %s, got %v", file, err)
+		}
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatalf("expected %s to be written, got %v", file, err)
+		}
+	}
+}
+
+// ServeChainRPC runs the chain container of name with the tendermint RPC
+// port published at a server which replies to the RPC methods with their
+// results.
+func ServeChainRPC(t *testing.T, server *DockerServer, name string, results map[string]string) *httptest.Server {
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := strings.TrimPrefix(r.URL.Path, "/")
+		if result, ok := results[method]; ok {
+			fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": "", "result": [1, %s], "error": ""}`, result)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc": "2.0", "id": "", "result": null, "error": "Unknown method %s"}`, method)
+	}))
+	_, port, _ := net.SplitHostPort(rpc.Listener.Addr().String())
+
+	server.AddImage("quay.io/eris/db")
+	if _, err := util.DockerClient.CreateContainer(docker.CreateContainerOptions{
+		Name: util.ChainContainerName(name),
+		Config: &docker.Config{
+			Image:        "quay.io/eris/db",
+			Cmd:          []string{"erisdb"},
+			Labels:       util.Labels(name, &def.Operation{ContainerType: def.TypeChain}),
+			ExposedPorts: map[docker.Port]struct{}{"46657/tcp": {}},
+		},
+		HostConfig: &docker.HostConfig{
+			PortBindings: map[docker.Port][]docker.PortBinding{
+				"46657/tcp": {{HostIP: "127.0.0.1", HostPort: port}},
+			},
+		},
+	}); err != nil {
+		rpc.Close()
+		t.Fatalf("expected chain container to be created, got %v", err)
+	}
+	if err := util.DockerClient.StartContainer(util.ChainContainerName(name), nil); err != nil {
+		rpc.Close()
+		t.Fatalf("expected chain container to start, got %v", err)
+	}
+	return rpc
+}
+
+// ReplaceArchiveEntry copies the gzipped tar archive src to dst with the
+// contents of the name entry replaced.
+func ReplaceArchiveEntry(t *testing.T, src, dst, name, content string) {
+	in, err := os.Open(src)
+	if err != nil {
+		t.Fatalf("expected archive, got %v", err)
+	}
+	defer in.Close()
+	gz, err := gzip.NewReader(in)
+	if err != nil {
+		t.Fatalf("expected gzipped archive, got %v", err)
+	}
+
+	out := new(bytes.Buffer)
+	gzOut := gzip.NewWriter(out)
+	tw := tar.NewWriter(gzOut)
+	for tr := tar.NewReader(gz); ; {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("expected archive entry, got %v", err)
+		}
+		var r io.Reader = tr
+		if header.Name == name {
+			header.Size = int64(len(content))
+			r = strings.NewReader(content)
+		}
+		tw.WriteHeader(header)
+		io.Copy(tw, r)
+	}
+	tw.Close()
+	gzOut.Close()
+
+	if err := ioutil.WriteFile(dst, out.Bytes(), 0644); err != nil {
+		t.Fatalf("expected archive to be written, got %v", err)
+	}
+}
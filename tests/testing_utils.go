@@ -40,7 +40,23 @@ func TestsInit(testType string) (err error) {
 	// run correctly.
 	config.ChangeErisDir(ErisDir)
 	common.InitErisDir()
-	util.DockerConnect(false, "eris")
+
+	// ERIS_TEST_BACKEND=fake runs the test suite against an in-memory
+	// container backend instead of a Docker daemon; ERIS_TEST_BACKEND=server
+	// runs it against the same backend behind a fake Docker API server.
+	switch os.Getenv("ERIS_TEST_BACKEND") {
+	case "fake":
+		UseFakeBackend()
+		os.Setenv("ERIS_PULL_APPROVE", "true")
+	case "server":
+		os.Setenv("DOCKER_HOST", NewDockerServer().URL())
+		os.Unsetenv("DOCKER_CERT_PATH")
+		os.Unsetenv("DOCKER_TLS_VERIFY")
+		os.Setenv("ERIS_PULL_APPROVE", "true")
+		util.DockerConnect(false, "eris")
+	default:
+		util.DockerConnect(false, "eris")
+	}
 
 	// this dumps the ipfs and keys services defs into the temp dir which
 	// has been set as the erisRoot.
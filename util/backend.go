@@ -0,0 +1,67 @@
+package util
+
+import (
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// ContainerBackend is the set of container engine calls the eris tool
+// makes. The method set mirrors the go-dockerclient one, so a connected
+// *docker.Client (the default, set by DockerConnect) satisfies it as is.
+// Tests can replace DockerClient with an in-memory implementation.
+type ContainerBackend interface {
+	// Containers.
+	CreateContainer(opts docker.CreateContainerOptions) (*docker.Container, error)
+	StartContainer(id string, hostConfig *docker.HostConfig) error
+	StopContainer(id string, timeout uint) error
+	KillContainer(opts docker.KillContainerOptions) error
+	RemoveContainer(opts docker.RemoveContainerOptions) error
+	InspectContainer(id string) (*docker.Container, error)
+	ListContainers(opts docker.ListContainersOptions) ([]docker.APIContainers, error)
+	WaitContainer(id string) (int, error)
+	AttachToContainer(opts docker.AttachToContainerOptions) error
+	Logs(opts docker.LogsOptions) error
+	Stats(opts docker.StatsOptions) error
+
+	// Exec.
+	CreateExec(opts docker.CreateExecOptions) (*docker.Exec, error)
+	StartExec(id string, opts docker.StartExecOptions) error
+	InspectExec(id string) (*docker.ExecInspect, error)
+	ResizeExecTTY(id string, height, width int) error
+
+	// Files.
+	UploadToContainer(id string, opts docker.UploadToContainerOptions) error
+	DownloadFromContainer(id string, opts docker.DownloadFromContainerOptions) error
+
+	// Images.
+	PullImage(opts docker.PullImageOptions, auth docker.AuthConfiguration) error
+	TagImage(name string, opts docker.TagImageOptions) error
+	BuildImage(opts docker.BuildImageOptions) error
+	ListImages(opts docker.ListImagesOptions) ([]docker.APIImages, error)
+	InspectImage(name string) (*docker.Image, error)
+	RemoveImage(name string) error
+	RemoveImageExtended(name string, opts docker.RemoveImageOptions) error
+
+	// Networks.
+	CreateNetwork(opts docker.CreateNetworkOptions) (*docker.Network, error)
+	NetworkInfo(id string) (*docker.Network, error)
+	ListNetworks() ([]docker.Network, error)
+	RemoveNetwork(id string) error
+	ConnectNetwork(id string, opts docker.NetworkConnectionOptions) error
+	DisconnectNetwork(id string, opts docker.NetworkConnectionOptions) error
+
+	// Volumes.
+	CreateVolume(opts docker.CreateVolumeOptions) (*docker.Volume, error)
+	InspectVolume(name string) (*docker.Volume, error)
+	ListVolumes(opts docker.ListVolumesOptions) ([]docker.Volume, error)
+	RemoveVolume(name string) error
+
+	// Events.
+	Events(opts docker.EventsOptions) error
+	AddEventListener(listener chan<- *docker.APIEvents) error
+	RemoveEventListener(listener chan *docker.APIEvents) error
+
+	// Daemon.
+	Version() (*docker.Env, error)
+}
+
+var _ ContainerBackend = (*docker.Client)(nil)
@@ -0,0 +1,288 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+
+	log "github.com/Sirupsen/logrus"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// CacheTTL is how long the container cache is trusted before it is read
+// from Docker again. It doesn't apply while the cache is kept up to date
+// by WatchContainers.
+var CacheTTL = 10 * time.Second
+
+// key identifies an Eris container by its short name and type.
+type key struct {
+	ShortName string
+	Type      string
+}
+
+// cache maps Eris container short names and types to full container
+// names, and keeps the container inspect output. It is safe for
+// concurrent use.
+type cache struct {
+	mu sync.RWMutex
+
+	names    map[key]string          // existing containers
+	reserved map[key]string          // names given to containers yet to be created
+	inspect  map[string]*inspectInfo // by container ID
+
+	loaded   time.Time // last time the names were read from Docker in full
+	watchers int       // running WatchContainers subscriptions
+}
+
+type inspectInfo struct {
+	state  string // container state as listed when inspected
+	loaded time.Time
+	info   *docker.Container
+}
+
+// Cached container names.
+var containerCache = newCache()
+
+func newCache() *cache {
+	return &cache{
+		names:    make(map[key]string),
+		reserved: make(map[key]string),
+		inspect:  make(map[string]*inspectInfo),
+	}
+}
+
+// WatchContainers keeps the container cache up to date with Docker
+// events rather than reading the container list again every CacheTTL,
+// which suits long running processes importing the eris packages.
+// If the event stream breaks, the cache falls back to CacheTTL.
+// WatchContainers returns a function to stop watching.
+func WatchContainers() (stop func()) {
+	now := time.Now()
+	opts := docker.EventsOptions{
+		// Replay the events from now on to cover the time it takes
+		// to connect; the names are then read afresh.
+		Since: fmt.Sprintf("%d.%09d", now.Unix(), now.Nanosecond()),
+
+		// Network events tell when container addresses change.
+		Filters: map[string][]string{"type": {"container", "network"}},
+	}
+	events := make(chan *docker.APIEvents)
+	done := make(chan bool)
+	opts.Events, opts.Done = events, done
+
+	containerCache.mu.Lock()
+	containerCache.watchers++
+	containerCache.loaded = time.Time{}
+	containerCache.mu.Unlock()
+
+	go func() {
+		if err := DockerClient.Events(opts); err != nil {
+			log.WithField("error", err).Info("Stopped watching containers")
+		}
+	}()
+	go func() {
+		for event := range events {
+			containerCache.apply(event)
+		}
+
+		containerCache.mu.Lock()
+		containerCache.watchers--
+		containerCache.mu.Unlock()
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// initialized returns true if the names have been read from Docker.
+func (c *cache) initialized() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return !c.loaded.IsZero()
+}
+
+// fresh returns true if entries loaded at the given time can be trusted.
+// It expects c.mu to be held.
+func (c *cache) fresh(loaded time.Time) bool {
+	return !loaded.IsZero() && (c.watchers > 0 || time.Since(loaded) < CacheTTL)
+}
+
+// lookup returns the full name of the k container, reading the names
+// from Docker first if they're stale.
+func (c *cache) lookup(k key) (string, bool) {
+	c.mu.RLock()
+	fresh := c.fresh(c.loaded)
+	c.mu.RUnlock()
+
+	if !fresh {
+		c.refresh()
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if name, ok := c.names[k]; ok {
+		return name, true
+	}
+	name, ok := c.reserved[k]
+	return name, ok
+}
+
+// refresh reads the names of all Eris containers from Docker.
+func (c *cache) refresh() {
+	containers, err := DockerClient.ListContainers(docker.ListContainersOptions{
+		All:     true,
+		Filters: map[string][]string{"label": {def.LabelEris}},
+	})
+	if err != nil {
+		return
+	}
+	c.update(containers, true)
+}
+
+// update caches the names of the listed containers. If complete is true,
+// the list has every Eris container, so the names missing from it are
+// dropped.
+func (c *cache) update(containers []docker.APIContainers, complete bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if complete {
+		c.names = make(map[key]string)
+	}
+
+	listed := make(map[string]bool)
+	for _, container := range containers {
+		k := key{
+			ShortName: container.Labels[def.LabelShortName],
+			Type:      container.Labels[def.LabelType],
+		}
+		c.names[k] = strings.TrimLeft(container.Names[0], "/")
+		delete(c.reserved, k)
+		listed[container.ID] = true
+	}
+
+	if complete {
+		for id := range c.inspect {
+			if !listed[id] {
+				delete(c.inspect, id)
+			}
+		}
+		c.loaded = time.Now()
+	}
+}
+
+// reserve caches the name for a container yet to be created, unless
+// a name is already known for k, which is returned instead.
+func (c *cache) reserve(k key, name string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if existing, ok := c.names[k]; ok {
+		return existing
+	}
+	if existing, ok := c.reserved[k]; ok {
+		return existing
+	}
+	c.reserved[k] = name
+	return name
+}
+
+// details returns the details of a listed container, using the cached
+// inspect output unless the container state has changed since.
+func (c *cache) details(container docker.APIContainers) *Details {
+	name := strings.TrimLeft(container.Names[0], "/")
+	state := containerState(container.Status)
+
+	c.mu.RLock()
+	cached, ok := c.inspect[container.ID]
+	fresh := ok && cached.state == state && c.fresh(cached.loaded)
+	c.mu.RUnlock()
+
+	if fresh {
+		details := labelDetails(name, cached.info.Config.Labels)
+		details.Info = cached.info
+		return details
+	}
+
+	details := ContainerDetails(name)
+	if details.Info == nil {
+		return details
+	}
+
+	c.mu.Lock()
+	c.inspect[container.ID] = &inspectInfo{
+		state:  state,
+		loaded: time.Now(),
+		info:   details.Info,
+	}
+	c.mu.Unlock()
+	return details
+}
+
+// containerState returns the state part of a listed container status,
+// e.g. "running" for "Up 5 minutes" or "exited (0)" for "Exited (0) 2 hours
+// ago". Unlike the status, it doesn't change as time goes by.
+func containerState(status string) string {
+	fields := strings.Fields(strings.ToLower(status))
+	switch {
+	case len(fields) == 0:
+		return ""
+	case fields[0] == "up" && strings.Contains(status, "(Paused)"):
+		return "paused"
+	case fields[0] == "up":
+		return "running"
+	case len(fields) > 1 && strings.HasPrefix(fields[1], "("):
+		// Exited (CODE) and Restarting (CODE).
+		return fields[0] + " " + fields[1]
+	default:
+		return fields[0]
+	}
+}
+
+// apply updates the cache with a Docker event.
+func (c *cache) apply(event *docker.APIEvents) {
+	if event.Type == "network" {
+		if id := event.Actor.Attributes["container"]; id != "" {
+			c.mu.Lock()
+			delete(c.inspect, id)
+			c.mu.Unlock()
+		}
+		return
+	}
+
+	details := EventDetails(event)
+
+	id, action := event.Actor.ID, event.Action
+	if id == "" {
+		id = event.ID
+	}
+	if action == "" {
+		action = event.Status
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.inspect, id)
+	if details == nil {
+		return
+	}
+
+	k := key{ShortName: details.ShortName, Type: details.Type}
+	switch action {
+	case "destroy":
+		if c.names[k] == details.FullName {
+			delete(c.names, k)
+		}
+	default:
+		c.names[k] = details.FullName
+		delete(c.reserved, k)
+	}
+}
@@ -0,0 +1,98 @@
+package util_test
+
+import (
+	"testing"
+	"time"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+	"github.com/eris-ltd/eris-cli/tests"
+	"github.com/eris-ltd/eris-cli/util"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestContainerCache(t *testing.T) {
+	server, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	server.AddImage("quay.io/eris/keys")
+
+	create := func(name string) string {
+		ops := def.BlankOperation()
+		ops.ContainerType = def.TypeService
+		if _, err := util.DockerClient.CreateContainer(docker.CreateContainerOptions{
+			Name: util.ServiceContainerName(name),
+			Config: &docker.Config{
+				Image:  "quay.io/eris/keys",
+				Labels: util.Labels(name, ops),
+			},
+		}); err != nil {
+			t.Fatalf("expected container to be created, got %v", err)
+		}
+		return util.ServiceContainerName(name)
+	}
+
+	// Concurrent callers are given the same name.
+	names := make(chan string)
+	for i := 0; i < 10; i++ {
+		go func() {
+			names <- util.ServiceContainerName("parallel")
+		}()
+	}
+	name := <-names
+	for i := 1; i < 10; i++ {
+		if other := <-names; other != name {
+			t.Fatalf("expected the same name, got %v and %v", name, other)
+		}
+	}
+
+	// Containers removed behind the tool's back are dropped
+	// once the cache expires.
+	ttl := util.CacheTTL
+	defer func() { util.CacheTTL = ttl }()
+	util.CacheTTL = 0
+
+	removed := create("removed")
+	if lookup, err := util.Lookup(def.TypeService, "removed"); err != nil || lookup != removed {
+		t.Fatalf("expected %v to be found, got %v (error %v)", removed, lookup, err)
+	}
+	util.DockerClient.RemoveContainer(docker.RemoveContainerOptions{ID: removed})
+	if lookup, err := util.Lookup(def.TypeService, "removed"); err != util.ErrNameNotFound {
+		t.Fatalf("expected removed container not to be found, got %v (error %v)", lookup, err)
+	}
+
+	// With events, the cache doesn't expire but follows the changes.
+	util.CacheTTL = time.Hour
+	stop := util.WatchContainers()
+	defer stop()
+
+	watched := create("watched")
+	if lookup, err := util.Lookup(def.TypeService, "watched"); err != nil || lookup != watched {
+		t.Fatalf("expected %v to be found, got %v (error %v)", watched, lookup, err)
+	}
+	util.DockerClient.RemoveContainer(docker.RemoveContainerOptions{ID: watched})
+
+	timeout := time.After(5 * time.Second)
+	for {
+		if _, err := util.Lookup(def.TypeService, "watched"); err == util.ErrNameNotFound {
+			break
+		}
+		select {
+		case <-time.After(10 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("expected removed container to be dropped from the cache")
+		}
+	}
+
+	// Inspect output is reused while the container doesn't change.
+	create("listed")
+	first := util.ErisContainersByType(def.TypeService, false)
+	second := util.ErisContainersByType(def.TypeService, false)
+	if len(first) != 1 || len(second) != 1 || first[0].Info != second[0].Info {
+		t.Fatalf("expected cached inspect output, got %v and %v", first, second)
+	}
+	util.DockerClient.StartContainer(first[0].FullName, nil)
+	if third := util.ErisContainersByType(def.TypeService, false); len(third) != 1 || !third[0].Info.State.Running {
+		t.Fatalf("expected inspect output to follow the container state, got %v", third)
+	}
+}
@@ -1,9 +1,16 @@
 package util
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/eris-ltd/eris-cli/genesis"
 
 	log "github.com/Sirupsen/logrus"
 	"github.com/eris-ltd/common/go/common"
@@ -25,6 +32,27 @@ func IsKnownChain(name string) bool {
 	return false
 }
 
+// ChainNodeName returns the short name of the i-th node of a chain made
+// of several validator nodes.
+func ChainNodeName(chain string, i int) string {
+	return fmt.Sprintf("%s-%d", chain, i)
+}
+
+// SplitChainNodeName returns the chain name and the node index of a name
+// which looks like that of a chain node (see ChainNodeName). Whether such
+// a node exists is up to the chain definition.
+func SplitChainNodeName(name string) (chain string, i int, ok bool) {
+	n := strings.LastIndex(name, "-")
+	if n <= 0 {
+		return "", 0, false
+	}
+	i, err := strconv.Atoi(name[n+1:])
+	if err != nil || i < 0 || name[n+1:] != strconv.Itoa(i) {
+		return "", 0, false
+	}
+	return name[:n], i, true
+}
+
 // Change the head to null (no head)
 func NullHead() error {
 	return ChangeHead("")
@@ -84,3 +112,208 @@ func ChangeHead(name string) error {
 	log.Debug("Head file saved")
 	return nil
 }
+
+// ChainCatchingUpAge is the age of the latest block past which a chain
+// with peers is taken to be catching up with them (see ChainStatus).
+var ChainCatchingUpAge = time.Minute
+
+// ChainStatus is the state of a chain as reported by its tendermint RPC.
+type ChainStatus struct {
+	Name       string     `json:"name"`
+	Running    bool       `json:"running"`
+	ChainID    string     `json:"chain_id,omitempty"`
+	Height     int        `json:"height"`
+	BlockHash  string     `json:"block_hash,omitempty"`
+	BlockTime  *time.Time `json:"block_time,omitempty"`
+	Validators int        `json:"validators"`
+	Peers      int        `json:"peers"`
+	CatchingUp bool       `json:"catching_up"`
+	Version    string     `json:"version,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// GetChainStatus queries the tendermint RPC (port 46657) of the chain
+// specified by its short name for its latest block, validators, and peers.
+// It doesn't fail: a chain which is not running or whose RPC cannot be
+// reached has Running or Error set. The RPC doesn't tell whether the node
+// is catching up, so a node with peers and a latest block older than
+// ChainCatchingUpAge is reported to be.
+func GetChainStatus(name string) *ChainStatus {
+	status := &ChainStatus{Name: name}
+	if !IsChain(name, true) {
+		return status
+	}
+	status.Running = true
+
+	var info struct {
+		NodeInfo struct {
+			Network string `json:"network"`
+			Version string `json:"version"`
+		} `json:"node_info"`
+		LatestBlockHash   string `json:"latest_block_hash"`
+		LatestBlockHeight int    `json:"latest_block_height"`
+		LatestBlockTime   int64  `json:"latest_block_time"` // nanoseconds
+	}
+	var validators struct {
+		BondedValidators []json.RawMessage `json:"bonded_validators"`
+	}
+	var network struct {
+		Peers []json.RawMessage `json:"peers"`
+	}
+	for method, v := range map[string]interface{}{
+		"status":     &info,
+		"validators": &validators,
+		"net_info":   &network,
+	} {
+		if err := chainRPC(name, method, v); err != nil {
+			status.Error = err.Error()
+			return status
+		}
+	}
+
+	status.ChainID = info.NodeInfo.Network
+	status.Height = info.LatestBlockHeight
+	status.BlockHash = info.LatestBlockHash
+	if info.LatestBlockTime != 0 {
+		blockTime := time.Unix(0, info.LatestBlockTime)
+		status.BlockTime = &blockTime
+	}
+	status.Validators = len(validators.BondedValidators)
+	status.Peers = len(network.Peers)
+	status.Version = info.NodeInfo.Version
+	status.CatchingUp = status.Peers > 0 && status.BlockTime != nil && time.Since(*status.BlockTime) > ChainCatchingUpAge
+	return status
+}
+
+// GetChainStatuses returns the statuses of the chains specified by their
+// short names, querying them concurrently (see GetChainStatus).
+func GetChainStatuses(names []string) []*ChainStatus {
+	statuses := make([]*ChainStatus, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			statuses[i] = GetChainStatus(name)
+		}(i, name)
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+// ChainValidator is a validator bonded on a running chain.
+type ChainValidator struct {
+	Address          string         `json:"address"`
+	PubKey           genesis.PubKey `json:"pub_key"`
+	VotingPower      int64          `json:"voting_power"`
+	BondHeight       int            `json:"bond_height"`
+	UnbondHeight     int            `json:"unbond_height"`
+	LastCommitHeight int            `json:"last_commit_height"`
+}
+
+// GetChainValidators returns the validators bonded on the running chain
+// specified by its short name, as reported by the validators method of
+// the tendermint RPC (port 46657).
+func GetChainValidators(name string) ([]*ChainValidator, error) {
+	var result struct {
+		BondedValidators []*ChainValidator `json:"bonded_validators"`
+	}
+	if err := chainRPC(name, "validators", &result); err != nil {
+		return nil, err
+	}
+	return result.BondedValidators, nil
+}
+
+// ChainPeer is a peer a running chain node is connected to.
+type ChainPeer struct {
+	Moniker    string `json:"moniker"`
+	ListenAddr string `json:"listen_addr"`
+	RemoteAddr string `json:"remote_addr"`
+	Version    string `json:"version"`
+	IsOutbound bool   `json:"is_outbound"`
+}
+
+// GetChainPeers returns the peers of the running chain node specified by
+// its short name, as reported by the net_info method of the tendermint
+// RPC (port 46657).
+func GetChainPeers(name string) ([]*ChainPeer, error) {
+	var info struct {
+		Peers []*struct {
+			NodeInfo struct {
+				Moniker    string `json:"moniker"`
+				ListenAddr string `json:"listen_addr"`
+				RemoteAddr string `json:"remote_addr"`
+				Version    string `json:"version"`
+			} `json:"node_info"`
+			IsOutbound bool `json:"is_outbound"`
+		} `json:"peers"`
+	}
+	if err := chainRPC(name, "net_info", &info); err != nil {
+		return nil, err
+	}
+
+	peers := []*ChainPeer{}
+	for _, p := range info.Peers {
+		peers = append(peers, &ChainPeer{
+			Moniker:    p.NodeInfo.Moniker,
+			ListenAddr: p.NodeInfo.ListenAddr,
+			RemoteAddr: p.NodeInfo.RemoteAddr,
+			Version:    p.NodeInfo.Version,
+			IsOutbound: p.IsOutbound,
+		})
+	}
+	return peers, nil
+}
+
+// ChainHeight returns the height of the latest block of the running chain
+// specified by its short name, as reported by the status method of the
+// tendermint RPC (port 46657).
+func ChainHeight(name string) (int, error) {
+	var status struct {
+		LatestBlockHeight int `json:"latest_block_height"`
+	}
+	if err := chainRPC(name, "status", &status); err != nil {
+		return 0, err
+	}
+	return status.LatestBlockHeight, nil
+}
+
+// chainRPC decodes the result of a tendermint RPC method of the running
+// chain specified by its short name into v. Results which come as [type,
+// result] pairs are unwrapped.
+func chainRPC(name, method string, v interface{}) error {
+	address, err := PublishedAddress(ChainContainerName(name), "46657")
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get("http://" + address + "/" + method)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("chain %s RPC returned %s", name, resp.Status)
+	}
+
+	var reply struct {
+		Result json.RawMessage `json:"result"`
+		Error  string          `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return err
+	}
+	if reply.Error != "" {
+		return fmt.Errorf("chain %s RPC %s: %s", name, method, reply.Error)
+	}
+
+	result := reply.Result
+	var pair []json.RawMessage
+	if err := json.Unmarshal(result, &pair); err == nil && len(pair) == 2 {
+		result = pair[1]
+	}
+	return json.Unmarshal(result, v)
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"testing"
+)
+
+func TestSplitChainNodeName(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		chain string
+		i     int
+		ok    bool
+	}{
+		{"testnet-0", "testnet", 0, true},
+		{"testnet-12", "testnet", 12, true},
+		{"my-testnet-3", "my-testnet", 3, true},
+		{"testnet", "", 0, false},
+		{"testnet-", "", 0, false},
+		{"-1", "", 0, false},
+		{"testnet-01", "", 0, false},
+		{"testnet-+1", "", 0, false},
+		{"testnet-a", "", 0, false},
+	} {
+		chain, i, ok := SplitChainNodeName(test.name)
+		if chain != test.chain || i != test.i || ok != test.ok {
+			t.Fatalf("%s: expected (%q, %d, %v), got (%q, %d, %v)", test.name, test.chain, test.i, test.ok, chain, i, ok)
+		}
+	}
+
+	if name := ChainNodeName("testnet", 2); name != "testnet-2" {
+		t.Fatalf("expected testnet-2, got %q", name)
+	}
+}
@@ -61,7 +61,7 @@ func cleanHandler(toClean map[string]bool) error {
 	return nil
 }
 
-// stops and removes containers and their volumes
+// stops and removes containers and their volumes, and data volumes
 func RemoveAllErisContainers() error {
 	contns, err := DockerClient.ListContainers(docker.ListContainersOptions{All: true})
 	if err != nil {
@@ -81,6 +81,17 @@ func RemoveAllErisContainers() error {
 
 	}
 
+	// Data kept in data volumes goes along with the data containers.
+	volumes, err := ErisVolumes()
+	if err != nil {
+		return err
+	}
+	for _, volume := range volumes {
+		if err := DockerClient.RemoveVolume(volume.FullName); err != nil {
+			return fmt.Errorf("Error removing volume: %v", DockerError(err))
+		}
+	}
+
 	return nil
 }
 
@@ -13,7 +13,8 @@ import (
 )
 
 // Details stores useful container information like its type, short name,
-// labels, and Docker inspect output.
+// labels, and Docker inspect output. For data kept in a named volume
+// rather than a data container, Volume is set instead of Info.
 type Details struct {
 	Type      string
 	ShortName string
@@ -21,26 +22,10 @@ type Details struct {
 
 	Labels map[string]string
 	Info   *docker.Container
+	Volume *docker.Volume
 }
 
-type cache struct {
-	c           map[key]string
-	initialized bool
-}
-
-type key struct {
-	ShortName string
-	Type      string
-}
-
-var (
-	// Cached container names.
-	containerCache cache = cache{
-		c: make(map[key]string),
-	}
-
-	ErrNameNotFound = errors.New("container name not found")
-)
+var ErrNameNotFound = errors.New("container name not found")
 
 // UniqueName() returns a unique container name, prefixed with the short
 // container name, e.g. `ipfs-6ba7b811-9dad-11d1-80b4-00c04fd430c8`
@@ -55,56 +40,27 @@ func UniqueName(name string) string {
 // ContainerName returns a long container name by a given container type
 // and a short name.
 func ContainerName(t, name string) string {
-	lookup, err := Lookup(t, name)
-	if err != nil {
-		containerName := UniqueName(name)
-
-		// Save the container's name in the cache (so that when the
-		// ContainerName() is called the second time, the name would
-		// be found in the cache).
-		containerCache.c[key{Type: t, ShortName: name}] = containerName
-
-		return containerName
+	if lookup, err := Lookup(t, name); err == nil {
+		return lookup
 	}
-	return lookup
+
+	// Save the container's name in the cache (so that when the
+	// ContainerName() is called the second time, the name would
+	// be found in the cache). Concurrent callers get the same name.
+	return containerCache.reserve(key{Type: t, ShortName: name}, UniqueName(name))
 }
 
 // Lookup tries the container cache if the container name has been
-// generated before for a give type and short name.
+// generated before for a give type and short name. The cache is read
+// from Docker if it is older than CacheTTL.
 func Lookup(t, name string) (string, error) {
-	if !containerCache.initialized {
-		initializeCache()
-	}
-
-	if lookup, ok := containerCache.c[key{Type: t, ShortName: name}]; ok {
+	if lookup, ok := containerCache.lookup(key{Type: t, ShortName: name}); ok {
 		return lookup, nil
 	}
 
 	return "", ErrNameNotFound
 }
 
-func initializeCache() {
-	containers, err := DockerClient.ListContainers(docker.ListContainersOptions{All: true})
-	if err != nil {
-		return
-	}
-
-	for _, c := range containers {
-		// A container belongs to Eris if it has the "ERIS" label.
-		if _, ok := c.Labels[def.LabelEris]; !ok {
-			continue
-		}
-
-		// Cache names.
-		containerCache.c[key{
-			ShortName: c.Labels[def.LabelShortName],
-			Type:      c.Labels[def.LabelType],
-		}] = strings.TrimLeft(c.Names[0], "/")
-	}
-
-	containerCache.initialized = true
-}
-
 // ContainerDetails uses Docker inspect API call to retrieve useful
 // information about the container. The Docker information is enriched
 // with Eris container short name and type, as well as with Eris labels.
@@ -114,14 +70,19 @@ func ContainerDetails(name string) *Details {
 		return &Details{}
 	}
 
-	labels := info.Config.Labels
+	details := labelDetails(name, info.Config.Labels)
+	details.Info = info
+	return details
+}
 
+// labelDetails returns the container details which can be told from
+// the container name and labels alone.
+func labelDetails(name string, labels map[string]string) *Details {
 	return &Details{
 		FullName:  name,
 		Type:      labels[def.LabelType],
 		ShortName: labels[def.LabelShortName],
 		Labels:    labels,
-		Info:      info,
 	}
 }
 
@@ -147,40 +108,30 @@ func ErisContainers(filter func(name string, details *Details) bool, running boo
 
 	var erisContainers []string
 
-	containers, err := DockerClient.ListContainers(docker.ListContainersOptions{All: !running})
+	// A container belongs to Eris if it has the "ERIS" label.
+	containers, err := DockerClient.ListContainers(docker.ListContainersOptions{
+		All:     !running,
+		Filters: map[string][]string{"label": {def.LabelEris}},
+	})
 	if err != nil {
 		return erisContainers
 	}
 
+	// The list of all containers is complete, the running
+	// ones only add to the cache.
+	containerCache.update(containers, !running)
+
 	for _, c := range containers {
 		name := strings.TrimLeft(c.Names[0], "/")
 
-		// A container belongs to Eris if it has the "ERIS" label.
-		if _, ok := c.Labels[def.LabelEris]; !ok {
-			continue
-		}
-
-		details := ContainerDetails(name)
-
-		// Cache names.
-		containerCache.c[key{
-			ShortName: details.Labels[def.LabelShortName],
-			Type:      details.Labels[def.LabelType],
-		}] = name
-
 		// Apply filter.
-		if !filter(name, details) {
+		if !filter(name, containerCache.details(c)) {
 			continue
 		}
 
 		erisContainers = append(erisContainers, name)
 	}
 
-	// Initialized cache means that it contains information
-	// about all containers, not just the running ones.
-	if running == false {
-		containerCache.initialized = true
-	}
 	return erisContainers
 }
 
@@ -194,32 +145,18 @@ func ErisContainersByType(t string, running bool) []*Details {
 
 	var erisContainers []*Details
 
-	containers, err := DockerClient.ListContainers(docker.ListContainersOptions{All: !running})
+	containers, err := DockerClient.ListContainers(docker.ListContainersOptions{
+		All:     !running,
+		Filters: map[string][]string{"label": {def.LabelEris, def.LabelType + "=" + t}},
+	})
 	if err != nil {
 		return erisContainers
 	}
 
-	for _, c := range containers {
-		name := strings.TrimLeft(c.Names[0], "/")
-
-		// A container belongs to Eris if it has the "ERIS" label.
-		if _, ok := c.Labels[def.LabelEris]; !ok {
-			continue
-		}
+	containerCache.update(containers, false)
 
-		if c.Labels[def.LabelType] != t {
-			continue
-		}
-
-		details := ContainerDetails(name)
-
-		// Cache names.
-		containerCache.c[key{
-			ShortName: details.Labels[def.LabelShortName],
-			Type:      details.Labels[def.LabelType],
-		}] = name
-
-		erisContainers = append(erisContainers, details)
+	for _, c := range containers {
+		erisContainers = append(erisContainers, containerCache.details(c))
 	}
 
 	return erisContainers
@@ -118,7 +118,7 @@ func TestContainerLookupNotFound(t *testing.T) {
 
 	const name = "a"
 
-	if containerCache.initialized {
+	if containerCache.initialized() {
 		t.Fatalf("expecting the container cache not to be initialized")
 	}
 
@@ -127,7 +127,7 @@ func TestContainerLookupNotFound(t *testing.T) {
 		t.Fatalf("didn't expect to find the container name in the first pass, got %v", pass1)
 	}
 
-	if !containerCache.initialized {
+	if !containerCache.initialized() {
 		t.Fatalf("expecting the container cache to be initialized after the first pass")
 	}
 
@@ -147,7 +147,7 @@ func TestContainerLookupWithContainerName(t *testing.T) {
 		t.Fatalf("didn't expect to find the container name in the first pass, got %v", pass1)
 	}
 
-	if !containerCache.initialized {
+	if !containerCache.initialized() {
 		t.Fatalf("expecting the container cache to be initialized after the first pass")
 	}
 
@@ -165,7 +165,7 @@ func TestContainerLookupWithoutContainerName(t *testing.T) {
 
 	const name = "a"
 
-	if containerCache.initialized {
+	if containerCache.initialized() {
 		t.Fatalf("expecting the container cache not to be initialized")
 	}
 
@@ -176,7 +176,7 @@ func TestContainerLookupWithoutContainerName(t *testing.T) {
 		t.Fatalf("expecting the container name %v to be in the cache, got %v", pass1, err)
 	}
 
-	if !containerCache.initialized {
+	if !containerCache.initialized() {
 		t.Fatalf("expecting the container cache to be initialized after the first pass")
 	}
 }
@@ -197,7 +197,7 @@ func TestErisContainersExisting(t *testing.T) {
 		t.Fatalf("expecting to find 2 existing containers")
 	}
 
-	if !containerCache.initialized {
+	if !containerCache.initialized() {
 		t.Fatalf("expecting the container cache to be initialized")
 	}
 }
@@ -218,7 +218,7 @@ func TestErisContainersRunning(t *testing.T) {
 		t.Fatalf("expecting to find 1 running containers")
 	}
 
-	if !containerCache.initialized {
+	if !containerCache.initialized() {
 		t.Fatalf("expecting the container cache to be initialized")
 	}
 }
@@ -290,12 +290,27 @@ func TestFindContainer(t *testing.T) {
 	}
 }
 
-func invalidateCache() {
-	containerCache = cache{
-		c: make(map[key]string),
+func TestContainerState(t *testing.T) {
+	for status, state := range map[string]string{
+		"Up 5 minutes":                  "running",
+		"Up About an hour":              "running",
+		"Up 2 hours (Paused)":           "paused",
+		"Exited (0) 2 hours ago":        "exited (0)",
+		"Exited (137) 3 seconds ago":    "exited (137)",
+		"Restarting (1) 10 seconds ago": "restarting (1)",
+		"Created":                       "created",
+		"":                              "",
+	} {
+		if got := containerState(status); got != state {
+			t.Fatalf("expected %q to be in the %q state, got %q", status, state, got)
+		}
 	}
 }
 
+func invalidateCache() {
+	containerCache = newCache()
+}
+
 func create(t, name string) error {
 	labels := make(map[string]string)
 	labels[def.LabelEris] = "true"
@@ -21,14 +21,30 @@ import (
 	. "github.com/eris-ltd/common/go/common"
 )
 
-// Docker Client initialization
-var DockerClient *docker.Client
+// DockerClient is the container backend all Docker calls go through.
+// DockerConnect sets it to a go-dockerclient connection.
+var DockerClient ContainerBackend
 
 func DockerConnect(verbose bool, machName string) { // TODO: return an error...?
 	var err error
 	var dockerHost string
 	var dockerCertPath string
 
+	// A plain (no TLS) Docker daemon given by DOCKER_HOST, unless
+	// a Docker Machine is asked for with --machine.
+	if dockerHost = os.Getenv("DOCKER_HOST"); (machName == "eris" || machName == "default") && dockerHost != "" && os.Getenv("DOCKER_CERT_PATH") == "" && os.Getenv("DOCKER_TLS_VERIFY") == "" {
+		log.WithField("=>", dockerHost).Debug("Connecting to Docker")
+		DockerClient, err = docker.NewClient(dockerHost)
+		if err != nil {
+			IfExit(DockerError(err))
+		}
+
+		if strings.HasPrefix(dockerHost, "tcp://") {
+			setIPFSHostViaDockerHost(dockerHost)
+		}
+		return
+	}
+
 	// This means we aren't gonna use docker-machine (kind of).
 	if (machName == "eris" || machName == "default") && (os.Getenv("DOCKER_HOST") == "" && os.Getenv("DOCKER_CERT_PATH") == "") {
 		//if os.Getenv("DOCKER_HOST") == "" && os.Getenv("DOCKER_CERT_PATH") == "" {
@@ -0,0 +1,47 @@
+package util
+
+import (
+	"strings"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// EventDetails returns the details of the Eris container a Docker event
+// is about, or nil if the event is not about an Eris container. The
+// details are taken from the container labels Docker sends along with
+// the event (so they're known for removed containers too); Details.Info
+// is only filled in for daemons which don't send the labels.
+func EventDetails(event *docker.APIEvents) *Details {
+	if event.Type != "" && event.Type != "container" {
+		return nil
+	}
+
+	id := event.Actor.ID
+	if id == "" {
+		id = event.ID
+	}
+	attributes := event.Actor.Attributes
+
+	if _, ok := attributes[def.LabelEris]; !ok {
+		details := ContainerDetails(id)
+		if _, ok := details.Labels[def.LabelEris]; !ok {
+			return nil
+		}
+		return details
+	}
+
+	name := strings.TrimLeft(attributes["name"], "/")
+	if name == "" {
+		name = id
+	}
+
+	labels := make(map[string]string)
+	for key, value := range attributes {
+		if strings.HasPrefix(key, def.Namespace+":") {
+			labels[key] = value
+		}
+	}
+	return labelDetails(name, labels)
+}
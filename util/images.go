@@ -0,0 +1,257 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/eris-ltd/eris-cli/config"
+	ver "github.com/eris-ltd/eris-cli/version"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/docker/docker/pkg/jsonmessage"
+	"github.com/docker/docker/pkg/term"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+const (
+	// DefaultPullRetries is the number of attempts to pull an image from
+	// a registry if eris.toml doesn't say otherwise.
+	DefaultPullRetries = 3
+
+	// dockerHub is how Docker Hub is referred to in eris.toml and messages.
+	dockerHub = "docker.io"
+)
+
+// PullBackoff is the delay before the second attempt to pull an image
+// from a registry; it doubles with every next attempt.
+var PullBackoff = 2 * time.Second
+
+// Registries returns the registries and mirrors images are pulled from,
+// in order. The list is the Registries field of eris.toml, or quay.io
+// followed by Docker Hub if it's not set.
+func Registries() []string {
+	registries := []string{ver.ERIS_REG_DEF, ver.ERIS_REG_BAK}
+	if registriesConfigured() {
+		registries = config.GlobalConfig.Config.Registries
+	}
+
+	var normalized []string
+	for _, registry := range registries {
+		normalized = append(normalized, registryHost(registry))
+	}
+	return normalized
+}
+
+// ParseImage splits the image name into the registry (docker.io for
+// Docker Hub), the repository within the registry, and the tag
+// (latest if omitted).
+func ParseImage(name string) (registry, repository, tag string) {
+	repository, tag = name, "latest"
+	if i := strings.LastIndex(name, ":"); i >= 0 && !strings.Contains(name[i:], "/") {
+		repository, tag = name[:i], name[i+1:]
+	}
+
+	registry = dockerHub
+	if parts := strings.SplitN(repository, "/", 2); len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		registry, repository = registryHost(parts[0]), parts[1]
+	}
+	return registry, repository, tag
+}
+
+// PullImage pulls the name image, displaying the progress on writer.
+// The registry the image name refers to is tried first. Eris images, and
+// images of a registry listed in the eris.toml Registries, are tried on
+// the other Registries next. Transient failures are retried with
+// a backoff, the image not being found or access being denied moves on
+// to the next registry straight away. Credentials for a registry are
+// read from the Docker config.json file. An image pulled from a mirror
+// is tagged with the name.
+//
+// PullImage returns an error listing every registry tried if none of
+// them has the image.
+func PullImage(name string, writer io.Writer) error {
+	registry, repository, tag := ParseImage(name)
+
+	var failures []string
+	for _, from := range pullRegistries(registry, repository) {
+		image := repository
+		if from != dockerHub {
+			image = from + "/" + repository
+		}
+
+		err := pullWithRetries(image, tag, from, writer)
+		if err == nil {
+			if from != registry {
+				log.WithFields(log.Fields{
+					"image": image,
+					"as":    name,
+				}).Debug("Tagging pulled image")
+				if err := DockerClient.TagImage(image+":"+tag, docker.TagImageOptions{
+					Repo:  strings.TrimSuffix(name, ":"+tag),
+					Tag:   tag,
+					Force: true,
+				}); err != nil {
+					return DockerError(err)
+				}
+			}
+			return nil
+		}
+
+		log.WithFields(log.Fields{
+			"image":    image + ":" + tag,
+			"registry": from,
+		}).Infof("Cannot pull image: %v", err)
+		failures = append(failures, fmt.Sprintf("  %s: %v", from, err))
+	}
+
+	return fmt.Errorf("Cannot pull the %s image from any registry. Tried:\n%s", name, strings.Join(failures, "\n"))
+}
+
+// pullRegistries returns the registries to pull the repository of the
+// registry from. Other registries are only trusted to serve the same
+// image for eris images and for the registries the user lists as
+// mirrors of each other.
+func pullRegistries(registry, repository string) []string {
+	registries := []string{registry}
+
+	eris := strings.HasPrefix(repository, "eris/") && (registry == registryHost(ver.ERIS_REG_DEF) || registry == registryHost(ver.ERIS_REG_BAK))
+	mirrors := Registries()
+	if !eris && !(registriesConfigured() && contains(mirrors, registry)) {
+		return registries
+	}
+
+	for _, mirror := range mirrors {
+		if !contains(registries, mirror) {
+			registries = append(registries, mirror)
+		}
+	}
+	return registries
+}
+
+func registriesConfigured() bool {
+	return config.GlobalConfig != nil && config.GlobalConfig.Config != nil && len(config.GlobalConfig.Config.Registries) != 0
+}
+
+func pullWithRetries(image, tag, registry string, writer io.Writer) error {
+	retries := DefaultPullRetries
+	if config.GlobalConfig != nil && config.GlobalConfig.Config != nil && config.GlobalConfig.Config.PullRetries > 0 {
+		retries = config.GlobalConfig.Config.PullRetries
+	}
+
+	auth := registryAuth(registry)
+	backoff := PullBackoff
+
+	var err error
+	for attempt := 1; attempt <= retries; attempt++ {
+		if err = pull(image, tag, auth, writer); err == nil || !transientPullError(err) {
+			return err
+		}
+
+		if attempt < retries {
+			log.WithFields(log.Fields{
+				"image":   image + ":" + tag,
+				"attempt": attempt,
+				"error":   err,
+			}).Warnf("Pull failed. Retrying in %v", backoff)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	return err
+}
+
+func pull(image, tag string, auth docker.AuthConfiguration, writer io.Writer) error {
+	r, w := io.Pipe()
+	opts := docker.PullImageOptions{
+		Repository:    image,
+		Tag:           tag,
+		OutputStream:  w,
+		RawJSONStream: true,
+	}
+
+	if os.Getenv("ERIS_PULL_APPROVE") == "true" {
+		writer = ioutil.Discard
+	}
+
+	ch := make(chan error, 1)
+	go func() {
+		defer w.Close()
+		defer close(ch)
+
+		if err := DockerClient.PullImage(opts, auth); err != nil {
+			ch <- DockerError(err)
+		}
+	}()
+
+	// Errors occurring halfway through the pull come in the stream.
+	displayErr := jsonmessage.DisplayJSONMessagesStream(r, writer, os.Stdout.Fd(), writer == os.Stdout && term.IsTerminal(os.Stdout.Fd()), nil)
+	// The display stops at the first error, the rest is to be read for
+	// the pull to finish.
+	io.Copy(ioutil.Discard, r)
+	if err, ok := <-ch; ok {
+		return err
+	}
+	return displayErr
+}
+
+// transientPullError returns false for the errors retrying won't fix.
+func transientPullError(err error) bool {
+	message := strings.ToLower(err.Error())
+	for _, permanent := range []string{
+		"not found",
+		"no such image",
+		"manifest unknown",
+		"unauthorized",
+		"denied",
+		"authentication required",
+		"invalid reference",
+	} {
+		if strings.Contains(message, permanent) {
+			return false
+		}
+	}
+	return true
+}
+
+// registryAuth returns the credentials for the registry from the Docker
+// config.json file (or the older .dockercfg), if any.
+func registryAuth(registry string) docker.AuthConfiguration {
+	auths, err := docker.NewAuthConfigurationsFromDockerCfg()
+	if err != nil {
+		return docker.AuthConfiguration{}
+	}
+
+	for server, auth := range auths.Configs {
+		if registryHost(server) == registry {
+			log.WithField("registry", registry).Debug("Using credentials from Docker config")
+			return auth
+		}
+	}
+	return docker.AuthConfiguration{}
+}
+
+// registryHost strips the scheme and the path from a registry address
+// and resolves the Docker Hub aliases to docker.io.
+func registryHost(address string) string {
+	host := strings.TrimPrefix(strings.TrimPrefix(address, "https://"), "http://")
+	host = strings.SplitN(host, "/", 2)[0]
+
+	switch host {
+	case "", "docker.io", "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		return dockerHub
+	}
+	return host
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
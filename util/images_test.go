@@ -0,0 +1,47 @@
+package util
+
+import (
+	"testing"
+)
+
+var ParseImageTests = []struct {
+	in                        string
+	registry, repository, tag string
+}{
+	{"eris/keys", "docker.io", "eris/keys", "latest"},
+	{"eris/keys:0.12.0", "docker.io", "eris/keys", "0.12.0"},
+	{"postgres", "docker.io", "postgres", "latest"},
+	{"quay.io/eris/keys", "quay.io", "eris/keys", "latest"},
+	{"quay.io/eris/erisdb:0.12.0", "quay.io", "eris/erisdb", "0.12.0"},
+	{"localhost:5000/eris/keys", "localhost:5000", "eris/keys", "latest"},
+	{"localhost/eris/keys:1", "localhost", "eris/keys", "1"},
+	{"docker.io/eris/keys", "docker.io", "eris/keys", "latest"},
+	{"index.docker.io/eris/keys", "docker.io", "eris/keys", "latest"},
+}
+
+func TestParseImage(t *testing.T) {
+	for _, test := range ParseImageTests {
+		registry, repository, tag := ParseImage(test.in)
+		if registry != test.registry || repository != test.repository || tag != test.tag {
+			t.Fatalf("expected %q %q %q, got %q %q %q, input %q", test.registry, test.repository, test.tag, registry, repository, tag, test.in)
+		}
+	}
+}
+
+var RegistryHostTests = []struct {
+	in, out string
+}{
+	{"", "docker.io"},
+	{"https://index.docker.io/v1/", "docker.io"},
+	{"quay.io", "quay.io"},
+	{"https://quay.io", "quay.io"},
+	{"http://mirror.example.com:5000/v2/", "mirror.example.com:5000"},
+}
+
+func TestRegistryHost(t *testing.T) {
+	for _, test := range RegistryHostTests {
+		if host := registryHost(test.in); host != test.out {
+			t.Fatalf("expected %q, got %q, input %q", test.out, host, test.in)
+		}
+	}
+}
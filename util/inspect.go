@@ -40,6 +40,23 @@ func PrintInspectionReport(cont *docker.Container, field string) error {
 	return nil
 }
 
+// PrintVolumeInspectionReport prints the named volume details: all of them
+// if field is "all", or else the value of the field (e.g. "mountpoint").
+func PrintVolumeInspectionReport(volume *docker.Volume, field string) error {
+	if field != "all" {
+		return printField(volume, field)
+	}
+
+	t, err := reflections.Fields(volume)
+	if err != nil {
+		return fmt.Errorf("The marmots had an error trying to print a nice report\n%s", err)
+	}
+	for _, f := range t {
+		printReport(volume, f)
+	}
+	return nil
+}
+
 func PrintLineByContainerID(containerID string, existing bool) ([]string, error) {
 	cont, err := DockerClient.InspectContainer(containerID)
 	if err != nil {
@@ -0,0 +1,81 @@
+package util
+
+import (
+	"strings"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+
+	log "github.com/Sirupsen/logrus"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// ChainAlias is the name a chain container answers to on its own network.
+const ChainAlias = "chain"
+
+// ChainNetworkName returns the name of the user-defined Docker network
+// shared by the chain container and everything connected to the chain.
+func ChainNetworkName(name string) string {
+	return "eris_chain_" + name
+}
+
+// IsUserNetwork returns true if the network mode refers to a user-defined
+// network rather than to one of the Docker built-in modes (bridge, host,
+// none, container:<name>).
+func IsUserNetwork(mode string) bool {
+	switch mode {
+	case "", "default", "bridge", "host", "none":
+		return false
+	}
+	return !strings.HasPrefix(mode, "container:")
+}
+
+// EnsureNetwork creates a bridge user-defined Docker network unless the
+// network already exists.
+func EnsureNetwork(name string) error {
+	if _, err := DockerClient.NetworkInfo(name); err == nil {
+		return nil
+	}
+
+	log.WithField("=>", name).Info("Creating network")
+	_, err := DockerClient.CreateNetwork(docker.CreateNetworkOptions{
+		Name:           name,
+		CheckDuplicate: true,
+		Driver:         "bridge",
+	})
+	if err == docker.ErrNetworkAlreadyExists {
+		return nil
+	}
+	return DockerError(err)
+}
+
+// RemoveNetwork removes the user-defined network if no container uses it.
+func RemoveNetwork(name string) error {
+	network, err := DockerClient.NetworkInfo(name)
+	if err != nil {
+		if _, ok := err.(*docker.NoSuchNetwork); ok {
+			return nil
+		}
+		return DockerError(err)
+	}
+	if len(network.Containers) != 0 {
+		log.WithField("=>", name).Debug("Network is still in use. Not removing")
+		return nil
+	}
+
+	log.WithField("=>", name).Info("Removing network")
+	return DockerError(DockerClient.RemoveNetwork(network.ID))
+}
+
+// JoinNetwork adds the network to the srv networks, unless srv uses a Docker
+// network mode (srv.Net) instead.
+func JoinNetwork(srv *def.Service, network string) {
+	if srv.Net != "" {
+		return
+	}
+	for _, n := range srv.Networks {
+		if n == network {
+			return
+		}
+	}
+	srv.Networks = append(srv.Networks, network)
+}
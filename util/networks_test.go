@@ -0,0 +1,52 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	def "github.com/eris-ltd/eris-cli/definitions"
+)
+
+var IsUserNetworkTests = []struct {
+	in  string
+	out bool
+}{
+	{"", false},
+	{"default", false},
+	{"bridge", false},
+	{"host", false},
+	{"none", false},
+	{"container:keys", false},
+	{"eris_chain_test", true},
+}
+
+func TestIsUserNetwork(t *testing.T) {
+	for _, test := range IsUserNetworkTests {
+		if actual := IsUserNetwork(test.in); actual != test.out {
+			t.Fatalf("expected %v for %q, got %v", test.out, test.in, actual)
+		}
+	}
+}
+
+func TestJoinNetwork(t *testing.T) {
+	srv := def.BlankService()
+
+	JoinNetwork(srv, "one")
+	JoinNetwork(srv, "two")
+	JoinNetwork(srv, "one")
+
+	if !reflect.DeepEqual(srv.Networks, []string{"one", "two"}) {
+		t.Fatalf("expected networks [one two], got %v", srv.Networks)
+	}
+}
+
+func TestJoinNetworkNetMode(t *testing.T) {
+	srv := def.BlankService()
+	srv.Net = "host"
+
+	JoinNetwork(srv, "one")
+
+	if len(srv.Networks) != 0 {
+		t.Fatalf("expected no networks with a network mode set, got %v", srv.Networks)
+	}
+}
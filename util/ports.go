@@ -2,8 +2,13 @@ package util
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"os"
 	"strconv"
 	"strings"
+
+	docker "github.com/fsouza/go-dockerclient"
 )
 
 // PortAndProtol adds the protocol tag '/tcp' to the bare port number
@@ -106,3 +111,42 @@ func MapPorts(ports, assignments []string) map[string]string {
 
 	return m
 }
+
+// PublishedAddress returns the "host:port" address on which the exposed
+// port (“46657” or “46657/tcp”) of the container can be reached from
+// the host. If the port isn't published, the container IP address and
+// the exposed port are returned instead. PublishedAddress returns Docker errors if
+// the container cannot be inspected.
+func PublishedAddress(name, port string) (string, error) {
+	container, err := DockerClient.InspectContainer(name)
+	if err != nil {
+		return "", DockerError(err)
+	}
+
+	port = PortAndProtocol(port)
+
+	if bindings := container.NetworkSettings.Ports[docker.Port(port)]; len(bindings) != 0 {
+		return net.JoinHostPort(publishedHost(bindings[0].HostIP), bindings[0].HostPort), nil
+	}
+
+	if container.NetworkSettings.IPAddress == "" {
+		return "", fmt.Errorf("port %s of container %s is neither published nor reachable", port, name)
+	}
+	return net.JoinHostPort(container.NetworkSettings.IPAddress, strings.Split(port, "/")[0]), nil
+}
+
+// publishedHost translates the host IP of a port binding into an address
+// reachable from this machine: a wildcard binding on a remote Docker host
+// (docker-machine) is reachable on the Docker host's address.
+func publishedHost(ip string) string {
+	if ip != "" && ip != "0.0.0.0" {
+		return ip
+	}
+
+	if u, err := url.Parse(os.Getenv("DOCKER_HOST")); err == nil && u.Scheme == "tcp" {
+		if host, _, err := net.SplitHostPort(u.Host); err == nil {
+			return host
+		}
+	}
+	return "127.0.0.1"
+}
@@ -0,0 +1,98 @@
+package util_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eris-ltd/eris-cli/config"
+	"github.com/eris-ltd/eris-cli/tests"
+	"github.com/eris-ltd/eris-cli/util"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+func TestPullImage(t *testing.T) {
+	server, disconnect := tests.ConnectDockerServer(t)
+	defer disconnect()
+
+	home, err := ioutil.TempDir("", "eris-home")
+	if err != nil {
+		t.Fatalf("expected temp dir, got %v", err)
+	}
+	defer os.RemoveAll(home)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", home)
+
+	os.MkdirAll(filepath.Join(home, ".docker"), 0755)
+	ioutil.WriteFile(filepath.Join(home, ".docker", "config.json"), []byte(`{"auths":{"https://mirror.example.com":{"auth":"bWFybW90OnNlY3JldA=="}}}`), 0644)
+
+	saved := config.GlobalConfig.Config
+	defer func() { config.GlobalConfig.Config = saved }()
+	config.GlobalConfig.Config = &config.ErisConfig{
+		Registries:  []string{"mirror.example.com", "quay.io"},
+		PullRetries: 2,
+	}
+	defer func(backoff time.Duration) { util.PullBackoff = backoff }(util.PullBackoff)
+	util.PullBackoff = time.Millisecond
+
+	// The registry of the image hasn't got it, the mirror fails once,
+	// then serves the image.
+	var tried []string
+	server.Pull = func(opts docker.PullImageOptions, auth docker.AuthConfiguration) error {
+		tried = append(tried, opts.Repository+" "+auth.Username)
+		switch len(tried) {
+		case 1:
+			return fmt.Errorf("image not found")
+		case 2:
+			return fmt.Errorf("connection refused")
+		}
+		return nil
+	}
+	if err := util.PullImage("quay.io/eris/keys:1.0", ioutil.Discard); err != nil {
+		t.Fatalf("expected pull to succeed, got %v", err)
+	}
+	expected := []string{"quay.io/eris/keys ", "mirror.example.com/eris/keys marmot", "mirror.example.com/eris/keys marmot"}
+	if !reflect.DeepEqual(tried, expected) {
+		t.Fatalf("expected pulls %q, got %q", expected, tried)
+	}
+	if _, err := util.DockerClient.InspectImage("quay.io/eris/keys:1.0"); err != nil {
+		t.Fatalf("expected pulled image tagged with the requested name, got %v", err)
+	}
+
+	// Nobody has it: no retries and every registry is reported.
+	tried = nil
+	server.Pull = func(opts docker.PullImageOptions, auth docker.AuthConfiguration) error {
+		tried = append(tried, opts.Repository)
+		return fmt.Errorf("image not found")
+	}
+	err = util.PullImage("eris/missing", ioutil.Discard)
+	if err == nil {
+		t.Fatalf("expected pull to fail")
+	}
+	for _, registry := range []string{"docker.io", "mirror.example.com", "quay.io"} {
+		if !strings.Contains(err.Error(), registry) {
+			t.Fatalf("expected %s in the error, got %v", registry, err)
+		}
+	}
+	if len(tried) != 3 {
+		t.Fatalf("expected one attempt per registry, got %q", tried)
+	}
+
+	// Other images are only pulled from their own registry.
+	for _, registries := range [][]string{nil, {"mirror.example.com", "quay.io"}} {
+		config.GlobalConfig.Config.Registries = registries
+		tried = nil
+		if err := util.PullImage("someorg/app", ioutil.Discard); err == nil || strings.Contains(err.Error(), "quay.io") {
+			t.Fatalf("expected pull from Docker Hub only to fail, got %v", err)
+		}
+		if !reflect.DeepEqual(tried, []string{"someorg/app"}) {
+			t.Fatalf("expected Docker Hub only to be tried with registries %v, got %q", registries, tried)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// Ulimits converts the ulimit entries from the definition file into
+// Docker ulimits. Entries are in the `docker run --ulimit` format:
+//
+//   name=soft
+//   name=soft:hard
+//
+// If the hard limit is omitted, it's the same as the soft one.
+func Ulimits(entries []string) ([]docker.ULimit, error) {
+	var ulimits []docker.ULimit
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("Bad ulimit %q: expected the name=soft[:hard] format", entry)
+		}
+
+		limits := strings.SplitN(parts[1], ":", 2)
+		soft, err := strconv.ParseInt(limits[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Bad ulimit %q: soft limit is not a number", entry)
+		}
+		hard := soft
+		if len(limits) == 2 {
+			if hard, err = strconv.ParseInt(limits[1], 10, 64); err != nil {
+				return nil, fmt.Errorf("Bad ulimit %q: hard limit is not a number", entry)
+			}
+		}
+		if soft > hard {
+			return nil, fmt.Errorf("Bad ulimit %q: soft limit is greater than the hard one", entry)
+		}
+
+		ulimits = append(ulimits, docker.ULimit{Name: parts[0], Soft: soft, Hard: hard})
+	}
+	return ulimits, nil
+}
+
+// Tmpfs converts the tmpfs entries from the definition file into
+// the Docker tmpfs mounts map (mount point to mount options). Entries
+// are in the `docker run --tmpfs` format:
+//
+//   /path
+//   /path:options
+//
+// The mount point has to be an absolute path.
+func Tmpfs(entries []string) (map[string]string, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	mounts := make(map[string]string)
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, ":", 2)
+		if !path.IsAbs(parts[0]) {
+			return nil, fmt.Errorf("Bad tmpfs mount %q: mount point must be an absolute path", entry)
+		}
+
+		mounts[parts[0]] = ""
+		if len(parts) == 2 {
+			mounts[parts[0]] = parts[1]
+		}
+	}
+	return mounts, nil
+}
@@ -0,0 +1,61 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+var UlimitsTests = []struct {
+	in  []string
+	out []docker.ULimit
+	err bool
+}{
+	{nil, nil, false},
+	{[]string{"nofile=1024"}, []docker.ULimit{{Name: "nofile", Soft: 1024, Hard: 1024}}, false},
+	{[]string{"nofile=1024:2048", "nproc=512"}, []docker.ULimit{{Name: "nofile", Soft: 1024, Hard: 2048}, {Name: "nproc", Soft: 512, Hard: 512}}, false},
+
+	{[]string{"nofile"}, nil, true},
+	{[]string{"=1024"}, nil, true},
+	{[]string{"nofile=many"}, nil, true},
+	{[]string{"nofile=1024:many"}, nil, true},
+	{[]string{"nofile=2048:1024"}, nil, true},
+}
+
+func TestUlimits(t *testing.T) {
+	for _, test := range UlimitsTests {
+		ulimits, err := Ulimits(test.in)
+		if test.err != (err != nil) {
+			t.Fatalf("expected error %v, got %v, input %q", test.err, err, test.in)
+		}
+		if !reflect.DeepEqual(ulimits, test.out) {
+			t.Fatalf("expected %v, got %v, input %q", test.out, ulimits, test.in)
+		}
+	}
+}
+
+var TmpfsTests = []struct {
+	in  []string
+	out map[string]string
+	err bool
+}{
+	{nil, nil, false},
+	{[]string{"/run"}, map[string]string{"/run": ""}, false},
+	{[]string{"/run:rw,size=64m", "/tmp:noexec"}, map[string]string{"/run": "rw,size=64m", "/tmp": "noexec"}, false},
+
+	{[]string{"run"}, nil, true},
+	{[]string{":rw"}, nil, true},
+}
+
+func TestTmpfs(t *testing.T) {
+	for _, test := range TmpfsTests {
+		mounts, err := Tmpfs(test.in)
+		if test.err != (err != nil) {
+			t.Fatalf("expected error %v, got %v, input %q", test.err, err, test.in)
+		}
+		if !reflect.DeepEqual(mounts, test.out) {
+			t.Fatalf("expected %v, got %v, input %q", test.out, mounts, test.in)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package util
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/eris-ltd/eris-cli/config"
+	def "github.com/eris-ltd/eris-cli/definitions"
+
+	log "github.com/Sirupsen/logrus"
+	docker "github.com/fsouza/go-dockerclient"
+)
+
+// DataVolumeName returns the name of the Docker volume keeping the data
+// of a given short name when it isn't kept in a data container. Volumes
+// can't be renamed, so unlike container names the name is fixed.
+func DataVolumeName(name string) string {
+	return def.Namespace + "_" + def.TypeData + "_" + name
+}
+
+// IsDataVolume returns true if the data volume specified by its short
+// name exists.
+func IsDataVolume(name string) bool {
+	_, err := DockerClient.InspectVolume(DataVolumeName(name))
+	return err == nil
+}
+
+// ExistingData tells where the existing data of a service or a chain
+// specified by its short name is kept, def.StorageContainer or
+// def.StorageVolume, or returns "" if there's no such data. Data
+// containers are found in the container cache, so only the names without
+// one cost a volume lookup.
+func ExistingData(name string) string {
+	switch {
+	case IsData(name):
+		return def.StorageContainer
+	case IsDataVolume(name):
+		return def.StorageVolume
+	}
+	return ""
+}
+
+// DataStorage tells where the data of a service or a chain specified by
+// its short name is kept, def.StorageContainer or def.StorageVolume.
+// Existing data stays where it is until migrated; new data goes where
+// srv.Storage (srv may be nil) or else the eris.toml Storage default say.
+func DataStorage(name string, srv *def.Service) (string, error) {
+	if storage := ExistingData(name); storage != "" {
+		return storage, nil
+	}
+
+	var storage string
+	if srv != nil {
+		storage = srv.Storage
+	}
+	if storage == "" && config.GlobalConfig != nil && config.GlobalConfig.Config != nil {
+		storage = config.GlobalConfig.Config.Storage
+	}
+
+	switch storage {
+	case "", def.StorageContainer:
+		return def.StorageContainer, nil
+	case def.StorageVolume:
+		return def.StorageVolume, nil
+	}
+	return "", fmt.Errorf("Unknown storage %q. Use %q or %q", storage, def.StorageContainer, def.StorageVolume)
+}
+
+// ErisVolumes returns the details of the data volumes, sorted by short
+// name. The details have Volume set and Info nil.
+func ErisVolumes() ([]*Details, error) {
+	log.Info("Discovering Eris data volumes")
+
+	var list []*Details
+
+	volumes, err := DockerClient.ListVolumes(docker.ListVolumesOptions{
+		Filters: map[string][]string{"label": {def.LabelEris}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Error listing volumes: %v", DockerError(err))
+	}
+
+	for i := range volumes {
+		// Older Docker versions ignore the label filter.
+		if volumes[i].Labels[def.LabelType] != def.TypeData {
+			continue
+		}
+
+		details := labelDetails(volumes[i].Name, volumes[i].Labels)
+		details.Volume = &volumes[i]
+		list = append(list, details)
+	}
+
+	sort.Sort(detailsByName(list))
+	return list, nil
+}
+
+type detailsByName []*Details
+
+func (s detailsByName) Len() int           { return len(s) }
+func (s detailsByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s detailsByName) Less(i, j int) bool { return s[i].ShortName < s[j].ShortName }